@@ -0,0 +1,333 @@
+package tango
+
+import (
+	"container/heap"
+)
+
+type aabbTreeNode struct {
+	AABB        AABB // The bounding box of a leaf is fattened by the margin of the tree
+	Value       AABBer
+	parent      *aabbTreeNode
+	left, right *aabbTreeNode
+	height      int
+}
+
+func (n *aabbTreeNode) isLeaf() bool {
+	return n.left == nil
+}
+
+// refit recalculates the bounding box and height of an inner node from its children
+func (n *aabbTreeNode) refit() {
+	n.AABB = aabbUnion(n.left.AABB, n.right.AABB)
+	n.height = n.left.height + 1
+	if n.right.height > n.left.height {
+		n.height = n.right.height + 1
+	}
+}
+
+func aabbUnion(a, b AABB) AABB {
+	if b.Min.X < a.Min.X {
+		a.Min.X = b.Min.X
+	}
+	if b.Min.Y < a.Min.Y {
+		a.Min.Y = b.Min.Y
+	}
+	if b.Max.X > a.Max.X {
+		a.Max.X = b.Max.X
+	}
+	if b.Max.Y > a.Max.Y {
+		a.Max.Y = b.Max.Y
+	}
+	return a
+}
+
+func aabbPerimeter(a AABB) float32 {
+	return 2 * (aabbWidth(a) + aabbHeight(a))
+}
+
+// aabbContains reports whether inner lies completely within outer
+func aabbContains(outer, inner AABB) bool {
+	return inner.Min.X >= outer.Min.X && inner.Min.Y >= outer.Min.Y &&
+		inner.Max.X <= outer.Max.X && inner.Max.Y <= outer.Max.Y
+}
+
+// AABBTree is a SpatialIndex which stores the items in a balanced binary tree of bounding boxes. It's unbounded and
+// handles objects of any size, which makes it well suited for large worlds.
+//
+// The tree stores the bounding box of every item fattened by Margin, so an item moving less than Margin doesn't change
+// the tree when it's updated. Larger margins make updates cheaper, but queries more expensive.
+type AABBTree struct {
+	Margin float32
+	root   *aabbTreeNode
+	items  map[AABBer]*aabbTreeNode
+}
+
+// NewAABBTree creates a new AABB tree which fattens the bounding boxes of the items by the given margin.
+func NewAABBTree(margin float32) *AABBTree {
+	return &AABBTree{
+		Margin: margin,
+		items:  make(map[AABBer]*aabbTreeNode),
+	}
+}
+
+func (t *AABBTree) fatten(a AABB) AABB {
+	a.Min.SubtractScalar(t.Margin)
+	a.Max.AddScalar(t.Margin)
+	return a
+}
+
+// Insert inserts the given item to the tree. Inserting an item which is already in the tree moves it like Update.
+func (t *AABBTree) Insert(item AABBer) {
+	if _, ok := t.items[item]; ok {
+		t.Update(item)
+		return
+	}
+
+	leaf := &aabbTreeNode{AABB: t.fatten(item.AABB()), Value: item}
+	t.items[item] = leaf
+	t.insertLeaf(leaf)
+}
+
+// Remove removes the given item from the tree
+func (t *AABBTree) Remove(item AABBer) {
+	leaf, ok := t.items[item]
+	if !ok {
+		return
+	}
+	delete(t.items, item)
+	t.removeLeaf(leaf)
+}
+
+// Update moves the given item to the location of its current bounding box. The tree only changes if the item moved out
+// of its fattened bounding box. Items not in the tree are inserted.
+func (t *AABBTree) Update(item AABBer) {
+	leaf, ok := t.items[item]
+	if !ok {
+		t.Insert(item)
+		return
+	}
+
+	pRect := item.AABB()
+	if aabbContains(leaf.AABB, pRect) {
+		return
+	}
+
+	t.removeLeaf(leaf)
+	leaf.AABB = t.fatten(pRect)
+	t.insertLeaf(leaf)
+}
+
+// Clear removes all items from the tree
+func (t *AABBTree) Clear() {
+	t.root = nil
+	t.items = make(map[AABBer]*aabbTreeNode)
+}
+
+// Len returns the number of items in the tree
+func (t *AABBTree) Len() int {
+	return len(t.items)
+}
+
+// insertLeaf adds the leaf next to the sibling which increases the perimeter of the tree the least
+func (t *AABBTree) insertLeaf(leaf *aabbTreeNode) {
+	if t.root == nil {
+		t.root = leaf
+		leaf.parent = nil
+		return
+	}
+
+	sibling := t.root
+	for !sibling.isLeaf() {
+		combined := aabbPerimeter(aabbUnion(sibling.AABB, leaf.AABB))
+		// Cost of creating a new parent for the sibling and the leaf
+		cost := 2 * combined
+		// Minimum cost of pushing the leaf further down the tree
+		inheritance := 2 * (combined - aabbPerimeter(sibling.AABB))
+
+		leftCost := t.descendCost(sibling.left, leaf) + inheritance
+		rightCost := t.descendCost(sibling.right, leaf) + inheritance
+		if cost < leftCost && cost < rightCost {
+			break
+		}
+
+		if leftCost < rightCost {
+			sibling = sibling.left
+		} else {
+			sibling = sibling.right
+		}
+	}
+
+	parent := &aabbTreeNode{parent: sibling.parent, left: sibling, right: leaf}
+	t.replaceChild(sibling.parent, sibling, parent)
+	sibling.parent = parent
+	leaf.parent = parent
+
+	for n := parent; n != nil; n = n.parent {
+		n.refit()
+		n = t.balance(n)
+	}
+}
+
+// descendCost returns the cost of inserting the leaf into the given child
+func (t *AABBTree) descendCost(child, leaf *aabbTreeNode) float32 {
+	perimeter := aabbPerimeter(aabbUnion(child.AABB, leaf.AABB))
+	if child.isLeaf() {
+		return perimeter
+	}
+	return perimeter - aabbPerimeter(child.AABB)
+}
+
+func (t *AABBTree) removeLeaf(leaf *aabbTreeNode) {
+	if leaf == t.root {
+		t.root = nil
+		return
+	}
+
+	parent := leaf.parent
+	sibling := parent.left
+	if sibling == leaf {
+		sibling = parent.right
+	}
+
+	// The sibling takes the place of the parent
+	t.replaceChild(parent.parent, parent, sibling)
+	sibling.parent = parent.parent
+	leaf.parent = nil
+
+	for n := sibling.parent; n != nil; n = n.parent {
+		n.refit()
+		n = t.balance(n)
+	}
+}
+
+func (t *AABBTree) replaceChild(parent, old, replacement *aabbTreeNode) {
+	switch {
+	case parent == nil:
+		t.root = replacement
+	case parent.left == old:
+		parent.left = replacement
+	default:
+		parent.right = replacement
+	}
+}
+
+// balance rotates the subtree if one of the children of n is more than one level higher than the other, and returns
+// the new root of the subtree
+func (t *AABBTree) balance(n *aabbTreeNode) *aabbTreeNode {
+	if n.isLeaf() || n.height < 2 {
+		return n
+	}
+
+	switch diff := n.right.height - n.left.height; {
+	case diff > 1:
+		return t.rotateUp(n, n.right)
+	case diff < -1:
+		return t.rotateUp(n, n.left)
+	}
+	return n
+}
+
+// rotateUp makes the child the parent of n, handing the lower child of child over to n
+func (t *AABBTree) rotateUp(n, child *aabbTreeNode) *aabbTreeNode {
+	high, low := child.left, child.right
+	if high.height < low.height {
+		high, low = low, high
+	}
+
+	t.replaceChild(n.parent, n, child)
+	child.parent = n.parent
+	child.left = n
+	child.right = high
+	n.parent = child
+
+	if n.left == child {
+		n.left = low
+	} else {
+		n.right = low
+	}
+	low.parent = n
+
+	n.refit()
+	child.refit()
+	return child
+}
+
+func (t *AABBTree) visit(find AABB, fn func(item AABBer)) {
+	if t.root == nil {
+		return
+	}
+
+	stack := []*aabbTreeNode{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if !aabbOverlaps(n.AABB, find) {
+			continue
+		}
+		if n.isLeaf() {
+			fn(n.Value)
+			continue
+		}
+		stack = append(stack, n.left, n.right)
+	}
+}
+
+// Retrieve returns all objects that could collide with the given bounding box and passing the given filter function.
+func (t *AABBTree) Retrieve(find AABB, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveAABB(t.visit, find, filter)
+}
+
+// RetrievePoint returns all objects containing the given point and passing the given filter function.
+func (t *AABBTree) RetrievePoint(p Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrievePoint(t.visit, p, filter)
+}
+
+// RetrieveCircle returns all objects overlapping the circle with the given center and radius and passing the given
+// filter function.
+func (t *AABBTree) RetrieveCircle(center Point, radius float32, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveCircle(t.visit, center, radius, filter)
+}
+
+// RetrieveOriented returns all objects overlapping the oriented rectangle with the given corners and passing the given
+// filter function. The corners are in the order returned by common.SpaceComponent.Corners.
+func (t *AABBTree) RetrieveOriented(corners [4]Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveOriented(t.visit, corners, filter)
+}
+
+// RetrieveLine returns all objects intersecting the line segment l and passing the given filter function, ordered by
+// the distance from l.P1 at which the line segment enters them.
+func (t *AABBTree) RetrieveLine(l Line, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveLine(t.visit, l, filter)
+}
+
+// RetrieveNearest returns up to k objects passing the given filter function, ordered by their distance to the point
+// p. Objects containing p have a distance of 0.
+func (t *AABBTree) RetrieveNearest(p Point, k int, filter func(aabb AABBer) bool) []AABBer {
+	if k <= 0 || t.root == nil {
+		return nil
+	}
+
+	var found []AABBer
+	queue := nearestQueue{{node: t.root}}
+	for len(queue) > 0 && len(found) < k {
+		c := heap.Pop(&queue).(nearestCandidate)
+
+		// No node or object left in the queue can be closer than this object
+		if c.item != nil {
+			found = append(found, c.item)
+			continue
+		}
+
+		n := c.node.(*aabbTreeNode)
+		if n.isLeaf() {
+			queue.pushItem(p, n.Value, filter)
+			continue
+		}
+		for _, child := range [2]*aabbTreeNode{n.left, n.right} {
+			heap.Push(&queue, nearestCandidate{distance: aabbDistanceSquared(child.AABB, p), node: child})
+		}
+	}
+
+	return found
+}
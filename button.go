@@ -2,8 +2,9 @@ package tango
 
 // A Button is an input which can be either JustPressed, JustReleased or Down. Common uses would be for, a jump key or an action key.
 type Button struct {
-	Triggers []Key
-	Name     string
+	Triggers      []Key
+	MouseTriggers []MouseButton
+	Name          string
 }
 
 // JustPressed checks whether an input was pressed in the previous frame.
@@ -15,6 +16,13 @@ func (b Button) JustPressed() bool {
 		}
 	}
 
+	for _, trigger := range b.MouseTriggers {
+		v := Input.mouseButtons.Get(trigger).JustPressed()
+		if v {
+			return v
+		}
+	}
+
 	return false
 }
 
@@ -27,6 +35,13 @@ func (b Button) JustReleased() bool {
 		}
 	}
 
+	for _, trigger := range b.MouseTriggers {
+		v := Input.mouseButtons.Get(trigger).JustReleased()
+		if v {
+			return v
+		}
+	}
+
 	return false
 }
 
@@ -39,5 +54,12 @@ func (b Button) Down() bool {
 		}
 	}
 
+	for _, trigger := range b.MouseTriggers {
+		v := Input.mouseButtons.Get(trigger).Down()
+		if v {
+			return v
+		}
+	}
+
 	return false
 }
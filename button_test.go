@@ -298,3 +298,44 @@ func BenchmarkInputMgr_ButtonFilledState(b *testing.B) {
 //		checkBtnConfigSubOptimal(b)
 //	}
 //}
+
+// Test a button triggered by both a key and a mouse button.
+func TestButtonMouse(t *testing.T) {
+	Input = NewInputManager()
+	Input.RegisterButton("fire", KeySpace)
+	Input.RegisterMouseButton("fire", MouseButtonLeft)
+
+	btn := Input.Button("fire")
+	if len(btn.Triggers) != 1 || len(btn.MouseTriggers) != 1 {
+		t.Fatal("Registering mouse triggers should keep the key triggers")
+	}
+
+	Input.update()
+	Input.mouseButtons.Set(MouseButtonLeft, true)
+	if !btn.JustPressed() || btn.Down() || btn.JustReleased() {
+		t.Error("Mouse press should be JustPressed")
+	}
+
+	Input.update()
+	if btn.JustPressed() || !btn.Down() || btn.JustReleased() {
+		t.Error("Held mouse button should be Down")
+	}
+
+	Input.update()
+	Input.mouseButtons.Set(MouseButtonLeft, false)
+	if btn.JustPressed() || btn.Down() || !btn.JustReleased() {
+		t.Error("Mouse release should be JustReleased")
+	}
+
+	Input.update()
+	Input.keys.Set(KeySpace, true)
+	if !btn.JustPressed() {
+		t.Error("Key press should still trigger the button")
+	}
+
+	Input.update()
+	Input.SetMute(true)
+	if btn.Down() {
+		t.Error("Muted button should not be Down")
+	}
+}
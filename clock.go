@@ -18,6 +18,16 @@ func (realTime) Now() int64 {
 	return time.Now().UnixNano()
 }
 
+// stepTimer is the timer used once Step runs the frames, which only moves forward by the time given to Step
+type stepTimer struct {
+	now int64
+}
+
+// Now implements the timer interface
+func (t *stepTimer) Now() int64 {
+	return t.now
+}
+
 var theTimer timer = realTime{}
 
 // The amound of nano seconds in a second.
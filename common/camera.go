@@ -30,6 +30,9 @@ const (
 	// EntityScrollerPriority is the priority for the EntityScrollerSystem.
 	// Priorities determine the order in which the system is updated.
 	EntityScrollerPriority
+	// CameraFollowerPriority is the priority for the CameraFollower.
+	// Priorities determine the order in which the system is updated.
+	CameraFollowerPriority
 )
 
 var (
@@ -51,10 +54,127 @@ type cameraEntity struct {
 	*SpaceComponent
 }
 
-// CameraSystem is a System that manages the state of the virtual camera. Only
-// one CameraSystem can be in a World at a time. If more than one CameraSystem
-// is added to the World, it will panic.
+// cameraAxes are all axes of the camera, in the order long tasks are updated
+var cameraAxes = []CameraAxis{XAxis, YAxis, ZAxis, Angle}
+
+// cameraTask is a CameraMessage with a Duration, which is performed over multiple frames
+type cameraTask struct {
+	msg      CameraMessage
+	from, to float32
+	path     []tango.Point // The start position followed by the points of CameraMessage.Path
+	elapsed  float32
+	duration float32
+	done     bool
+}
+
+func (t *cameraTask) begin(cam *CameraSystem) {
+	t.duration = float32(t.msg.Duration.Seconds())
+
+	if len(t.msg.Path) > 0 {
+		start := tango.Point{X: cam.axisValue(XAxis), Y: cam.axisValue(YAxis)}
+		t.path = append([]tango.Point{start}, t.msg.Path...)
+		if t.msg.Incremental {
+			for i := 1; i < len(t.path); i++ {
+				t.path[i].Add(start)
+			}
+		}
+		return
+	}
+
+	t.from = cam.axisValue(t.msg.Axis)
+	t.to = t.msg.Value
+	if t.msg.Incremental {
+		t.to += t.from
+	}
+}
+
+// speed returns the average speed of a move along a single axis, in units per second
+func (t *cameraTask) speed() float32 {
+	if t.path != nil || t.duration <= 0 {
+		return 0
+	}
+	return math32.Abs(t.to-t.from) / t.duration
+}
+
+// advance moves the camera along with the task, and returns whether the task finished
+func (t *cameraTask) advance(cam *CameraSystem, dt float32) bool {
+	t.elapsed += dt
+
+	progress := float32(1)
+	if t.elapsed < t.duration {
+		ease := t.msg.Ease
+		if ease == nil {
+			ease = EaseLinear
+		}
+		progress = ease(t.elapsed / t.duration)
+	}
+
+	if t.path != nil {
+		p := bezierPoint(t.path, progress)
+		cam.moveToX(p.X)
+		cam.moveToY(p.Y)
+	} else {
+		cam.moveAxisTo(t.msg.Axis, t.from+(t.to-t.from)*progress)
+	}
+
+	return t.elapsed >= t.duration
+}
+
+// bezierPoint returns the point at t on the bezier curve with the given control points, using De Casteljau's algorithm
+func bezierPoint(points []tango.Point, t float32) tango.Point {
+	p := make([]tango.Point, len(points))
+	copy(p, points)
+	for n := len(p) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			p[i].X += (p[i+1].X - p[i].X) * t
+			p[i].Y += (p[i+1].Y - p[i].Y) * t
+		}
+	}
+	return p[0]
+}
+
+// cameraSequence is a CameraSequenceMessage which is being performed
+type cameraSequence struct {
+	msg    CameraSequenceMessage
+	step   int
+	tasks  []*cameraTask
+	waited float32
+}
+
+func (seq *cameraSequence) stepDone() bool {
+	if seq.waited < float32(seq.msg.Steps[seq.step].Wait.Seconds()) {
+		return false
+	}
+	for _, task := range seq.tasks {
+		if !task.done {
+			return false
+		}
+	}
+	return true
+}
+
+// CameraSystem is a System that manages the state of the virtual camera. A World
+// can have multiple cameras, such as for split-screen or a minimap, as long as
+// each one has a different Name. The RenderSystem draws the scene once for every
+// camera, in the order they were added to the World.
 type CameraSystem struct {
+	// Name identifies the camera in messages such as CameraMessage. The camera added by the RenderSystem, which is
+	// the main camera, has an empty Name.
+	Name string
+	// Viewport is the area of the canvas the camera renders to, in fractions of the size of the canvas, with (0, 0)
+	// at the top-left corner. An empty Viewport covers the whole canvas.
+	Viewport tango.AABB
+	// Layers selects the entities the camera renders, by their RenderComponent.Layers. Zero renders all entities.
+	Layers LayerMask
+	// Target is the texture the camera renders to instead of the canvas, such as for a minimap or a mirror. The
+	// Viewport is then the area of the texture it renders to. The texture can be drawn by other entities as their
+	// Drawable, which flips its view so it's drawn upright. It's cleared to transparent before rendering, and HUD
+	// entities are not rendered to it.
+	Target *RenderTexture
+	// Cached only renders to the Target once, and again after calling Refresh, such as for UI panels which rarely
+	// change.
+	Cached bool
+
 	x, y, z       float32
 	tracking      cameraEntity // The entity that is currently being followed
 	trackRotation bool         // Rotate with the entity
@@ -62,16 +182,21 @@ type CameraSystem struct {
 	// angle is the angle of the camera, in degrees (not radians!)
 	angle float32
 
-	longTasks map[CameraAxis]*CameraMessage
+	longTasks map[CameraAxis]*cameraTask
+	sequence  *cameraSequence
+	shake     cameraShake
+	rendered  bool
 }
 
 // New initializes the CameraSystem.
 func (cam *CameraSystem) New(w *ecs.World) {
 	num := 0
 	for _, sys := range w.Systems() {
-		switch sys.(type) {
+		switch other := sys.(type) {
 		case *CameraSystem:
-			num++
+			if other.Name == cam.Name {
+				num++
+			}
 		}
 	}
 	if num > 0 { //initalizer is called before added to w.systems
@@ -85,73 +210,177 @@ func (cam *CameraSystem) New(w *ecs.World) {
 	cam.x = CameraBounds.Max.X / 2
 	cam.y = CameraBounds.Max.Y / 2
 	cam.z = 1
+	if cam.Target != nil {
+		cam.Target.flipped = true
+	}
 
-	cam.longTasks = make(map[CameraAxis]*CameraMessage)
+	cam.longTasks = make(map[CameraAxis]*cameraTask)
 
 	tango.Mailbox.ListenMessage(new(CameraMessage), func(msg tango.Message) {
 		cammsg, ok := msg.(CameraMessage)
-		if !ok {
+		if !ok || cammsg.Camera != cam.Name {
+			return
+		}
+
+		cam.perform(cammsg)
+	})
+
+	tango.Mailbox.ListenMessage(new(CameraSequenceMessage), func(msg tango.Message) {
+		seqmsg, ok := msg.(CameraSequenceMessage)
+		if !ok || seqmsg.Camera != cam.Name {
 			return
 		}
 
-		if cammsg.Duration > time.Duration(0) {
-			currentmsg, ok := cam.longTasks[cammsg.Axis]
-			if ok && cammsg.Continue && !currentmsg.Incremental {
-				cammsg.Duration = time.Duration((currentmsg.initialValue - cam.getAxis(cammsg.Axis)) / currentmsg.speed)
+		if cam.sequence != nil {
+			for _, task := range cam.sequence.tasks {
+				cam.stopTask(task)
 			}
+		}
+
+		cam.sequence = &cameraSequence{msg: seqmsg}
+		if len(seqmsg.Steps) > 0 {
+			cam.startStep()
+		}
+	})
+
+	tango.Mailbox.ListenMessage(new(CameraShakeMessage), func(msg tango.Message) {
+		shakemsg, ok := msg.(CameraShakeMessage)
+		if !ok || shakemsg.Camera != cam.Name {
+			return
+		}
+
+		cam.AddTrauma(shakemsg.Trauma)
+	})
+
+	tango.Mailbox.Dispatch(NewCameraMessage{})
+}
+
+// perform starts the move of the CameraMessage, stopping whatever the axis is doing now. It returns the long task if
+// the move has a Duration, or nil if it's done right away.
+func (cam *CameraSystem) perform(cammsg CameraMessage) *cameraTask {
+	current := cam.longTasks[cammsg.Axis]
 
-			cam.longTasks[cammsg.Axis] = &cammsg
-			return // because it's handled incrementally
+	axes := []CameraAxis{cammsg.Axis}
+	if len(cammsg.Path) > 0 {
+		axes = []CameraAxis{XAxis, YAxis}
+	}
+	for _, axis := range axes {
+		if task, ok := cam.longTasks[axis]; ok {
+			cam.stopTask(task)
+		}
+	}
+
+	if cammsg.Duration > time.Duration(0) {
+		task := &cameraTask{msg: cammsg}
+		task.begin(cam)
+
+		// Keep the speed of the move we're replacing
+		if current != nil && cammsg.Continue && task.path == nil {
+			if speed := current.speed(); speed > 0 {
+				task.duration = math32.Abs(task.to-task.from) / speed
+			}
 		}
 
-		// Stop with whatever we're doing now
-		if _, ok := cam.longTasks[cammsg.Axis]; ok {
-			delete(cam.longTasks, cammsg.Axis)
+		for _, axis := range axes {
+			cam.longTasks[axis] = task
 		}
+		return task // because it's handled incrementally
+	}
 
+	switch {
+	case len(cammsg.Path) > 0:
+		end := cammsg.Path[len(cammsg.Path)-1]
 		if cammsg.Incremental {
-			cam.moveAxis(cammsg.Axis, cammsg.Value)
+			cam.moveX(end.X)
+			cam.moveY(end.Y)
 		} else {
-			cam.moveAxisTo(cammsg.Axis, cammsg.Value)
+			cam.moveToX(end.X)
+			cam.moveToY(end.Y)
 		}
-	})
+	case cammsg.Incremental:
+		cam.moveAxis(cammsg.Axis, cammsg.Value)
+	default:
+		cam.moveAxisTo(cammsg.Axis, cammsg.Value)
+	}
+	return nil
+}
 
-	tango.Mailbox.Dispatch(NewCameraMessage{})
+// stopTask stops the long task on all axes it's moving
+func (cam *CameraSystem) stopTask(task *cameraTask) {
+	for _, axis := range cameraAxes {
+		if cam.longTasks[axis] == task {
+			delete(cam.longTasks, axis)
+		}
+	}
+	task.done = true
 }
 
-// Remove does nothing since the CameraSystem has only one entity, the camera itself.
-// This is here to implement the ecs.System interface.
-func (cam *CameraSystem) Remove(ecs.BasicEntity) {}
+// startStep performs the moves of the current step of the sequence
+func (cam *CameraSystem) startStep() {
+	seq := cam.sequence
+	seq.waited = 0
+	seq.tasks = seq.tasks[:0]
+	for _, move := range seq.msg.Steps[seq.step].Moves {
+		if task := cam.perform(move); task != nil {
+			seq.tasks = append(seq.tasks, task)
+		}
+	}
+}
 
-// Update updates the camera. lLong tasks are attempted to update incrementally in batches.
-func (cam *CameraSystem) Update(dt float32) {
-	for axis, longTask := range cam.longTasks {
-		if !longTask.Incremental {
-			longTask.initialIncremental = false
-			longTask.initialValue = longTask.Value
+// updateSequence moves on to the next steps of the sequence once the current one is done, and returns the messages
+// reporting the completed steps
+func (cam *CameraSystem) updateSequence(dt float32) []tango.Message {
+	seq := cam.sequence
+	if seq == nil {
+		return nil
+	}
 
-			longTask.Incremental = true
-			longTask.Value -= cam.getAxis(axis)
+	var completed []tango.Message
+	seq.waited += dt
+	for seq.step < len(seq.msg.Steps) {
+		if !seq.stepDone() {
+			return completed
 		}
 
-		// Set speed if needed
-		if longTask.speed == 0 {
-			longTask.speed = longTask.Value / float32(longTask.Duration.Seconds())
+		completed = append(completed, CameraStepCompleteMessage{Tag: seq.msg.Tag, Step: seq.step})
+		seq.step++
+		if seq.step < len(seq.msg.Steps) {
+			cam.startStep()
 		}
+	}
 
-		cam.moveAxis(axis, longTask.speed*dt)
+	cam.sequence = nil
+	return append(completed, CameraSequenceCompleteMessage{Tag: seq.msg.Tag})
+}
 
-		longTask.Duration -= time.Duration(dt * float32(time.Second))
-		if longTask.Duration <= time.Duration(0) {
+// Remove does nothing since the CameraSystem has only one entity, the camera itself.
+// This is here to implement the ecs.System interface.
+func (cam *CameraSystem) Remove(ecs.BasicEntity) {}
 
-			// it enforces that the last call will have the exactly position set by `Value` when `incremental = false`.
-			if !longTask.initialIncremental {
-				cam.moveAxisTo(axis, longTask.initialValue)
-			}
+// Update updates the camera. Long tasks are performed a bit further every frame, and a CameraMoveCompleteMessage is
+// dispatched for each one that finished.
+func (cam *CameraSystem) Update(dt float32) {
+	var completed []tango.Message
+	for _, axis := range cameraAxes {
+		task, ok := cam.longTasks[axis]
+		if !ok || (axis == YAxis && cam.longTasks[XAxis] == task) {
+			// Paths are moving both the x and y axes, and were already updated with the x axis
+			continue
+		}
 
-			delete(cam.longTasks, axis)
+		if task.advance(cam, dt) {
+			cam.stopTask(task)
+			completed = append(completed, CameraMoveCompleteMessage{Message: task.msg})
 		}
 	}
+	completed = append(completed, cam.updateSequence(dt)...)
+
+	// Dispatched afterwards, as listeners may start new moves
+	for _, msg := range completed {
+		tango.Mailbox.Dispatch(msg)
+	}
+
+	cam.shake.update(dt)
 
 	if cam.tracking.BasicEntity == nil {
 		return
@@ -172,6 +401,53 @@ func (cam *CameraSystem) Update(dt float32) {
 	}
 }
 
+// renders returns whether the camera renders the entity with the given RenderComponent
+func (cam *CameraSystem) renders(render *RenderComponent) bool {
+	if cam.Layers == 0 {
+		return true
+	}
+
+	layers := render.Layers
+	if layers == 0 {
+		layers = DefaultLayer
+	}
+	return cam.Layers&layers != 0
+}
+
+// Refresh makes a Cached camera render to its Target again during the next frame.
+func (cam *CameraSystem) Refresh() {
+	cam.rendered = false
+}
+
+// fullscreen returns whether the camera renders to the whole canvas
+func (cam *CameraSystem) fullscreen() bool {
+	return cam.Viewport == tango.AABB{} || cam.Viewport == tango.AABB{Max: tango.Point{X: 1, Y: 1}}
+}
+
+// mainCamera returns the camera with an empty name in the World, or else the first camera rendering to the canvas, or
+// else the first camera. It returns nil if the World has no camera.
+func mainCamera(w *ecs.World) *CameraSystem {
+	var found, target *CameraSystem
+	for _, system := range w.Systems() {
+		if cam, ok := system.(*CameraSystem); ok {
+			if cam.Name == "" {
+				return cam
+			}
+			if cam.Target != nil {
+				if target == nil {
+					target = cam
+				}
+			} else if found == nil {
+				found = cam
+			}
+		}
+	}
+	if found == nil {
+		return target
+	}
+	return found
+}
+
 // FollowEntity sets the camera to follow the entity with BasicEntity basic
 // and SpaceComponent space.
 func (cam *CameraSystem) FollowEntity(basic *ecs.BasicEntity, space *SpaceComponent, trackRotation bool) {
@@ -214,6 +490,18 @@ func (cam *CameraSystem) getAxis(axis CameraAxis) float32 {
 	}
 }
 
+// axisValue returns the value of the axis in the units used by CameraMessage, which don't include the GlobalScale
+func (cam *CameraSystem) axisValue(axis CameraAxis) float32 {
+	switch axis {
+	case XAxis:
+		return cam.x / tango.GetGlobalScale().X
+	case YAxis:
+		return cam.y / tango.GetGlobalScale().Y
+	default:
+		return cam.getAxis(axis)
+	}
+}
+
 func (cam *CameraSystem) moveAxis(axis CameraAxis, value float32) {
 	switch axis {
 	case XAxis:
@@ -307,14 +595,24 @@ const (
 // CameraMessage is a message that can be sent to the Camera (and other Systemers),
 // to indicate movement.
 type CameraMessage struct {
+	// Camera is the Name of the camera to move. It's empty for the main camera.
+	Camera      string
 	Axis        CameraAxis
 	Value       float32
 	Incremental bool
-	Duration    time.Duration
-	Continue    bool
-
-	initialValue, speed float32
-	initialIncremental  bool
+	// Duration makes the camera move over time instead of right away. A CameraMoveCompleteMessage is dispatched once
+	// the move finished, unless another move on the same axis stopped it before.
+	Duration time.Duration
+	// Continue keeps the speed of the move it replaces, instead of taking Duration.
+	Continue bool
+	// Ease maps the progress over time to the progress of the move. EaseLinear is used if it's nil.
+	Ease EaseFunc
+	// Path moves the camera along a bezier curve through the x and y axes, instead of along Axis to Value. It holds the
+	// control points after the current position, the last one being the destination. If Incremental, the points are
+	// relative to the current position.
+	Path []tango.Point
+	// Tag identifies the move in the CameraMoveCompleteMessage.
+	Tag string
 }
 
 // Type implements the tango.Message interface.
@@ -322,6 +620,60 @@ func (CameraMessage) Type() string {
 	return "CameraMessage"
 }
 
+// CameraMoveCompleteMessage is dispatched when a CameraMessage with a Duration finished moving the camera.
+type CameraMoveCompleteMessage struct {
+	Message CameraMessage
+}
+
+// Type implements the tango.Message interface.
+func (CameraMoveCompleteMessage) Type() string {
+	return "CameraMoveCompleteMessage"
+}
+
+// CameraStep is a step of a CameraSequenceMessage. Its Moves are performed at the same time, and the step is done
+// once all of them finished and at least Wait has passed. A step without moves just waits.
+type CameraStep struct {
+	Moves []CameraMessage
+	Wait  time.Duration
+}
+
+// CameraSequenceMessage makes the camera perform Steps one after another, such as panning, then zooming, then
+// waiting. A CameraStepCompleteMessage is dispatched after every step, and a CameraSequenceCompleteMessage after the
+// last one. A new CameraSequenceMessage stops the sequence being performed. Moves stopped by another CameraMessage
+// count as finished.
+type CameraSequenceMessage struct {
+	// Camera is the Name of the camera to move. It's empty for the main camera.
+	Camera string
+	Steps  []CameraStep
+	Tag    string
+}
+
+// Type implements the tango.Message interface.
+func (CameraSequenceMessage) Type() string {
+	return "CameraSequenceMessage"
+}
+
+// CameraStepCompleteMessage is dispatched when a step of a CameraSequenceMessage is done.
+type CameraStepCompleteMessage struct {
+	Tag  string
+	Step int
+}
+
+// Type implements the tango.Message interface.
+func (CameraStepCompleteMessage) Type() string {
+	return "CameraStepCompleteMessage"
+}
+
+// CameraSequenceCompleteMessage is dispatched when all steps of a CameraSequenceMessage are done.
+type CameraSequenceCompleteMessage struct {
+	Tag string
+}
+
+// Type implements the tango.Message interface.
+func (CameraSequenceCompleteMessage) Type() string {
+	return "CameraSequenceCompleteMessage"
+}
+
 // NewCameraMessage is a message that is sent out whenever the camera system changes,
 // such as when a new world is created or scenes are switched.
 type NewCameraMessage struct{}
@@ -407,12 +759,7 @@ type KeyboardRotator struct {
 
 // New set default values and camera
 func (c *KeyboardRotator) New(w *ecs.World) {
-	for _, sys := range w.Systems() {
-		switch sys.(type) {
-		case *CameraSystem:
-			c.camera = sys.(*CameraSystem)
-		}
-	}
+	c.camera = mainCamera(w)
 
 	if c.camera == nil {
 		warning("missing camera system in the world")
@@ -502,12 +849,7 @@ type KeyboardZoomer struct {
 
 // New set default values and camera
 func (c *KeyboardZoomer) New(w *ecs.World) {
-	for _, sys := range w.Systems() {
-		switch sys.(type) {
-		case *CameraSystem:
-			c.camera = sys.(*CameraSystem)
-		}
-	}
+	c.camera = mainCamera(w)
 
 	if c.camera == nil {
 		warning("missing camera system in the world")
@@ -708,12 +1050,7 @@ type MouseZoomer struct {
 
 // New set default values and camera
 func (c *MouseZoomer) New(w *ecs.World) {
-	for _, sys := range w.Systems() {
-		switch sys.(type) {
-		case *CameraSystem:
-			c.camera = sys.(*CameraSystem)
-		}
-	}
+	c.camera = mainCamera(w)
 
 	if c.camera == nil {
 		warning("missing camera system in the world")
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+type cameraFollowerEntity struct {
+	*ecs.BasicEntity
+	*SpaceComponent
+}
+
+// CameraFollower is a System which smoothly scrolls the camera to follow one or more entities. Unlike the
+// EntityScroller and CameraSystem.FollowEntity, which snap the camera to the entity every frame, it can ignore small
+// movements within a deadzone, ease the camera towards the entities, look ahead in the direction they're moving, and
+// zoom out to keep several entities in view.
+type CameraFollower struct {
+	// Camera is the Name of the camera to move. It's empty for the main camera.
+	Camera string
+	// Deadzone is the width and height of a rectangle around the center of the camera in which the followed entities
+	// can move without the camera following them.
+	Deadzone tango.Point
+	// Smoothing is roughly the time in seconds the camera takes to catch up with the entities. The camera moves as a
+	// critically damped spring, which doesn't overshoot. If zero, the camera snaps to the entities.
+	Smoothing float32
+	// Lookahead moves the camera ahead of the entities in the direction they're moving, by the distance they move in
+	// Lookahead seconds.
+	Lookahead float32
+	// LockX and LockY stop the camera from following the entities along that axis.
+	LockX, LockY bool
+	// Frame zooms the camera in or out so all followed entities are in view, with Padding world units around them.
+	// The zoom is limited by MinZoom and MaxZoom.
+	Frame   bool
+	Padding float32
+
+	entities []cameraFollowerEntity
+
+	started            bool
+	focus, goal        tango.Point // The center of the entities and the point the camera is heading to
+	position           tango.Point
+	velocity           tango.Point
+	zoom, zoomVelocity float32
+}
+
+// Add starts following the entity.
+func (c *CameraFollower) Add(basic *ecs.BasicEntity, space *SpaceComponent) {
+	c.entities = append(c.entities, cameraFollowerEntity{basic, space})
+}
+
+// Remove stops following the entity.
+func (c *CameraFollower) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range c.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		c.entities = append(c.entities[:delete], c.entities[delete+1:]...)
+	}
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*CameraFollower) Priority() int { return CameraFollowerPriority }
+
+// Update moves the camera towards the followed entities.
+func (c *CameraFollower) Update(dt float32) {
+	if len(c.entities) == 0 {
+		return
+	}
+
+	bounds := c.entities[0].SpaceComponent.AABB()
+	for _, e := range c.entities[1:] {
+		aabb := e.SpaceComponent.AABB()
+		bounds.Min.X = math32.Min(bounds.Min.X, aabb.Min.X)
+		bounds.Min.Y = math32.Min(bounds.Min.Y, aabb.Min.Y)
+		bounds.Max.X = math32.Max(bounds.Max.X, aabb.Max.X)
+		bounds.Max.Y = math32.Max(bounds.Max.Y, aabb.Max.Y)
+	}
+	focus := tango.Point{X: (bounds.Min.X + bounds.Max.X) / 2, Y: (bounds.Min.Y + bounds.Max.Y) / 2}
+
+	if !c.started {
+		c.started = true
+		c.focus, c.goal, c.position = focus, focus, focus
+		c.velocity = tango.Point{}
+		c.zoom, c.zoomVelocity = c.frameZoom(bounds), 0
+	}
+
+	// The deadzone is dragged along by the entities once they reach its edges
+	halfX, halfY := c.Deadzone.X/2, c.Deadzone.Y/2
+	c.goal.X = math32.Clamp(c.goal.X, focus.X-halfX, focus.X+halfX)
+	c.goal.Y = math32.Clamp(c.goal.Y, focus.Y-halfY, focus.Y+halfY)
+
+	target := c.goal
+	if c.Lookahead != 0 && dt > 0 {
+		target.X += (focus.X - c.focus.X) / dt * c.Lookahead
+		target.Y += (focus.Y - c.focus.Y) / dt * c.Lookahead
+	}
+	c.focus = focus
+
+	// Keeping the position within the bounds of the camera, so the spring doesn't wind up beyond them
+	target.X = math32.Clamp(target.X, CameraBounds.Min.X, CameraBounds.Max.X)
+	target.Y = math32.Clamp(target.Y, CameraBounds.Min.Y, CameraBounds.Max.Y)
+
+	if !c.LockX {
+		c.position.X = smoothDamp(c.position.X, target.X, &c.velocity.X, c.Smoothing, dt)
+		tango.Mailbox.Dispatch(CameraMessage{Camera: c.Camera, Axis: XAxis, Value: c.position.X, Incremental: false})
+	}
+	if !c.LockY {
+		c.position.Y = smoothDamp(c.position.Y, target.Y, &c.velocity.Y, c.Smoothing, dt)
+		tango.Mailbox.Dispatch(CameraMessage{Camera: c.Camera, Axis: YAxis, Value: c.position.Y, Incremental: false})
+	}
+	if c.Frame {
+		c.zoom = smoothDamp(c.zoom, c.frameZoom(bounds), &c.zoomVelocity, c.Smoothing, dt)
+		tango.Mailbox.Dispatch(CameraMessage{Camera: c.Camera, Axis: ZAxis, Value: c.zoom, Incremental: false})
+	}
+}
+
+// frameZoom returns the zoom level at which the bounding box and the padding around it fit on the screen
+func (c *CameraFollower) frameZoom(bounds tango.AABB) float32 {
+	viewWidth := tango.GameWidth() / tango.GetGlobalScale().X
+	viewHeight := tango.GameHeight() / tango.GetGlobalScale().Y
+	if viewWidth <= 0 || viewHeight <= 0 {
+		return 1
+	}
+
+	zoom := math32.Max(
+		(bounds.Max.X-bounds.Min.X+2*c.Padding)/viewWidth,
+		(bounds.Max.Y-bounds.Min.Y+2*c.Padding)/viewHeight,
+	)
+	return math32.Clamp(zoom, MinZoom, MaxZoom)
+}
+
+// smoothDamp moves current towards target as a critically damped spring, which reaches the target in roughly
+// smoothTime seconds without overshooting it. The velocity is kept in between calls.
+func smoothDamp(current, target float32, velocity *float32, smoothTime, dt float32) float32 {
+	if smoothTime <= 0 {
+		*velocity = 0
+		return target
+	}
+
+	omega := 2 / smoothTime
+	x := omega * dt
+	// Approximation of e^-x, which is accurate enough for the step sizes of a frame
+	exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+
+	change := current - target
+	temp := (*velocity + omega*change) * dt
+	*velocity = (*velocity - omega*temp) * exp
+	result := target + (change+temp)*exp
+
+	// Prevent overshooting the target due to the approximation
+	if (target-current > 0) == (result > target) {
+		*velocity = 0
+		return target
+	}
+	return result
+}
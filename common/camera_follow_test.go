@@ -0,0 +1,94 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+type cameraFollowerTestScene struct{}
+
+func (*cameraFollowerTestScene) Preload()            {}
+func (*cameraFollowerTestScene) Setup(tango.Updater) {}
+func (*cameraFollowerTestScene) Type() string        { return "cameraFollowerTestScene" }
+
+func newCameraFollowerTarget(follower *CameraFollower, x, y float32) *SpaceComponent {
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{Position: tango.Point{X: x - 5, Y: y - 5}, Width: 10, Height: 10}
+	follower.Add(&basic, space)
+	return space
+}
+
+func TestCameraFollowerDeadzone(t *testing.T) {
+	initialize()
+	follower := &CameraFollower{Deadzone: tango.Point{X: 40, Y: 20}, LockY: true}
+	space := newCameraFollowerTarget(follower, 100, 100)
+
+	follower.Update(1)
+	assert.Equal(t, float32(100), cam.X(), "The camera should start at the entity")
+	assert.Equal(t, float32(150), cam.Y(), "The camera should not follow along a locked axis")
+
+	space.Position.X += 15
+	follower.Update(1)
+	assert.Equal(t, float32(100), cam.X(), "Moving within the deadzone should not move the camera")
+
+	space.Position.X += 15
+	follower.Update(1)
+	assert.Equal(t, float32(110), cam.X(), "The camera should follow once the entity leaves the deadzone")
+
+	space.Position.X -= 20
+	follower.Update(1)
+	assert.Equal(t, float32(110), cam.X(), "Moving back within the deadzone should not move the camera")
+}
+
+func TestCameraFollowerSmoothing(t *testing.T) {
+	initialize()
+	follower := &CameraFollower{Smoothing: 0.5}
+	space := newCameraFollowerTarget(follower, 100, 100)
+	follower.Update(1.0 / 60)
+
+	space.Position.X += 100
+	last := cam.X()
+	for i := 0; i < 60; i++ {
+		follower.Update(1.0 / 60)
+		assert.True(t, cam.X() >= last, "The camera should keep moving towards the entity")
+		assert.True(t, cam.X() <= 200, "The camera should not overshoot the entity")
+		last = cam.X()
+	}
+	assert.True(t, cam.X() > 190, "The camera should have mostly caught up after a second, got %v", cam.X())
+}
+
+func TestCameraFollowerLookahead(t *testing.T) {
+	initialize()
+	follower := &CameraFollower{Lookahead: 2}
+	space := newCameraFollowerTarget(follower, 100, 100)
+	follower.Update(1)
+
+	space.Position.X += 10
+	follower.Update(1)
+	assert.Equal(t, float32(130), cam.X(), "The camera should be ahead of the entity by its speed times the lookahead")
+
+	follower.Update(1)
+	assert.Equal(t, float32(110), cam.X(), "The camera should return to the entity once it stopped")
+}
+
+func TestCameraFollowerFrame(t *testing.T) {
+	tango.Run(tango.RunOptions{HeadlessMode: true, NoRun: true, Width: 200, Height: 100}, &cameraFollowerTestScene{})
+	initialize()
+	follower := &CameraFollower{Frame: true, Padding: 5}
+	newCameraFollowerTarget(follower, 50, 100)
+	right := newCameraFollowerTarget(follower, 250, 100)
+
+	follower.Update(1)
+	assert.Equal(t, float32(150), cam.X(), "The camera should be centered between the entities")
+	assert.InDelta(t, 1.1, cam.Z(), 1e-5, "The camera should zoom out to fit the entities and the padding")
+
+	right.Position.X = 50
+	follower.Update(1)
+	assert.Equal(t, MinZoom, cam.Z(), "The zoom should be limited by MinZoom")
+
+	follower.Remove(*follower.entities[1].BasicEntity)
+	assert.Len(t, follower.entities, 1)
+}
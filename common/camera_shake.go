@@ -0,0 +1,102 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+var (
+	// ShakeMaxOffset is the farthest, in world units, a shake at full trauma moves the camera from its position.
+	ShakeMaxOffset float32 = 16
+	// ShakeMaxAngle is the largest angle, in degrees, a shake at full trauma rotates the camera by.
+	ShakeMaxAngle float32 = 4
+	// ShakeDecay is the amount of trauma the camera recovers from per second.
+	ShakeDecay float32 = 1
+	// ShakeFrequency is how many times per second the direction of the shake changes, roughly.
+	ShakeFrequency float32 = 20
+)
+
+// cameraShake is the shake of the camera, which is added to its position and angle when rendering
+type cameraShake struct {
+	trauma      float32
+	time        float32
+	x, y, angle float32
+}
+
+// Seeds of the noise, so each axis shakes independently
+const (
+	shakeSeedX uint32 = iota + 1
+	shakeSeedY
+	shakeSeedAngle
+)
+
+func (s *cameraShake) update(dt float32) {
+	if s.trauma <= 0 {
+		s.x, s.y, s.angle = 0, 0, 0
+		return
+	}
+
+	s.time += dt * ShakeFrequency
+	s.trauma = math32.Max(s.trauma-ShakeDecay*dt, 0)
+
+	// Squaring the trauma makes small amounts barely noticeable, and large amounts violent
+	amount := s.trauma * s.trauma
+	s.x = ShakeMaxOffset * amount * shakeNoise(shakeSeedX, s.time) * tango.GetGlobalScale().X
+	s.y = ShakeMaxOffset * amount * shakeNoise(shakeSeedY, s.time) * tango.GetGlobalScale().Y
+	s.angle = ShakeMaxAngle * amount * shakeNoise(shakeSeedAngle, s.time)
+}
+
+// shakeNoise returns smooth one-dimensional value noise between -1 and 1 at t
+func shakeNoise(seed uint32, t float32) float32 {
+	i := math32.Floor(t)
+	f := t - i
+	// Smoothstep, so the noise doesn't change direction abruptly at the lattice points
+	f = f * f * (3 - 2*f)
+
+	a := shakeHash(seed, int32(i))
+	b := shakeHash(seed, int32(i)+1)
+	return a + (b-a)*f
+}
+
+// shakeHash returns a pseudo-random value between -1 and 1 for the lattice point n
+func shakeHash(seed uint32, n int32) float32 {
+	h := uint32(n)*0x27d4eb2d ^ seed*0x165667b1
+	h ^= h >> 15
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return float32(h)/float32(^uint32(0))*2 - 1
+}
+
+// CameraShakeMessage shakes the camera by adding Trauma to the trauma of the camera. The trauma ranges from 0 to 1,
+// and determines how violently the camera shakes. It decays by ShakeDecay per second, so stacking shakes makes them
+// longer and stronger.
+//
+// The shake only affects how the scene is rendered, so it doesn't change the position returned by CameraSystem.X and
+// CameraSystem.Y, nor the following of entities or the clamping to CameraBounds.
+type CameraShakeMessage struct {
+	// Camera is the Name of the camera to shake. It's empty for the main camera.
+	Camera string
+	Trauma float32
+}
+
+// Type implements the tango.Message interface.
+func (CameraShakeMessage) Type() string {
+	return "CameraShakeMessage"
+}
+
+// AddTrauma adds to the trauma of the camera, which shakes the camera. The trauma is kept between 0 and 1.
+func (cam *CameraSystem) AddTrauma(trauma float32) {
+	cam.shake.trauma = math32.Clamp(cam.shake.trauma+trauma, 0, 1)
+}
+
+// Trauma returns the trauma of the camera, ranging from 0 when it doesn't shake, to 1 when it shakes the most.
+func (cam *CameraSystem) Trauma() float32 {
+	return cam.shake.trauma
+}
+
+// view returns the position and angle at which the camera renders the scene, including the shake
+func (cam *CameraSystem) view() (x, y, angle float32) {
+	return cam.x + cam.shake.x, cam.y + cam.shake.y, cam.angle + cam.shake.angle
+}
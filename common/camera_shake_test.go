@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShakeNoise(t *testing.T) {
+	last := shakeNoise(shakeSeedX, 0)
+	for i := 1; i <= 1000; i++ {
+		n := shakeNoise(shakeSeedX, float32(i)/100)
+		assert.True(t, n >= -1 && n <= 1, "Noise should be between -1 and 1, got %v", n)
+		assert.InDelta(t, last, n, 0.1, "Noise should be smooth")
+		last = n
+	}
+	assert.NotEqual(t, shakeNoise(shakeSeedX, 1.5), shakeNoise(shakeSeedY, 1.5), "Seeds should give different noise")
+}
+
+func TestCameraShake(t *testing.T) {
+	initialize()
+	cam.moveToX(CameraBounds.Max.X)
+
+	tango.Mailbox.Dispatch(CameraShakeMessage{Trauma: 0.6})
+	tango.Mailbox.Dispatch(CameraShakeMessage{Trauma: 0.6})
+	assert.Equal(t, float32(1), cam.Trauma(), "Trauma should be limited to 1")
+
+	moved := false
+	for i := 0; i < 10; i++ {
+		cam.Update(0.05)
+		x, y, angle := cam.view()
+		assert.Equal(t, CameraBounds.Max.X, cam.X(), "Shaking should not move the camera itself")
+		assert.Equal(t, float32(150), cam.Y(), "Shaking should not move the camera itself")
+		assert.True(t, x-cam.X() <= ShakeMaxOffset && y-cam.Y() <= ShakeMaxOffset && angle <= ShakeMaxAngle)
+		moved = moved || x != cam.X()
+	}
+	assert.True(t, moved, "The camera should be shaking")
+	assert.InDelta(t, 0.5, cam.Trauma(), 1e-5, "Trauma should decay over time")
+
+	cam.moveX(-100)
+	assert.Equal(t, CameraBounds.Max.X-100, cam.X(), "Moving while shaking should not be affected by the shake")
+
+	cam.Update(1)
+	x, y, angle := cam.view()
+	assert.Equal(t, float32(0), cam.Trauma())
+	assert.Equal(t, tango.Point{X: cam.X(), Y: cam.Y()}, tango.Point{X: x, Y: y}, "The shake should stop without trauma")
+	assert.Equal(t, cam.Angle(), angle)
+}
@@ -3,8 +3,10 @@ package common
 import (
 	"bytes"
 	"log"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/inkeliz-technologies/ecs"
 	"github.com/inkeliz-technologies/tango"
@@ -180,3 +182,210 @@ func TestCameraAddOnlyOne(t *testing.T) {
 		t.Error("adding more than one CameraSystem did not write expected output to log")
 	}
 }
+
+func TestCameraMessageEase(t *testing.T) {
+	initialize()
+	var completed []CameraMoveCompleteMessage
+	tango.Mailbox.Listen("CameraMoveCompleteMessage", func(msg tango.Message) {
+		completed = append(completed, msg.(CameraMoveCompleteMessage))
+	})
+
+	tango.Mailbox.Dispatch(CameraMessage{Axis: XAxis, Value: 250, Duration: time.Second, Ease: EaseInQuad, Tag: "pan"})
+	tango.Mailbox.Dispatch(CameraMessage{Axis: ZAxis, Value: 1, Incremental: true, Duration: time.Second})
+
+	cam.Update(0.5)
+	assert.InDelta(t, 175, cam.X(), 1e-4, "The move should follow the easing")
+	assert.InDelta(t, 1.5, cam.Z(), 1e-4, "The move should be linear without easing")
+	assert.Len(t, completed, 0)
+
+	cam.Update(0.5)
+	assert.Equal(t, float32(250), cam.X())
+	assert.Equal(t, float32(2), cam.Z())
+	if assert.Len(t, completed, 2) {
+		assert.Equal(t, "pan", completed[0].Message.Tag)
+		assert.Equal(t, ZAxis, completed[1].Message.Axis)
+	}
+
+	tango.Mailbox.Dispatch(CameraMessage{Axis: XAxis, Value: 100, Duration: time.Second})
+	cam.Update(0.5)
+	tango.Mailbox.Dispatch(CameraMessage{Axis: XAxis, Value: 10, Incremental: true})
+	assert.InDelta(t, 185, cam.X(), 1e-4)
+	cam.Update(1)
+	assert.InDelta(t, 185, cam.X(), 1e-4, "Moving the axis right away should stop the long task")
+	assert.Len(t, completed, 2, "Stopped moves should not report completion")
+}
+
+func TestCameraMessagePath(t *testing.T) {
+	initialize()
+
+	tango.Mailbox.Dispatch(CameraMessage{Path: []tango.Point{{X: 150, Y: 50}, {X: 250, Y: 50}}, Duration: time.Second})
+	cam.Update(0.5)
+	assert.InDelta(t, 175, cam.X(), 1e-4, "The camera should follow the bezier curve")
+	assert.InDelta(t, 75, cam.Y(), 1e-4, "The camera should follow the bezier curve")
+
+	cam.Update(0.5)
+	assert.Equal(t, float32(250), cam.X())
+	assert.Equal(t, float32(50), cam.Y())
+
+	tango.Mailbox.Dispatch(CameraMessage{Path: []tango.Point{{X: -100, Y: 100}}, Incremental: true})
+	assert.Equal(t, float32(150), cam.X(), "Incremental paths should be relative to the camera")
+	assert.Equal(t, float32(150), cam.Y(), "Incremental paths should be relative to the camera")
+}
+
+func TestCameraSequenceMessage(t *testing.T) {
+	initialize()
+	var steps []int
+	done := false
+	tango.Mailbox.Listen("CameraStepCompleteMessage", func(msg tango.Message) {
+		steps = append(steps, msg.(CameraStepCompleteMessage).Step)
+	})
+	tango.Mailbox.Listen("CameraSequenceCompleteMessage", func(msg tango.Message) {
+		done = msg.(CameraSequenceCompleteMessage).Tag == "intro"
+	})
+
+	tango.Mailbox.Dispatch(CameraSequenceMessage{Tag: "intro", Steps: []CameraStep{
+		{Moves: []CameraMessage{
+			{Axis: XAxis, Value: 50, Duration: time.Second},
+			{Axis: YAxis, Value: 250, Duration: time.Second},
+		}},
+		{Wait: time.Second},
+		{Moves: []CameraMessage{{Axis: ZAxis, Value: 2, Duration: time.Second}}},
+	}})
+
+	cam.Update(1)
+	assert.Equal(t, tango.Point{X: 50, Y: 250}, tango.Point{X: cam.X(), Y: cam.Y()})
+	assert.Equal(t, []int{0}, steps)
+
+	cam.Update(0.5)
+	cam.Update(0.25)
+	assert.Equal(t, float32(1), cam.Z(), "The zoom should wait for the second step")
+	cam.Update(0.25)
+	assert.Equal(t, []int{0, 1}, steps)
+
+	cam.Update(0.5)
+	assert.InDelta(t, 1.5, cam.Z(), 1e-4)
+	assert.False(t, done)
+	cam.Update(0.5)
+	assert.Equal(t, float32(2), cam.Z())
+	assert.Equal(t, []int{0, 1, 2}, steps)
+	assert.True(t, done)
+}
+
+func TestCameraMultiple(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	tango.Mailbox = &tango.MessageManager{}
+	CameraBounds = tango.AABB{Min: tango.Point{X: 0, Y: 0}, Max: tango.Point{X: 300, Y: 300}}
+	w := &ecs.World{}
+
+	left := &CameraSystem{Name: "left", Viewport: tango.AABB{Max: tango.Point{X: 0.5, Y: 1}}}
+	right := &CameraSystem{Name: "right", Viewport: tango.AABB{Min: tango.Point{X: 0.5}, Max: tango.Point{X: 1, Y: 1}}}
+	primary := &CameraSystem{}
+	w.AddSystem(left)
+	w.AddSystem(right)
+	w.AddSystem(primary)
+	assert.Equal(t, "", buf.String(), "Cameras with different names should not warn")
+	assert.Equal(t, primary, mainCamera(w), "The camera without a name should be the main camera")
+
+	tango.Mailbox.Dispatch(CameraMessage{Camera: "right", Axis: XAxis, Value: 10})
+	tango.Mailbox.Dispatch(CameraMessage{Axis: ZAxis, Value: 2})
+	assert.Equal(t, float32(150), left.X())
+	assert.Equal(t, float32(10), right.X(), "Messages should only move the named camera")
+	assert.Equal(t, float32(1), right.Z())
+	assert.Equal(t, float32(2), primary.Z(), "Messages without a name should move the main camera")
+
+	x, y, width, height := viewportRect([4]int32{0, 0, 800, 600}, right.Viewport)
+	assert.Equal(t, []int{400, 0, 400, 600}, []int{x, y, width, height})
+	x, y, width, height = viewportRect([4]int32{0, 0, 800, 600}, tango.AABB{Min: tango.Point{X: 0.75}, Max: tango.Point{X: 1, Y: 0.25}})
+	assert.Equal(t, []int{600, 450, 200, 150}, []int{x, y, width, height}, "The viewport should be flipped for OpenGL")
+	assert.True(t, primary.fullscreen())
+	assert.False(t, left.fullscreen())
+}
+
+func TestCameraTarget(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	tango.Mailbox = &tango.MessageManager{}
+	CameraBounds = tango.AABB{Min: tango.Point{X: 0, Y: 0}, Max: tango.Point{X: 300, Y: 300}}
+	w := &ecs.World{}
+
+	texture := &RenderTexture{width: 128, height: 64}
+	minimap := &CameraSystem{Name: "minimap", Target: texture, Cached: true}
+	player := &CameraSystem{Name: "player"}
+	w.AddSystem(minimap)
+	w.AddSystem(player)
+	assert.Equal(t, player, mainCamera(w), "A camera rendering to the canvas should be preferred as main camera")
+
+	width, height := viewportSize(minimap)
+	assert.Equal(t, []float32{128, 64}, []float32{width, height}, "A camera with a target should render to the size of the texture")
+	minimap.Viewport = tango.AABB{Max: tango.Point{X: 0.5, Y: 0.5}}
+	width, height = viewportSize(minimap)
+	assert.Equal(t, []float32{64, 32}, []float32{width, height})
+
+	minimap.rendered = true
+	minimap.Refresh()
+	assert.False(t, minimap.rendered, "Refresh should render a cached camera again")
+
+	minX, minY, maxX, maxY := texture.View()
+	assert.Equal(t, []float32{0, 1, 1, 0}, []float32{minX, minY, maxX, maxY}, "The target of a camera should be drawn upright")
+	minX, minY, maxX, maxY = (&RenderTexture{width: 128, height: 64}).View()
+	assert.Equal(t, []float32{0, 0, 1, 1}, []float32{minX, minY, maxX, maxY}, "Other textures shouldn't be flipped")
+}
+
+func TestCameraLayers(t *testing.T) {
+	const minimap LayerMask = 1 << 1
+
+	all := &CameraSystem{}
+	world := &CameraSystem{Layers: DefaultLayer}
+	overview := &CameraSystem{Layers: minimap}
+
+	player := &RenderComponent{}
+	marker := &RenderComponent{Layers: minimap}
+	both := &RenderComponent{Layers: DefaultLayer | minimap}
+
+	assert.True(t, all.renders(player) && all.renders(marker) && all.renders(both), "Cameras without layers should render everything")
+	assert.True(t, world.renders(player), "Entities without layers should be on the default layer")
+	assert.False(t, world.renders(marker))
+	assert.True(t, world.renders(both))
+	assert.False(t, overview.renders(player))
+	assert.True(t, overview.renders(marker))
+	assert.True(t, overview.renders(both))
+}
+
+func TestCameraViewportHUDProjection(t *testing.T) {
+	_, rs := newSoftwareTestWorld()
+	cam := mainCamera(rs.world)
+	cam.Viewport = tango.AABB{Max: tango.Point{X: 0.5, Y: 1}}
+
+	newShader := func(cameraEnabled bool) *basicShader {
+		s := &basicShader{
+			cameraEnabled:    cameraEnabled,
+			projectionMatrix: tango.IdentityMatrix(),
+			viewMatrix:       tango.IdentityMatrix(),
+			projViewMatrix:   tango.IdentityMatrix(),
+			modelMatrix:      tango.IdentityMatrix(),
+			cullingMatrix:    tango.IdentityMatrix(),
+		}
+		s.SetCamera(cam)
+		s.PrepareCulling()
+		return s
+	}
+
+	world := newShader(true)
+	x, y := world.projectionMatrix.ScaleComponent()
+	assert.Equal(t, []float32{2.0 / 100, -2.0 / 100}, []float32{x, y}, "The world should be projected to the viewport of the camera")
+
+	hud := newShader(false)
+	// A Material which was switched to the HUD still knows the camera
+	hud.camera = cam
+	hud.PrepareCulling()
+	x, y = hud.projectionMatrix.ScaleComponent()
+	assert.Equal(t, []float32{2.0 / 200, -2.0 / 100}, []float32{x, y}, "The HUD should be projected to the whole canvas")
+	corner := tango.Point{X: 200, Y: 100}
+	corner.MultiplyMatrixVector(hud.cullingMatrix)
+	assert.InDeltaSlice(t, []float32{1, -1}, []float32{corner.X, corner.Y}, 1e-5, "The corner of the canvas should be the corner of the HUD")
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// viewportMatrix returns the matrix which transforms world coordinates into coordinates within the viewport of the
+// camera, in game units with (0, 0) at its top-left corner. It's the same transformation the shaders use to render.
+func (cam *CameraSystem) viewportMatrix() *tango.Matrix {
+	width, height := viewportSize(cam)
+	x, y, angle := cam.view()
+	scale := tango.GetGlobalScale()
+
+	return tango.IdentityMatrix().
+		Translate(width/2, height/2).
+		Scale(1/cam.z, 1/cam.z).
+		Translate(-x, -y).
+		Rotate(angle).
+		Scale(scale.X, scale.Y)
+}
+
+// screenToCanvas converts screen coordinates, such as those of tango.Input.Mouse, into game units on the canvas. The
+// backends divide the position of the mouse by the GlobalScale, which the viewportMatrix applies again, so it's
+// multiplied back first. Where the canvas isn't scaled to the window, the resize offsets are added instead.
+func screenToCanvas(p tango.Point) tango.Point {
+	scale := tango.GetGlobalScale()
+	p.X *= scale.X
+	p.Y *= scale.Y
+
+	switch tango.CurrentBackEnd {
+	case tango.BackEndGLFW, tango.BackEndSDL, tango.BackEndVulkan:
+		if tango.WindowWidth() > 0 && tango.WindowHeight() > 0 {
+			width, height := viewportSize(nil)
+			p.X *= width / tango.WindowWidth()
+			p.Y *= height / tango.WindowHeight()
+		}
+	case tango.BackEndMobile, tango.BackEndWeb:
+		p.X += tango.ResizeXOffset / 2
+		p.Y += tango.ResizeYOffset / 2
+	}
+	return p
+}
+
+// canvasToScreen is the inverse of screenToCanvas
+func canvasToScreen(p tango.Point) tango.Point {
+	switch tango.CurrentBackEnd {
+	case tango.BackEndGLFW, tango.BackEndSDL, tango.BackEndVulkan:
+		width, height := viewportSize(nil)
+		if width > 0 && height > 0 {
+			p.X *= tango.WindowWidth() / width
+			p.Y *= tango.WindowHeight() / height
+		}
+	case tango.BackEndMobile, tango.BackEndWeb:
+		p.X -= tango.ResizeXOffset / 2
+		p.Y -= tango.ResizeYOffset / 2
+	}
+
+	scale := tango.GetGlobalScale()
+	p.X /= scale.X
+	p.Y /= scale.Y
+	return p
+}
+
+// viewportOrigin returns the top-left corner of the viewport of the camera on the canvas, in game units
+func (cam *CameraSystem) viewportOrigin() tango.Point {
+	width, height := viewportSize(nil)
+	return tango.Point{X: cam.Viewport.Min.X * width, Y: cam.Viewport.Min.Y * height}
+}
+
+// ScreenToWorld converts screen coordinates, such as those of tango.Input.Mouse, into world coordinates as seen
+// through the camera. It takes the position, zoom, rotation and viewport of the camera into account, as well as the
+// GlobalScale and the size of the canvas after resizing the window.
+func (cam *CameraSystem) ScreenToWorld(p tango.Point) tango.Point {
+	p = screenToCanvas(p)
+	p.Subtract(cam.viewportOrigin())
+	return cam.viewportMatrix().InverseTransformPoint(p)
+}
+
+// WorldToScreen converts world coordinates into screen coordinates, such as those of tango.Input.Mouse, as seen
+// through the camera. It's the inverse of ScreenToWorld.
+func (cam *CameraSystem) WorldToScreen(p tango.Point) tango.Point {
+	p = cam.viewportMatrix().TransformPoint(p)
+	p.Add(cam.viewportOrigin())
+	return canvasToScreen(p)
+}
+
+// VisibleBounds returns the bounding box of the part of the world visible through the camera. If the camera is
+// rotated, the box contains the whole rotated view, and thus some parts which aren't visible.
+func (cam *CameraSystem) VisibleBounds() tango.AABB {
+	width, height := viewportSize(cam)
+	m := cam.viewportMatrix()
+
+	bounds := tango.AABB{
+		Min: tango.Point{X: math32.MaxFloat32, Y: math32.MaxFloat32},
+		Max: tango.Point{X: -math32.MaxFloat32, Y: -math32.MaxFloat32},
+	}
+	for _, corner := range [4]tango.Point{{}, {X: width}, {Y: height}, {X: width, Y: height}} {
+		p := m.InverseTransformPoint(corner)
+		bounds.Min.X = math32.Min(bounds.Min.X, p.X)
+		bounds.Min.Y = math32.Min(bounds.Min.Y, p.Y)
+		bounds.Max.X = math32.Max(bounds.Max.X, p.X)
+		bounds.Max.Y = math32.Max(bounds.Max.Y, p.Y)
+	}
+	return bounds
+}
+
+// IsVisible returns whether any part of the bounding box, in world coordinates, is within the VisibleBounds of the
+// camera. It can be used to skip work for entities which are off-screen.
+func (cam *CameraSystem) IsVisible(aabb tango.AABB) bool {
+	bounds := cam.VisibleBounds()
+	return aabb.Min.X <= bounds.Max.X && aabb.Max.X >= bounds.Min.X &&
+		aabb.Min.Y <= bounds.Max.Y && aabb.Max.Y >= bounds.Min.Y
+}
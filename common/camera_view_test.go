@@ -0,0 +1,99 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func initializeCameraView() {
+	tango.Run(tango.RunOptions{HeadlessMode: true, NoRun: true, Width: 800, Height: 600}, &cameraFollowerTestScene{})
+	initialize()
+}
+
+func assertPointInDelta(t *testing.T, expected, actual tango.Point, msgAndArgs ...interface{}) {
+	assert.InDelta(t, expected.X, actual.X, 1e-3, msgAndArgs...)
+	assert.InDelta(t, expected.Y, actual.Y, 1e-3, msgAndArgs...)
+}
+
+func TestCameraScreenToWorld(t *testing.T) {
+	initializeCameraView()
+
+	assertPointInDelta(t, tango.Point{X: 150, Y: 150}, cam.ScreenToWorld(tango.Point{X: 400, Y: 300}), "The center of the screen should be the position of the camera")
+	assertPointInDelta(t, tango.Point{X: -250, Y: -150}, cam.ScreenToWorld(tango.Point{}))
+
+	cam.zoomTo(2)
+	assertPointInDelta(t, tango.Point{X: -650, Y: -450}, cam.ScreenToWorld(tango.Point{}), "Zooming out should show more of the world")
+
+	cam.zoomTo(1)
+	cam.rotateTo(90)
+	assertPointInDelta(t, tango.Point{X: 150, Y: -150}, cam.ScreenToWorld(tango.Point{X: 400, Y: 300}), "Rotating should rotate the world around its origin")
+
+	cam.Viewport = tango.AABB{Min: tango.Point{X: 0.5}, Max: tango.Point{X: 1, Y: 1}}
+	cam.rotateTo(0)
+	assertPointInDelta(t, tango.Point{X: 150, Y: 150}, cam.ScreenToWorld(tango.Point{X: 600, Y: 300}), "The center of the viewport should be the position of the camera")
+}
+
+func TestCameraScreenToWorldGlobalScale(t *testing.T) {
+	initializeCameraView()
+	defer tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+
+	// The backends divide the position of the mouse by the GlobalScale
+	tango.SetGlobalScale(tango.Point{X: 2, Y: 2})
+	assertPointInDelta(t, tango.Point{X: 75, Y: 75}, cam.ScreenToWorld(tango.Point{X: 200, Y: 150}), "The center of the screen should be the position of the camera, divided by the GlobalScale")
+	assertPointInDelta(t, tango.Point{X: -125, Y: -75}, cam.ScreenToWorld(tango.Point{}))
+	assertPointInDelta(t, tango.Point{X: 200, Y: 150}, cam.WorldToScreen(tango.Point{X: 75, Y: 75}))
+
+	cam.zoomTo(2)
+	assertPointInDelta(t, tango.Point{X: -325, Y: -225}, cam.ScreenToWorld(tango.Point{}))
+}
+
+func TestCameraScreenToWorldResizeOffset(t *testing.T) {
+	initializeCameraView()
+	backEnd := tango.CurrentBackEnd
+	defer func() {
+		tango.CurrentBackEnd = backEnd
+		tango.ResizeXOffset, tango.ResizeYOffset = 0, 0
+		tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+	}()
+
+	tango.CurrentBackEnd = tango.BackEndWeb
+	tango.ResizeXOffset, tango.ResizeYOffset = -200, 100
+	assertPointInDelta(t, tango.Point{X: 50, Y: 200}, cam.ScreenToWorld(tango.Point{X: 400, Y: 300}), "The screen should be moved by half the resize offsets")
+	assertPointInDelta(t, tango.Point{X: 400, Y: 300}, cam.WorldToScreen(tango.Point{X: 50, Y: 200}))
+
+	tango.SetGlobalScale(tango.Point{X: 2, Y: 2})
+	assertPointInDelta(t, tango.Point{X: 25, Y: 100}, cam.ScreenToWorld(tango.Point{X: 200, Y: 150}))
+	assertPointInDelta(t, tango.Point{X: 200, Y: 150}, cam.WorldToScreen(tango.Point{X: 25, Y: 100}))
+}
+
+func TestCameraWorldToScreen(t *testing.T) {
+	initializeCameraView()
+	defer tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+
+	tango.SetGlobalScale(tango.Point{X: 2, Y: 2})
+	cam.moveToX(100)
+	cam.zoomTo(1.5)
+	cam.rotateTo(30)
+
+	for _, p := range []tango.Point{{}, {X: 400, Y: 300}, {X: 12, Y: 590}, {X: 799, Y: 1}} {
+		assertPointInDelta(t, p, cam.WorldToScreen(cam.ScreenToWorld(p)), "Converting %v back and forth should return the same point", p)
+	}
+}
+
+func TestCameraVisibleBounds(t *testing.T) {
+	initializeCameraView()
+
+	bounds := cam.VisibleBounds()
+	assertPointInDelta(t, tango.Point{X: -250, Y: -150}, bounds.Min)
+	assertPointInDelta(t, tango.Point{X: 550, Y: 450}, bounds.Max)
+
+	assert.True(t, cam.IsVisible(tango.AABB{Min: tango.Point{X: 540, Y: 440}, Max: tango.Point{X: 600, Y: 500}}))
+	assert.False(t, cam.IsVisible(tango.AABB{Min: tango.Point{X: 560, Y: 0}, Max: tango.Point{X: 600, Y: 10}}))
+
+	cam.rotateTo(90)
+	bounds = cam.VisibleBounds()
+	assert.InDelta(t, 600, bounds.Max.X-bounds.Min.X, 1e-3, "Rotating the camera should rotate the visible area")
+	assert.InDelta(t, 800, bounds.Max.Y-bounds.Min.Y, 1e-3, "Rotating the camera should rotate the visible area")
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// EaseFunc maps the progress of an animation over time, from 0 to 1, to the progress of the animated value. The
+// value may go below 0 or above 1 in between, to overshoot the start or the end.
+type EaseFunc func(t float32) float32
+
+const (
+	easeBackOvershoot   = 1.70158
+	easeElasticPeriod   = 2 * math32.Pi / 3
+	easeBounceStiffness = 7.5625
+	easeBounceDivisor   = 2.75
+)
+
+// EaseLinear moves at a constant speed.
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad starts slowly and accelerates quadratically.
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad starts quickly and decelerates quadratically.
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates quadratically until halfway, then decelerates.
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slowly and accelerates cubically.
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic starts quickly and decelerates cubically.
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates cubically until halfway, then decelerates.
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// EaseInElastic wobbles around the start with a growing amplitude before snapping to the end.
+func EaseInElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return -math32.Pow(2, 10*t-10) * math32.Sin((10*t-10.75)*easeElasticPeriod)
+}
+
+// EaseOutElastic snaps to the end and wobbles around it with a shrinking amplitude.
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return math32.Pow(2, -10*t)*math32.Sin((10*t-0.75)*easeElasticPeriod) + 1
+}
+
+// EaseInOutElastic combines EaseInElastic for the first half with EaseOutElastic for the second half.
+func EaseInOutElastic(t float32) float32 {
+	if t < 0.5 {
+		return EaseInElastic(2*t) / 2
+	}
+	return (1 + EaseOutElastic(2*t-1)) / 2
+}
+
+// EaseInBounce bounces off the start with growing bounces before reaching the end.
+func EaseInBounce(t float32) float32 {
+	return 1 - EaseOutBounce(1-t)
+}
+
+// EaseOutBounce reaches the end quickly and bounces off it a few times, like a dropped ball.
+func EaseOutBounce(t float32) float32 {
+	switch {
+	case t < 1/easeBounceDivisor:
+		return easeBounceStiffness * t * t
+	case t < 2/easeBounceDivisor:
+		t -= 1.5 / easeBounceDivisor
+		return easeBounceStiffness*t*t + 0.75
+	case t < 2.5/easeBounceDivisor:
+		t -= 2.25 / easeBounceDivisor
+		return easeBounceStiffness*t*t + 0.9375
+	default:
+		t -= 2.625 / easeBounceDivisor
+		return easeBounceStiffness*t*t + 0.984375
+	}
+}
+
+// EaseInOutBounce combines EaseInBounce for the first half with EaseOutBounce for the second half.
+func EaseInOutBounce(t float32) float32 {
+	if t < 0.5 {
+		return EaseInBounce(2*t) / 2
+	}
+	return (1 + EaseOutBounce(2*t-1)) / 2
+}
+
+// EaseInBack pulls back a little before moving to the end.
+func EaseInBack(t float32) float32 {
+	return t * t * ((easeBackOvershoot+1)*t - easeBackOvershoot)
+}
+
+// EaseOutBack overshoots the end a little before settling on it.
+func EaseOutBack(t float32) float32 {
+	t--
+	return t*t*((easeBackOvershoot+1)*t+easeBackOvershoot) + 1
+}
+
+// EaseInOutBack combines EaseInBack for the first half with EaseOutBack for the second half.
+func EaseInOutBack(t float32) float32 {
+	if t < 0.5 {
+		return EaseInBack(2*t) / 2
+	}
+	return (1 + EaseOutBack(2*t-1)) / 2
+}
@@ -64,6 +64,31 @@ func (c *CollisionComponent) GetCollisionComponent() *CollisionComponent {
 	return c
 }
 
+// GetTextField Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *TextField) GetTextField() *TextField {
+	return c
+}
+
+// GetParticleComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *ParticleComponent) GetParticleComponent() *ParticleComponent {
+	return c
+}
+
+// GetLightComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *LightComponent) GetLightComponent() *LightComponent {
+	return c
+}
+
+// GetOccluderComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *OccluderComponent) GetOccluderComponent() *OccluderComponent {
+	return c
+}
+
+// GetNormalMapComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *NormalMapComponent) GetNormalMapComponent() *NormalMapComponent {
+	return c
+}
+
 // Faces
 
 // BasicFace is the means of accessing the ecs.BasicEntity class , it also has the ID method, to simplify, finding an item within a system
@@ -102,6 +127,31 @@ type CollisionFace interface {
 	GetCollisionComponent() *CollisionComponent
 }
 
+// TextFieldFace allows typesafe access to an anonymous TextField
+type TextFieldFace interface {
+	GetTextField() *TextField
+}
+
+// ParticleFace allows typesafe access to an anonymous ParticleComponent
+type ParticleFace interface {
+	GetParticleComponent() *ParticleComponent
+}
+
+// LightFace allows typesafe access to an anonymous LightComponent
+type LightFace interface {
+	GetLightComponent() *LightComponent
+}
+
+// OccluderFace allows typesafe access to an anonymous OccluderComponent
+type OccluderFace interface {
+	GetOccluderComponent() *OccluderComponent
+}
+
+// NormalMapFace allows typesafe access to an anonymous NormalMapComponent
+type NormalMapFace interface {
+	GetNormalMapComponent() *NormalMapComponent
+}
+
 // Combined for systems
 
 // Animationable is the required interface for AnimationSystem.AddByInterface method
@@ -139,6 +189,36 @@ type Collisionable interface {
 	SpaceFace
 }
 
+// TextFieldable is the required interface for the TextFieldSystem.AddByInterface method
+type TextFieldable interface {
+	BasicFace
+	TextFieldFace
+	RenderFace
+	SpaceFace
+}
+
+// Particleable is the required interface for the ParticleSystem.AddByInterface method
+type Particleable interface {
+	BasicFace
+	ParticleFace
+	RenderFace
+	SpaceFace
+}
+
+// SVGable is the required interface for the SVGSystem.AddByInterface method
+type SVGable interface {
+	BasicFace
+	RenderFace
+}
+
+// Lightable is the required interface for the LightSystem.AddByInterface
+// method. The entity is added for each of the LightFace, OccluderFace and
+// NormalMapFace it meets.
+type Lightable interface {
+	BasicFace
+	SpaceFace
+}
+
 // Not-Ables
 
 // NotAnimationComponent is used to flag an entity as not in the AnimationSystem
@@ -215,3 +295,63 @@ func (n *NotCollisionComponent) GetNotCollisionComponent() *NotCollisionComponen
 type NotCollisionable interface {
 	GetNotCollisionComponent() *NotCollisionComponent
 }
+
+// NotTextFieldComponent is used to flag an entity as not in the TextFieldSystem
+// even if it has the proper components
+type NotTextFieldComponent struct{}
+
+// GetNotTextFieldComponent implements the NotTextFieldable interface
+func (n *NotTextFieldComponent) GetNotTextFieldComponent() *NotTextFieldComponent {
+	return n
+}
+
+// NotTextFieldable is an interface used to flag an entity as not in the
+// TextFieldSystem even if it has the proper components
+type NotTextFieldable interface {
+	GetNotTextFieldComponent() *NotTextFieldComponent
+}
+
+// NotParticleComponent is used to flag an entity as not in the ParticleSystem
+// even if it has the proper components
+type NotParticleComponent struct{}
+
+// GetNotParticleComponent implements the NotParticleable interface
+func (n *NotParticleComponent) GetNotParticleComponent() *NotParticleComponent {
+	return n
+}
+
+// NotParticleable is an interface used to flag an entity as not in the
+// ParticleSystem even if it has the proper components
+type NotParticleable interface {
+	GetNotParticleComponent() *NotParticleComponent
+}
+
+// NotSVGComponent is used to flag an entity as not in the SVGSystem even if
+// it has the proper components
+type NotSVGComponent struct{}
+
+// GetNotSVGComponent implements the NotSVGable interface
+func (n *NotSVGComponent) GetNotSVGComponent() *NotSVGComponent {
+	return n
+}
+
+// NotSVGable is an interface used to flag an entity as not in the SVGSystem
+// even if it has the proper components
+type NotSVGable interface {
+	GetNotSVGComponent() *NotSVGComponent
+}
+
+// NotLightComponent is used to flag an entity as not in the LightSystem even
+// if it has the proper components
+type NotLightComponent struct{}
+
+// GetNotLightComponent implements the NotLightable interface
+func (n *NotLightComponent) GetNotLightComponent() *NotLightComponent {
+	return n
+}
+
+// NotLightable is an interface used to flag an entity as not in the
+// LightSystem even if it has the proper components
+type NotLightable interface {
+	GetNotLightComponent() *NotLightComponent
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/inkeliz-technologies/ecs"
 	"github.com/inkeliz-technologies/tango"
-	"github.com/inkeliz-technologies/tango/math32"
 )
 
 // Cursor is a reference to a GLFW-cursor - to be used with the `SetCursor` method.
@@ -131,12 +130,7 @@ func (m *MouseSystem) New(w *ecs.World) {
 	m.world = w
 
 	// First check if the CameraSystem is available
-	for _, system := range m.world.Systems() {
-		switch sys := system.(type) {
-		case *CameraSystem:
-			m.camera = sys
-		}
-	}
+	m.camera = mainCamera(m.world)
 
 	if m.camera == nil {
 		log.Println("ERROR: CameraSystem not found - have you added the `RenderSystem` before the `MouseSystem`?")
@@ -177,20 +171,8 @@ func (m *MouseSystem) Remove(basic ecs.BasicEntity) {
 // Update updates all the entities in the MouseSystem.
 func (m *MouseSystem) Update(dt float32) {
 	// Translate Mouse.X and Mouse.Y into "game coordinates"
-	switch tango.CurrentBackEnd {
-	case tango.BackEndGLFW, tango.BackEndSDL, tango.BackEndVulkan:
-		m.mouseX = ((tango.Input.Mouse.X * m.camera.Z() * tango.GameWidth() / tango.WindowWidth()) + (m.camera.X()-(tango.GameWidth()/2)*m.camera.Z())/tango.GetGlobalScale().X)
-		m.mouseY = ((tango.Input.Mouse.Y * m.camera.Z() * tango.GameHeight() / tango.WindowHeight()) + (m.camera.Y()-(tango.GameHeight()/2)*m.camera.Z())/tango.GetGlobalScale().Y)
-	case tango.BackEndMobile, tango.BackEndWeb:
-		m.mouseX = tango.Input.Mouse.X*m.camera.Z() + (m.camera.X()-(tango.GameWidth()/2)*m.camera.Z()+(tango.ResizeXOffset/2))/tango.GetGlobalScale().X
-		m.mouseY = tango.Input.Mouse.Y*m.camera.Z() + (m.camera.Y()-(tango.GameHeight()/2)*m.camera.Z()+(tango.ResizeYOffset/2))/tango.GetGlobalScale().Y
-	}
-
-	// Rotate if needed
-	if m.camera.angle != 0 {
-		sin, cos := math32.Sincos(m.camera.angle * math32.Pi / 180)
-		m.mouseX, m.mouseY = m.mouseX*cos+m.mouseY*sin, m.mouseY*cos-m.mouseX*sin
-	}
+	mouse := m.camera.ScreenToWorld(tango.Point{X: tango.Input.Mouse.X, Y: tango.Input.Mouse.Y})
+	m.mouseX, m.mouseY = mouse.X, mouse.Y
 
 	for _, e := range m.entities {
 		// Reset all values except these
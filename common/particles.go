@@ -0,0 +1,405 @@
+package common
+
+import (
+	"image/color"
+	"math/rand"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// ParticleRange is a range of values, of which each particle gets a random one.
+type ParticleRange struct {
+	Min, Max float32
+}
+
+// value returns a random value within the range.
+func (r ParticleRange) value(rng *rand.Rand) float32 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rng.Float32()*(r.Max-r.Min)
+}
+
+// ParticleCurve is how a value of a particle changes over its lifetime, from Start when it's spawned to End when it
+// dies. If both are zero, the value is kept at 1.
+type ParticleCurve struct {
+	Start, End float32
+	// Ease is the easing function from Start to End. It's EaseLinear if nil.
+	Ease EaseFunc
+}
+
+// at returns the value of the curve at the given progress, from 0 to 1.
+func (c ParticleCurve) at(progress float32) float32 {
+	if c.Start == 0 && c.End == 0 {
+		return 1
+	}
+	if c.Ease != nil {
+		progress = c.Ease(progress)
+	}
+	return c.Start + (c.End-c.Start)*progress
+}
+
+// ParticleBurst spawns a number of particles at once, at a time after the emitter was added to the ParticleSystem.
+type ParticleBurst struct {
+	// Time is the time of the burst, in seconds.
+	Time float32
+	// Count is the number of particles spawned.
+	Count int
+	// Interval repeats the burst every Interval seconds after Time. The burst happens once if it's zero.
+	Interval float32
+}
+
+// occurrences returns the number of times the burst happened before the given time.
+func (b ParticleBurst) occurrences(time float32) int {
+	if time <= b.Time {
+		return 0
+	}
+	if b.Interval <= 0 {
+		return 1
+	}
+	return int(math32.Ceil((time - b.Time) / b.Interval))
+}
+
+// EmissionShape is the area particles are spawned in.
+type EmissionShape interface {
+	// Spawn returns a random point within the shape, relative to the center of the emitter.
+	Spawn(rng *rand.Rand) tango.Point
+}
+
+// EmitPoint spawns all particles at the center of the emitter.
+type EmitPoint struct{}
+
+// Spawn returns the center of the emitter. This implements the EmissionShape interface.
+func (EmitPoint) Spawn(*rand.Rand) tango.Point {
+	return tango.Point{}
+}
+
+// EmitCircle spawns particles within a circle around the center of the emitter.
+type EmitCircle struct {
+	Radius float32
+}
+
+// Spawn returns a random point within the circle. This implements the EmissionShape interface.
+func (c EmitCircle) Spawn(rng *rand.Rand) tango.Point {
+	// The square root spreads the points evenly over the area, instead of bunching them up at the center
+	r := c.Radius * math32.Sqrt(rng.Float32())
+	sin, cos := math32.Sincos(rng.Float32() * 2 * math32.Pi)
+	return tango.Point{X: r * cos, Y: r * sin}
+}
+
+// EmitRectangle spawns particles within a rectangle centered on the emitter.
+type EmitRectangle struct {
+	Width, Height float32
+}
+
+// Spawn returns a random point within the rectangle. This implements the EmissionShape interface.
+func (r EmitRectangle) Spawn(rng *rand.Rand) tango.Point {
+	return tango.Point{X: (rng.Float32() - 0.5) * r.Width, Y: (rng.Float32() - 0.5) * r.Height}
+}
+
+// Particle is a particle spawned by a ParticleComponent. Its Position is in world coordinates, so particles which
+// were already spawned don't follow the emitter around.
+type Particle struct {
+	Position, Velocity tango.Point
+	// Age and Lifetime are the time, in seconds, since the particle was spawned, and at which it dies.
+	Age, Lifetime float32
+	// Color and Scale are the color and scale of the particle at its age.
+	Color color.NRGBA
+	Scale float32
+}
+
+// ParticleComponent is an emitter of particles, spawned at the center of its SpaceComponent and simulated by the
+// ParticleSystem. The particles are drawn by the RenderSystem with the Drawable of the RenderComponent, centered on
+// their position, as a single batch instead of an entity each.
+//
+// The simulation is deterministic: emitters with the same Seed spawn the same particles when updated with the same
+// time steps.
+type ParticleComponent struct {
+	// Rate is the number of particles spawned per second.
+	Rate float32
+	// Bursts spawn a number of particles at once, on top of the Rate.
+	Bursts []ParticleBurst
+	// Stopped stops spawning particles with the Rate and the Bursts, while the spawned ones live out their lifetime.
+	Stopped bool
+	// MaxParticles is the maximum number of particles alive at once. No particles are spawned while it's reached.
+	// There is no maximum if it's zero.
+	MaxParticles int
+
+	// Shape is the area particles are spawned in. It's EmitPoint if nil.
+	Shape EmissionShape
+	// Lifetime is the time, in seconds, a particle lives.
+	Lifetime ParticleRange
+	// Speed is the speed particles are spawned with, in units per second.
+	Speed ParticleRange
+	// Angle is the direction particles are spawned towards, in degrees clockwise from the right. It's relative to
+	// the Rotation of the SpaceComponent, which rotates the Shape as well.
+	Angle ParticleRange
+	// Gravity is the acceleration of the particles, in units per second squared.
+	Gravity tango.Point
+
+	// StartColor and EndColor are the colors particles fade from and to over their lifetime. The StartColor is
+	// white if nil, and the EndColor is the StartColor if nil.
+	StartColor, EndColor color.Color
+	// Alpha multiplies the alpha of the color of particles over their lifetime.
+	Alpha ParticleCurve
+	// Scale scales particles over their lifetime, on top of the Scale of the RenderComponent.
+	Scale ParticleCurve
+
+	// Seed seeds the random number generator, when the emitter is added to the ParticleSystem.
+	Seed int64
+
+	particles []Particle
+	rng       *rand.Rand
+	time      float32
+	// spawning is the fraction of a particle left over from spawning with the Rate
+	spawning float32
+	burst    int
+}
+
+// Particles returns the particles which are alive, from the oldest to the newest. The slice is reused by the next
+// update.
+func (c *ParticleComponent) Particles() []Particle {
+	return c.particles
+}
+
+// Burst spawns the given number of particles with the next update, even if the emitter is Stopped.
+func (c *ParticleComponent) Burst(count int) {
+	c.burst += count
+}
+
+// Clear removes all particles.
+func (c *ParticleComponent) Clear() {
+	c.particles = c.particles[:0]
+}
+
+// reset seeds the emitter, and starts it over.
+func (c *ParticleComponent) reset() {
+	c.rng = rand.New(rand.NewSource(c.Seed))
+	c.particles = c.particles[:0]
+	c.time, c.spawning = 0, 0
+}
+
+// update advances the particles by dt seconds, removing the ones which died, and spawns new ones.
+func (c *ParticleComponent) update(dt float32, space *SpaceComponent) {
+	start, end := c.colors()
+
+	alive := c.particles[:0]
+	for _, p := range c.particles {
+		p.Age += dt
+		if p.Age >= p.Lifetime {
+			continue
+		}
+		p.Velocity.X += c.Gravity.X * dt
+		p.Velocity.Y += c.Gravity.Y * dt
+		p.Position.X += p.Velocity.X * dt
+		p.Position.Y += p.Velocity.Y * dt
+		c.age(&p, start, end)
+		alive = append(alive, p)
+	}
+	c.particles = alive
+
+	previous := c.time
+	c.time += dt
+	count := c.burst
+	c.burst = 0
+	if !c.Stopped {
+		c.spawning += c.Rate * dt
+		count += int(c.spawning)
+		c.spawning -= float32(int(c.spawning))
+		for _, b := range c.Bursts {
+			count += (b.occurrences(c.time) - b.occurrences(previous)) * b.Count
+		}
+	}
+	if c.MaxParticles > 0 && len(c.particles)+count > c.MaxParticles {
+		count = c.MaxParticles - len(c.particles)
+	}
+	for i := 0; i < count; i++ {
+		c.spawn(space, start, end)
+	}
+}
+
+// colors returns the colors particles fade from and to.
+func (c *ParticleComponent) colors() (color.NRGBA, color.NRGBA) {
+	start := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	if c.StartColor != nil {
+		start = color.NRGBAModel.Convert(c.StartColor).(color.NRGBA)
+	}
+	end := start
+	if c.EndColor != nil {
+		end = color.NRGBAModel.Convert(c.EndColor).(color.NRGBA)
+	}
+	return start, end
+}
+
+// spawn adds a particle at the center of the emitter.
+func (c *ParticleComponent) spawn(space *SpaceComponent, start, end color.NRGBA) {
+	shape := c.Shape
+	if shape == nil {
+		shape = EmitPoint{}
+	}
+	offset := shape.Spawn(c.rng)
+	angle := c.Angle.value(c.rng)
+	speed := c.Speed.value(c.rng)
+	lifetime := c.Lifetime.value(c.rng)
+
+	if space.Rotation != 0 {
+		offset.Rotate(space.Rotation)
+		angle += space.Rotation
+	}
+	sin, cos := math32.Sincos(angle * tango.DegToRad)
+
+	center := space.Center()
+	p := Particle{
+		Position: *center.Add(offset),
+		Velocity: tango.Point{X: speed * cos, Y: speed * sin},
+		Lifetime: lifetime,
+	}
+	c.age(&p, start, end)
+	c.particles = append(c.particles, p)
+}
+
+// age sets the color and scale of the particle at its age.
+func (c *ParticleComponent) age(p *Particle, start, end color.NRGBA) {
+	progress := float32(1)
+	if p.Lifetime > 0 {
+		progress = p.Age / p.Lifetime
+	}
+	lerp := func(from, to uint8) uint8 {
+		return uint8(float32(from) + (float32(to)-float32(from))*progress + 0.5)
+	}
+	p.Color = color.NRGBA{
+		R: lerp(start.R, end.R),
+		G: lerp(start.G, end.G),
+		B: lerp(start.B, end.B),
+	}
+	alpha := float32(lerp(start.A, end.A)) * c.Alpha.at(progress)
+	p.Color.A = uint8(math32.Min(math32.Max(alpha, 0), 0xff) + 0.5)
+	p.Scale = c.Scale.at(progress)
+}
+
+// Particles is a Drawable which draws the particles of a ParticleComponent with the Drawable. The ParticleSystem
+// sets it as the Drawable of the RenderComponents of its entities. It's drawn by the DefaultShader and the HUDShader,
+// with a quad per particle, batched with the other sprites of the same texture.
+type Particles struct {
+	// Drawable is the image of a particle, such as a Texture or a cell of a Spritesheet.
+	Drawable Drawable
+	// Component is the emitter of the particles.
+	Component *ParticleComponent
+}
+
+// Texture returns the texture of the Drawable. This implements the Drawable interface.
+func (p Particles) Texture() *gl.Texture { return p.Drawable.Texture() }
+
+// Width returns the width of the Drawable, which is the width of a particle. This implements the Drawable interface.
+func (p Particles) Width() float32 { return p.Drawable.Width() }
+
+// Height returns the height of the Drawable, which is the height of a particle. This implements the Drawable
+// interface.
+func (p Particles) Height() float32 { return p.Drawable.Height() }
+
+// View returns the view of the Drawable. This implements the Drawable interface.
+func (p Particles) View() (float32, float32, float32, float32) { return p.Drawable.View() }
+
+// Close closes the Drawable. This implements the Drawable interface.
+func (p Particles) Close() { p.Drawable.Close() }
+
+// bounds returns the area covered by the particles, drawn at the given scale.
+func (p Particles) bounds(scale tango.Point) tango.AABB {
+	particles := p.Component.Particles()
+	if len(particles) == 0 {
+		return tango.AABB{}
+	}
+	bounds := tango.AABB{Min: particles[0].Position, Max: particles[0].Position}
+	size := float32(0)
+	for _, particle := range particles {
+		bounds.Min.X = math32.Min(bounds.Min.X, particle.Position.X)
+		bounds.Min.Y = math32.Min(bounds.Min.Y, particle.Position.Y)
+		bounds.Max.X = math32.Max(bounds.Max.X, particle.Position.X)
+		bounds.Max.Y = math32.Max(bounds.Max.Y, particle.Position.Y)
+		size = math32.Max(size, math32.Abs(particle.Scale))
+	}
+	// The particles are centered on their position, so half of the largest one may stick out
+	half := tango.Point{
+		X: p.Drawable.Width() * math32.Abs(scale.X) * size / 2,
+		Y: p.Drawable.Height() * math32.Abs(scale.Y) * size / 2,
+	}
+	bounds.Min.Subtract(half)
+	bounds.Max.Add(half)
+	return bounds
+}
+
+// generateQuads fills the buffer with a quad for each particle, in world coordinates.
+func (p Particles) generateQuads(s *basicShader, ren *RenderComponent, buffer []float32) {
+	u, v, u2, v2 := p.Drawable.View()
+	width, height := p.Drawable.Width()/2, p.Drawable.Height()/2
+	scale := tango.GetGlobalScale()
+	for i, particle := range p.Component.Particles() {
+		tint := colorToFloat32(particle.Color)
+		quad := buffer[i*spriteSize : (i+1)*spriteSize]
+		copy(quad, []float32{
+			-width, -height, u, v, tint,
+			width, -height, u2, v, tint,
+			width, height, u2, v2, tint,
+			-width, height, u, v2, tint,
+		})
+		s.modelMatrix.Identity().Scale(scale.X, scale.Y).Translate(particle.Position.X, particle.Position.Y).
+			Scale(ren.Scale.X*particle.Scale, ren.Scale.Y*particle.Scale)
+		for j := 0; j < spriteSize; j += 5 {
+			s.multModel(s.modelMatrix, quad[j:j+2])
+		}
+	}
+}
+
+type particleEntity struct {
+	*ecs.BasicEntity
+	*ParticleComponent
+	*SpaceComponent
+}
+
+// ParticleSystem simulates the particles of ParticleComponents. The particles of an entity are drawn by the
+// RenderSystem, if the entity is added to it as well.
+type ParticleSystem struct {
+	entities []particleEntity
+}
+
+// Add starts emitting particles from the entity. The Drawable of the RenderComponent is replaced by Particles, which
+// draws the particles with it.
+func (p *ParticleSystem) Add(basic *ecs.BasicEntity, particles *ParticleComponent, render *RenderComponent, space *SpaceComponent) {
+	particles.reset()
+	if _, ok := render.Drawable.(Particles); !ok {
+		render.Drawable = Particles{Drawable: render.Drawable, Component: particles}
+	}
+	p.entities = append(p.entities, particleEntity{basic, particles, space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Particleable interface, which every entity
+// containing the BasicEntity, ParticleComponent, RenderComponent and SpaceComponent anonymously automatically
+// satisfies.
+func (p *ParticleSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Particleable)
+	p.Add(o.GetBasicEntity(), o.GetParticleComponent(), o.GetRenderComponent(), o.GetSpaceComponent())
+}
+
+// Remove stops emitting particles from the given entity.
+func (p *ParticleSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range p.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		p.entities = append(p.entities[:delete], p.entities[delete+1:]...)
+	}
+}
+
+// Update advances the particles of all emitters by dt seconds, and spawns new ones.
+func (p *ParticleSystem) Update(dt float32) {
+	for _, e := range p.entities {
+		e.ParticleComponent.update(dt, e.SpaceComponent)
+	}
+}
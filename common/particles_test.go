@@ -0,0 +1,205 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func newParticleTestEmitter(c *ParticleComponent) (*ParticleSystem, *SpaceComponent) {
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{Position: tango.Point{X: 10, Y: 20}, Width: 20, Height: 10}
+	ps := &ParticleSystem{}
+	ps.Add(&basic, c, &RenderComponent{Drawable: Rectangle{}}, space)
+	return ps, space
+}
+
+func TestParticleSystemDeterministic(t *testing.T) {
+	simulate := func(seed int64) []Particle {
+		c := &ParticleComponent{
+			Rate:     50,
+			Lifetime: ParticleRange{Min: 0.5, Max: 1},
+			Speed:    ParticleRange{Min: 10, Max: 20},
+			Angle:    ParticleRange{Min: 0, Max: 360},
+			Shape:    EmitCircle{Radius: 5},
+			Seed:     seed,
+		}
+		ps, _ := newParticleTestEmitter(c)
+		for i := 0; i < 30; i++ {
+			ps.Update(1.0 / 60)
+		}
+		return append([]Particle(nil), c.Particles()...)
+	}
+
+	first := simulate(42)
+	assert.Len(t, first, 25, "Particles should be spawned at the Rate")
+	assert.Equal(t, first, simulate(42), "The same seed should spawn the same particles")
+	assert.NotEqual(t, first, simulate(7), "Another seed should spawn other particles")
+}
+
+func TestParticleSystemSpawning(t *testing.T) {
+	c := &ParticleComponent{
+		Rate:     10,
+		Lifetime: ParticleRange{Min: 100},
+		Bursts:   []ParticleBurst{{Time: 0, Count: 3}, {Time: 0.5, Count: 1, Interval: 0.5}},
+	}
+	ps, _ := newParticleTestEmitter(c)
+
+	ps.Update(0.25)
+	assert.Len(t, c.Particles(), 2+3, "The first burst should happen with the first update")
+	ps.Update(0.25)
+	assert.Len(t, c.Particles(), 5+3, "The fraction of a particle left over should be spawned later")
+	ps.Update(0.25)
+	assert.Len(t, c.Particles(), 8+2+1, "The second burst should happen at its time")
+	ps.Update(0.5)
+	assert.Len(t, c.Particles(), 11+5+1, "The second burst should repeat")
+
+	c.Stopped = true
+	ps.Update(1)
+	assert.Len(t, c.Particles(), 17, "A stopped emitter shouldn't spawn particles")
+	c.Burst(4)
+	ps.Update(0.1)
+	assert.Len(t, c.Particles(), 21, "A burst should spawn particles even if the emitter is stopped")
+
+	c.Clear()
+	c.Stopped = false
+	c.MaxParticles = 5
+	ps.Update(1)
+	assert.Len(t, c.Particles(), 5, "No more than MaxParticles should be alive")
+}
+
+func TestParticleSystemSimulation(t *testing.T) {
+	c := &ParticleComponent{
+		Lifetime:   ParticleRange{Min: 1, Max: 1},
+		Speed:      ParticleRange{Min: 10, Max: 10},
+		Angle:      ParticleRange{Min: 90, Max: 90},
+		Gravity:    tango.Point{X: 4},
+		StartColor: color.NRGBA{R: 0xff, A: 0xff},
+		EndColor:   color.NRGBA{B: 0xff, A: 0xff},
+		Alpha:      ParticleCurve{Start: 1, End: 0},
+		Scale:      ParticleCurve{Start: 1, End: 3, Ease: EaseInQuad},
+	}
+	ps, space := newParticleTestEmitter(c)
+	c.Burst(1)
+	ps.Update(0.5)
+	if !assert.Len(t, c.Particles(), 1) {
+		return
+	}
+	p := c.Particles()[0]
+	assert.Equal(t, tango.Point{X: 20, Y: 25}, p.Position, "Particles should spawn at the center of the emitter")
+	assert.InDelta(t, 0, p.Velocity.X, 1e-5)
+	assert.InDelta(t, 10, p.Velocity.Y, 1e-5, "The angle should be clockwise from the right")
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, p.Color)
+	assert.Equal(t, float32(1), p.Scale)
+
+	space.Position.X = 100
+	ps.Update(0.5)
+	p = c.Particles()[0]
+	assert.InDelta(t, 20+4*0.5*0.5, p.Position.X, 1e-5, "Particles shouldn't follow the emitter, but fall with gravity")
+	assert.InDelta(t, 25+10*0.5, p.Position.Y, 1e-5)
+	assert.Equal(t, color.NRGBA{R: 0x80, B: 0x80, A: 0x80}, p.Color, "The color and alpha should change over the lifetime")
+	assert.InDelta(t, 1.5, p.Scale, 1e-5, "The scale should change with the easing function")
+
+	ps.Update(0.5)
+	assert.Empty(t, c.Particles(), "Particles should die at the end of their lifetime")
+}
+
+func TestParticleSystemRotation(t *testing.T) {
+	c := &ParticleComponent{
+		Lifetime: ParticleRange{Min: 1},
+		Speed:    ParticleRange{Min: 10},
+		Shape:    EmitRectangle{Width: 4},
+	}
+	ps, space := newParticleTestEmitter(c)
+	space.Rotation = 90
+	c.Burst(1)
+	ps.Update(0.1)
+	if !assert.Len(t, c.Particles(), 1) {
+		return
+	}
+	p := c.Particles()[0]
+	assert.InDelta(t, 10, p.Velocity.Y, 1e-5, "The angle should be relative to the rotation of the emitter")
+	assert.InDelta(t, space.Center().X, p.Position.X, 1e-5, "The shape should be rotated with the emitter")
+	assert.InDelta(t, space.Center().Y, p.Position.Y, 2+1e-5)
+}
+
+func TestEmissionShapes(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, tango.Point{}, EmitPoint{}.Spawn(rng))
+
+		p := EmitCircle{Radius: 5}.Spawn(rng)
+		if !assert.True(t, p.Length() <= 5, "%v should be within the circle", p) {
+			break
+		}
+
+		p = EmitRectangle{Width: 4, Height: 2}.Spawn(rng)
+		if !assert.True(t, p.X >= -2 && p.X <= 2 && p.Y >= -1 && p.Y <= 1, "%v should be within the rectangle", p) {
+			break
+		}
+	}
+}
+
+func TestParticlesBufferContent(t *testing.T) {
+	c := &ParticleComponent{Lifetime: ParticleRange{Min: 1}, Scale: ParticleCurve{Start: 2, End: 2}}
+	ren := &RenderComponent{Drawable: Texture{width: 4, height: 2, viewport: tango.AABB{Max: tango.Point{X: 1, Y: 1}}}, Scale: tango.Point{X: 1, Y: 1}}
+	basic := ecs.NewBasic()
+	ps := &ParticleSystem{}
+	ps.Add(&basic, c, ren, &SpaceComponent{})
+	c.Burst(2)
+	ps.Update(0.1)
+
+	s := &basicShader{modelMatrix: tango.IdentityMatrix()}
+	tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+	if !assert.Equal(t, 2, s.countQuads(ren, nil), "Particles should be drawn with a quad each") {
+		return
+	}
+	buffer := make([]float32, 2*spriteSize)
+	s.generateQuads(ren, nil, buffer)
+	assert.Equal(t, []float32{-4, -2, 0, 0}, buffer[0:4], "Particles should be centered on their position")
+	assert.Equal(t, []float32{4, 2, 1, 1}, buffer[10:14])
+	assert.Equal(t, colorToFloat32(color.White), buffer[4])
+}
+
+func TestParticlesSoftwareRendering(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+	ps := &ParticleSystem{}
+	w.AddSystem(ps)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	texture := NewTextureSingle(NewImageObject(img))
+	defer texture.Close()
+
+	basic := ecs.NewBasic()
+	particles := &ParticleComponent{
+		Lifetime:   ParticleRange{Min: 10},
+		Speed:      ParticleRange{Min: 200},
+		StartColor: color.RGBA{G: 0xff, A: 0xff},
+	}
+	render := &RenderComponent{Drawable: texture}
+	space := &SpaceComponent{Position: tango.Point{X: 40, Y: 40}, Width: 20, Height: 20}
+	ps.Add(&basic, particles, render, space)
+	rs.Add(&basic, render, space)
+
+	particles.Burst(1)
+	w.Update(0.1)
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 48, 48, "The particle should be drawn at the center of the emitter")
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 51, 51)
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 52, 50, "The particle should be the size of the Drawable")
+
+	particles.Burst(1)
+	w.Update(0.1)
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 50, 50, "Every particle should be drawn")
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 70, 50)
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 60, 50)
+}
@@ -4,8 +4,8 @@ import (
 	"github.com/inkeliz-technologies/ecs"
 	"github.com/inkeliz-technologies/tango"
 	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/inkeliz-technologies/tango/math32"
 	"image/color"
-	"sort"
 	"sync"
 	"unsafe"
 )
@@ -56,6 +56,12 @@ const (
 	FilterLinear
 )
 
+// LayerMask is a set of layers, one per bit, which selects the entities a CameraSystem renders.
+type LayerMask uint32
+
+// DefaultLayer is the layer of entities whose RenderComponent doesn't set any Layers.
+const DefaultLayer LayerMask = 1
+
 // RenderComponent is the component needed to render an entity.
 type RenderComponent struct {
 	// Hidden is used to prevent drawing by OpenGL
@@ -69,8 +75,8 @@ type RenderComponent struct {
 	// Repeat defines how to repeat the Texture if the SpaceComponent of the entity
 	// is larger than the texture itself, after applying scale. Defaults to NoRepeat
 	// which allows the texture to draw entirely without regard to th SpaceComponent
-	// Do not set to anything other than NoRepeat for textures in a sprite sheet.
-	// This does not yet work with sprite sheets.
+	// Repeat and MirroredRepeat also work with the cells of a sprite sheet, which are
+	// drawn once for every time they're repeated.
 	Repeat TextureRepeating
 	// Buffer represents the buffer object itself
 	// Avoid using it unless your are writing a custom shader
@@ -85,11 +91,23 @@ type RenderComponent struct {
 	// StartShader defines the initial Shader. Beware that you must use `SetShader` function to change
 	// the Shader.
 	StartShader Shader
+	// Layers are the layers the entity is on. Cameras only render the entity if their Layers include one of them.
+	// Defaults to DefaultLayer. These are unrelated to the RenderLayer the entity is drawn in.
+	Layers LayerMask
+	// Uniforms are the values of the uniforms of the Material the entity is drawn with, replacing the ones of the
+	// Material.
+	Uniforms Uniforms
+	// StartLayer defines the name of the initial RenderLayer. Beware that you must use `SetLayer` function to change
+	// the RenderLayer.
+	StartLayer string
 
 	magFilter, minFilter ZoomFilter
 
 	shader Shader
 	zIndex float32
+	layer  string
+
+	spriteCache spriteCache
 }
 
 // SetShader sets the shader used by the RenderComponent.
@@ -110,6 +128,8 @@ func (r *RenderComponent) ensureShader() {
 			r.shader = LegacyShader
 		case ComplexTriangles:
 			r.shader = LegacyShader
+		case vectorShape:
+			r.shader = LegacyShader
 		case Text:
 			r.shader = TextShader
 		case Blendmap:
@@ -148,6 +168,8 @@ type renderEntity struct {
 	*ecs.BasicEntity
 	*RenderComponent
 	*SpaceComponent
+
+	item *renderItem
 }
 
 type renderEntityList []renderEntity
@@ -206,12 +228,26 @@ func (r renderEntityList) Swap(i, j int) {
 // RenderSystem is the system that draws entities on the OpenGL surface. It requires
 // a CameraSystem to work. If a CameraSystem is not in the World when you add RenderSystem
 // one is automatically added to the world.
+//
+// Sprites drawn through the camera are kept in a quadtree covering the CameraBounds, so only the ones within the view
+// of a camera are considered for drawing. The vertices of sprites are kept as well, and only generated and uploaded
+// again when a sprite changes, so static sprites cost little more than a draw call per batch.
 type RenderSystem struct {
 	entities renderEntityList
 	ids      map[uint64]struct{}
 	world    *ecs.World
 
-	sortingNeeded, newCamera bool
+	index                   *tango.Quadtree
+	indexBounds             tango.AABB
+	unindexed, found, drawn []int
+
+	cameras           []*CameraSystem
+	layers            map[string]*RenderLayer
+	postProcess       postProcessor
+	targetFramebuffer *Framebuffer
+	software          softwareRenderer
+
+	sortingNeeded, sortEveryFrame, newCamera bool
 }
 
 // Priority implements the ecs.Prioritizer interface.
@@ -227,6 +263,8 @@ func (rs *RenderSystem) New(w *ecs.World) {
 	})
 
 	addCameraSystemOnce(w)
+	// Cameras added before the RenderSystem announced themselves before it was listening
+	rs.newCamera = true
 
 	if !tango.Headless() {
 		if err := initShaders(w); err != nil {
@@ -284,6 +322,8 @@ func (rs *RenderSystem) Add(basic *ecs.BasicEntity, render *RenderComponent, spa
 			render.shader = LegacyHUDShader
 		case ComplexTriangles:
 			render.shader = LegacyHUDShader
+		case vectorShape:
+			render.shader = LegacyHUDShader
 		case Text:
 			render.shader = TextHUDShader
 		default:
@@ -303,7 +343,11 @@ func (rs *RenderSystem) Add(basic *ecs.BasicEntity, render *RenderComponent, spa
 		render.zIndex = render.StartZIndex
 	}
 
-	rs.entities = append(rs.entities, renderEntity{basic, render, space})
+	if render.layer == "" {
+		render.layer = render.StartLayer
+	}
+
+	rs.entities = append(rs.entities, renderEntity{basic, render, space, &renderItem{}})
 	rs.sortingNeeded = true
 }
 
@@ -328,6 +372,9 @@ func (rs *RenderSystem) AddByInterface(i ecs.Identifier) {
 func (rs *RenderSystem) Remove(basic ecs.BasicEntity) {
 	var d = rs.EntityExists(&basic)
 	if d >= 0 {
+		if item := rs.entities[d].item; item.indexed {
+			rs.index.Remove(item)
+		}
 		rs.entities = append(rs.entities[:d], rs.entities[d+1:]...)
 		rs.sortingNeeded = true
 	}
@@ -336,35 +383,147 @@ func (rs *RenderSystem) Remove(basic ecs.BasicEntity) {
 
 // Update draws the entities in the RenderSystem to the OpenGL Surface.
 func (rs *RenderSystem) Update(dt float32) {
-	if tango.Headless() {
+	if tango.Headless() && !SoftwareRendering {
 		return
 	}
 
-	if rs.sortingNeeded {
-		sort.Sort(rs.entities)
+	if rs.sortingNeeded || rs.sortEveryFrame {
+		rs.sort()
 		rs.sortingNeeded = false
 	}
 
 	if rs.newCamera {
-		newCamera(rs.world)
+		if !tango.Headless() {
+			newCamera(rs.world)
+		}
+		rs.cameras = rs.cameras[:0]
+		for _, system := range rs.world.Systems() {
+			if cam, ok := system.(*CameraSystem); ok {
+				rs.cameras = append(rs.cameras, cam)
+			}
+		}
 		rs.newCamera = false
 	}
 
+	rs.updateIndex()
+	if tango.Headless() {
+		rs.renderSoftware()
+		return
+	}
+
+	beginShadersFrame()
+	rs.renderTargets()
+
+	passes := rs.postProcess.active()
+	if len(passes) == 0 {
+		rs.render()
+		return
+	}
+
+	rs.postProcess.begin(tango.Gl.GetViewport())
+	rs.render()
+	rs.postProcess.end(passes, dt)
+}
+
+// render draws the cameras and the HUD to the current framebuffer
+func (rs *RenderSystem) render() {
 	tango.Gl.Clear(tango.Gl.COLOR_BUFFER_BIT)
 
+	screens := 0
+	var single *CameraSystem
+	for _, cam := range rs.cameras {
+		if cam.Target == nil {
+			screens++
+			single = cam
+		}
+	}
+
+	if screens == 1 && single.fullscreen() {
+		// A single camera shares the canvas with the HUD, so they're drawn together in the order of their z-index
+		setShadersCamera(single)
+		rs.draw(single, func(e renderEntity) bool {
+			return isHUDShader(rs.shaderOf(e)) || single.renders(e.RenderComponent)
+		})
+		return
+	}
+
+	canvas := tango.Gl.GetViewport()
+	for _, cam := range rs.cameras {
+		if cam.Target != nil {
+			continue
+		}
+		tango.Gl.Viewport(viewportRect(canvas, cam.Viewport))
+		setShadersCamera(cam)
+		rs.draw(cam, func(e renderEntity) bool {
+			return !isHUDShader(rs.shaderOf(e)) && cam.renders(e.RenderComponent)
+		})
+	}
+
+	// The HUD is drawn once over the whole canvas, on top of all cameras
+	tango.Gl.Viewport(int(canvas[0]), int(canvas[1]), int(canvas[2]), int(canvas[3]))
+	if cam := mainCamera(rs.world); cam != nil {
+		setShadersCamera(cam)
+	}
+	rs.draw(nil, func(e renderEntity) bool {
+		return isHUDShader(rs.shaderOf(e))
+	})
+}
+
+// renderTargets draws the cameras with a Target to their textures
+func (rs *RenderSystem) renderTargets() {
+	rendered := false
+	for _, cam := range rs.cameras {
+		if cam.Target == nil || cam.Cached && cam.rendered {
+			continue
+		}
+		if rs.targetFramebuffer == nil {
+			rs.targetFramebuffer = CreateFramebuffer()
+		}
+
+		// The Target may have been set after the camera was added
+		cam.Target.flipped = true
+		width, height := int(cam.Target.Width()), int(cam.Target.Height())
+		rs.targetFramebuffer.Open(width, height)
+		cam.Target.Bind()
+		if !rendered {
+			tango.Gl.ClearColor(0, 0, 0, 0)
+		}
+		tango.Gl.Clear(tango.Gl.COLOR_BUFFER_BIT)
+
+		tango.Gl.Viewport(viewportRect([4]int32{0, 0, int32(width), int32(height)}, cam.Viewport))
+		setShadersCamera(cam)
+		target := cam.Target
+		rs.draw(cam, func(e renderEntity) bool {
+			// An entity drawing the texture can't be drawn to it at the same time
+			return e.Drawable != Drawable(target) && !isHUDShader(rs.shaderOf(e)) && cam.renders(e.RenderComponent)
+		})
+
+		rs.targetFramebuffer.Close()
+		cam.rendered = true
+		rendered = true
+	}
+
+	if rendered {
+		tango.Gl.ClearColor(backgroundColor[0], backgroundColor[1], backgroundColor[2], backgroundColor[3])
+	}
+}
+
+// draw draws the entities visible through the camera and passing the filter to the current viewport. Without a camera,
+// only the entities which aren't culled with the spatial index are drawn, such as those on the HUD.
+func (rs *RenderSystem) draw(cam *CameraSystem, filter func(e renderEntity) bool) {
 	preparedCullingShaders := make(map[CullingShader]struct{})
 	var cullingShader CullingShader // current culling shader
 	var prevShader Shader           // shader of the previous entity
 	var currentShader Shader        // currently "active" shader
 
-	// TODO: it's linear for now, but that might very well be a bad idea
-	for _, e := range rs.entities {
-		if e.RenderComponent.Hidden {
+	for _, i := range rs.visible(cam) {
+		e := rs.entities[i]
+		if e.RenderComponent.Hidden || rs.layerOf(e).Hidden || !filter(e) {
 			continue // with other entities
 		}
 
 		// Retrieve a shader, may be the default one -- then use it if we aren't already using it
-		shader := e.RenderComponent.shader
+		shader := rs.shaderOf(e)
 
 		if !compareShaders(shader, prevShader) {
 			// to increase performance avoid the type assertions when possible
@@ -411,11 +570,66 @@ func (rs *RenderSystem) Update(dt float32) {
 	}
 }
 
+// isHUDShader returns whether the shader draws on the HUD instead of through a camera
+func isHUDShader(s Shader) bool {
+	if m, ok := s.(*Material); ok {
+		return m.HUD
+	}
+	return compareShaders(s, HUDShader) || compareShaders(s, LegacyHUDShader) || compareShaders(s, TextHUDShader)
+}
+
+// viewportRect returns the pixels of the canvas covered by the viewport of a camera, in the x, y, width and height
+// expected by OpenGL, which has its origin at the bottom-left corner.
+func viewportRect(canvas [4]int32, viewport tango.AABB) (x, y, width, height int) {
+	if viewport == (tango.AABB{}) {
+		return int(canvas[0]), int(canvas[1]), int(canvas[2]), int(canvas[3])
+	}
+
+	left := math32.Floor(viewport.Min.X*float32(canvas[2]) + 0.5)
+	right := math32.Floor(viewport.Max.X*float32(canvas[2]) + 0.5)
+	bottom := math32.Floor((1-viewport.Max.Y)*float32(canvas[3]) + 0.5)
+	top := math32.Floor((1-viewport.Min.Y)*float32(canvas[3]) + 0.5)
+	return int(canvas[0]) + int(left), int(canvas[1]) + int(bottom), int(right - left), int(top - bottom)
+}
+
+// viewportSize returns the size, in game units, of the area the camera renders to. Without a camera, it's the size of
+// the whole canvas. A camera with a Target renders one game unit to one pixel of the texture.
+func viewportSize(cam *CameraSystem) (width, height float32) {
+	if tango.ScaleOnResize() {
+		width, height = tango.GameWidth(), tango.GameHeight()
+	} else {
+		width, height = tango.CanvasWidth()/tango.CanvasScale(), tango.CanvasHeight()/tango.CanvasScale()
+	}
+
+	if cam != nil && cam.Target != nil {
+		width, height = cam.Target.Width(), cam.Target.Height()
+	}
+
+	if cam != nil && cam.Viewport != (tango.AABB{}) {
+		width *= cam.Viewport.Max.X - cam.Viewport.Min.X
+		height *= cam.Viewport.Max.Y - cam.Viewport.Min.Y
+	}
+	return width, height
+}
+
+// projectionSize returns the size, in game units, of the area a shader projects to. This is the viewport of the camera,
+// or, for shaders which don't use the camera such as the HUDShader, the whole canvas.
+func projectionSize(cam *CameraSystem, cameraEnabled bool) (width, height float32) {
+	if !cameraEnabled {
+		return viewportSize(nil)
+	}
+	return viewportSize(cam)
+}
+
+// backgroundColor is the ClearColor set by SetBackground
+var backgroundColor [4]float32
+
 // SetBackground sets the OpenGL ClearColor to the provided color.
 func SetBackground(c color.Color) {
-	if !tango.Headless() {
-		r, g, b, a := c.RGBA()
+	r, g, b, a := c.RGBA()
+	backgroundColor = [4]float32{float32(r) / 0xffff, float32(g) / 0xffff, float32(b) / 0xffff, float32(a) / 0xffff}
 
-		tango.Gl.ClearColor(float32(r)/0xffff, float32(g)/0xffff, float32(b)/0xffff, float32(a)/0xffff)
+	if !tango.Headless() {
+		tango.Gl.ClearColor(backgroundColor[0], backgroundColor[1], backgroundColor[2], backgroundColor[3])
 	}
 }
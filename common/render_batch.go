@@ -0,0 +1,135 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+)
+
+// maxSpriteBatches is the number of batches a shader keeps across frames. If more sprites are drawn within a frame,
+// the batches are reused from the first one, which only costs uploading them again.
+const maxSpriteBatches = 64
+
+// batchContext is the part of the OpenGL context the basicShader draws its batches with. It's tango.Gl, except in the
+// benchmarks, which run without OpenGL.
+type batchContext interface {
+	CreateBuffer() *gl.Buffer
+	BindBuffer(target int, buffer *gl.Buffer)
+	BufferData(target int, data interface{}, usage int)
+	BufferSubData(target int, offset int, data interface{})
+	BindTexture(target int, texture *gl.Texture)
+	TexParameteri(target int, pname int, param int)
+	UseProgram(program *gl.Program)
+	UniformMatrix3fv(location *gl.UniformLocation, transpose bool, value []float32)
+	EnableVertexAttribArray(index int)
+	DisableVertexAttribArray(index int)
+	VertexAttribPointer(index, size, typ int, normal bool, stride int, offset int)
+	Enable(flag int)
+	Disable(flag int)
+	Hint(target, mode int)
+	BlendFunc(src, dst int)
+	DrawElements(mode, count, typ, offset int)
+}
+
+// batchingShader is implemented by the shaders which keep their batches across frames
+type batchingShader interface {
+	// beginFrame is called at the start of every frame
+	beginFrame()
+}
+
+// spriteBatch is a batch of up to BatchSize sprites in a vertex buffer of its own. The buffers are kept across frames:
+// the n-th batch drawn by a shader during a frame is the one it drew n-th during the previous frame. The vertices of
+// a batch are only uploaded where they changed, so the batches of static scenes aren't uploaded at all.
+type spriteBatch struct {
+	buffer   *gl.Buffer
+	vertices []float32
+	// uploaded is the number of vertices, in floats, the buffer holds
+	uploaded int
+}
+
+// spriteKey is everything the vertices of a sprite drawn with a single quad are generated from
+type spriteKey struct {
+	position                tango.Point
+	width, height           float32
+	rotation                float32
+	scale, globalScale      tango.Point
+	drawnWidth, drawnHeight float32
+	view                    [4]float32
+	repeat                  TextureRepeating
+	tint                    float32
+}
+
+// spriteCache keeps the vertices of a sprite in its RenderComponent, along with what they were generated from
+type spriteCache struct {
+	valid    bool
+	key      spriteKey
+	vertices [spriteSize]float32
+}
+
+func (s *basicShader) beginFrame() {
+	if len(s.batches) > 0 {
+		s.useBatch(0)
+	}
+}
+
+// useBatch makes the sprites be added to the batch with the given index, creating its vertex buffer if needed.
+func (s *basicShader) useBatch(index int) {
+	if index >= maxSpriteBatches {
+		index = 0
+	}
+	for len(s.batches) <= index {
+		batch := &spriteBatch{buffer: s.context.CreateBuffer(), vertices: make([]float32, s.BatchSize*spriteSize)}
+		s.context.BindBuffer(tango.Gl.ARRAY_BUFFER, batch.buffer)
+		s.context.BufferData(tango.Gl.ARRAY_BUFFER, batch.vertices, tango.Gl.DYNAMIC_DRAW)
+		s.batches = append(s.batches, batch)
+	}
+
+	s.batch = index
+	s.vertices = s.batches[index].vertices
+	s.vertexBuffer = s.batches[index].buffer
+	s.idx, s.start = 0, 0
+	s.changed = false
+}
+
+// add adds the vertices to the batch, noting whether they differ from the ones there.
+func (s *basicShader) add(vertices []float32) {
+	batch := s.vertices[s.idx : s.idx+len(vertices)]
+	for i, v := range vertices {
+		if batch[i] != v {
+			batch[i] = v
+			s.changed = true
+		}
+	}
+	s.idx += len(vertices)
+}
+
+// dirty returns whether the vertices added since the last flush have to be uploaded.
+func (s *basicShader) dirty() bool {
+	return s.changed || s.idx > s.batches[s.batch].uploaded
+}
+
+// cachedVertices returns the vertices of a sprite drawn with a single quad. They're kept in its RenderComponent and
+// only generated again when anything they're generated from changed, so the vertices of static sprites are merely
+// copied into the batch.
+func (s *basicShader) cachedVertices(ren *RenderComponent, space *SpaceComponent) []float32 {
+	u, v, u2, v2 := ren.Drawable.View()
+	key := spriteKey{
+		position:    space.Position,
+		width:       space.Width,
+		height:      space.Height,
+		rotation:    space.Rotation,
+		scale:       ren.Scale,
+		globalScale: tango.GetGlobalScale(),
+		drawnWidth:  ren.Drawable.Width(),
+		drawnHeight: ren.Drawable.Height(),
+		view:        [4]float32{u, v, u2, v2},
+		repeat:      ren.Repeat,
+		tint:        colorToFloat32(ren.Color),
+	}
+
+	cache := &ren.spriteCache
+	if !cache.valid || cache.key != key {
+		s.generateBufferContent(ren, space, cache.vertices[:])
+		cache.key, cache.valid = key, true
+	}
+	return cache.vertices[:]
+}
@@ -0,0 +1,226 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpriteCache(t *testing.T) {
+	tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+	s := &basicShader{modelMatrix: tango.IdentityMatrix()}
+	ren := &RenderComponent{
+		Drawable: Texture{width: 4, height: 2, viewport: tango.AABB{Max: tango.Point{X: 1, Y: 1}}},
+		Scale:    tango.Point{X: 1, Y: 1},
+		Color:    color.White,
+	}
+	space := &SpaceComponent{Position: tango.Point{X: 10, Y: 20}, Width: 4, Height: 2}
+
+	expected := make([]float32, spriteSize)
+	s.generateBufferContent(ren, space, expected)
+	assert.Equal(t, expected, s.cachedVertices(ren, space))
+	assert.True(t, ren.spriteCache.valid)
+
+	ren.spriteCache.vertices[0] = -1
+	assert.Equal(t, float32(-1), s.cachedVertices(ren, space)[0], "The vertices of an unchanged sprite shouldn't be generated again")
+
+	space.Position.X = 30
+	assert.Equal(t, float32(30), s.cachedVertices(ren, space)[0], "The vertices should be generated again when the sprite moves")
+	ren.Color = color.Black
+	assert.Equal(t, colorToFloat32(color.Black), s.cachedVertices(ren, space)[4], "The vertices should be generated again when the sprite changes color")
+	tango.SetGlobalScale(tango.Point{X: 2, Y: 2})
+	assert.Equal(t, float32(60), s.cachedVertices(ren, space)[0], "The vertices should be generated again when the GlobalScale changes")
+	tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+}
+
+func TestSpriteBatchUploads(t *testing.T) {
+	batch := &spriteBatch{vertices: make([]float32, 2*spriteSize)}
+	s := &basicShader{batches: []*spriteBatch{batch}, vertices: batch.vertices}
+	quad := make([]float32, spriteSize)
+	for i := range quad {
+		quad[i] = float32(i + 1)
+	}
+
+	s.add(quad)
+	assert.Equal(t, quad, batch.vertices[:spriteSize])
+	assert.True(t, s.dirty(), "New vertices should be uploaded")
+	// The first sprite is uploaded and drawn
+	batch.uploaded, s.start, s.changed = s.idx, s.idx, false
+
+	s.add(quad)
+	assert.True(t, s.dirty(), "Vertices past the ones uploaded should be uploaded, even if they're the same")
+	batch.uploaded, s.start, s.changed = s.idx, s.idx, false
+
+	// The next frame draws the same sprites
+	s.idx, s.start = 0, 0
+	s.add(quad)
+	assert.False(t, s.dirty(), "Vertices which didn't change shouldn't be uploaded again")
+	s.start = s.idx
+	quad[3] = 0
+	s.add(quad)
+	assert.True(t, s.dirty(), "Vertices which changed should be uploaded again")
+}
+
+// benchmarkSprites is the number of sprites of the benchmarks of the RenderSystem. The benchmarks only measure the
+// work done on the CPU, so a frame taking less than the 16.6ms of a frame at 60 frames per second doesn't mean that
+// the GPU draws that many sprites as fast.
+const benchmarkSprites = 50000
+
+// newBenchmarkRenderSystem returns a RenderSystem with n sprites of 16x16 in a grid 250 sprites wide, and its camera,
+// which sees about a twentieth of a square grid.
+func newBenchmarkRenderSystem(n int) (*RenderSystem, *CameraSystem, []*SpaceComponent) {
+	_, rs := newSoftwareTestWorld()
+	side := float32(16 * 250)
+	CameraBounds = tango.AABB{Max: tango.Point{X: side, Y: side}}
+
+	texture := Texture{width: 16, height: 16, viewport: tango.AABB{Max: tango.Point{X: 1, Y: 1}}}
+	spaces := make([]*SpaceComponent, n)
+	for i := range spaces {
+		basic := ecs.NewBasic()
+		spaces[i] = &SpaceComponent{Position: tango.Point{X: float32(i%250) * 16, Y: float32(i/250) * 16}, Width: 16, Height: 16}
+		rs.Add(&basic, &RenderComponent{Drawable: texture, Color: color.White, Scale: tango.Point{X: 1, Y: 1}}, spaces[i])
+	}
+	rs.sort()
+
+	cam := mainCamera(rs.world)
+	cam.x, cam.y, cam.z = side/2, side/2, 6
+	return rs, cam, spaces
+}
+
+// benchmarkContext is the batchContext of the benchmarks. It doesn't call OpenGL, but counts the sprites drawn and
+// the floats uploaded.
+type benchmarkContext struct {
+	drawn, uploaded int
+}
+
+func (*benchmarkContext) CreateBuffer() *gl.Buffer                              { return &gl.Buffer{} }
+func (*benchmarkContext) BindBuffer(int, *gl.Buffer)                            {}
+func (*benchmarkContext) BufferData(int, interface{}, int)                      {}
+func (*benchmarkContext) BindTexture(int, *gl.Texture)                          {}
+func (*benchmarkContext) TexParameteri(int, int, int)                           {}
+func (*benchmarkContext) UseProgram(*gl.Program)                                {}
+func (*benchmarkContext) UniformMatrix3fv(*gl.UniformLocation, bool, []float32) {}
+func (*benchmarkContext) EnableVertexAttribArray(int)                           {}
+func (*benchmarkContext) DisableVertexAttribArray(int)                          {}
+func (*benchmarkContext) VertexAttribPointer(int, int, int, bool, int, int)     {}
+func (*benchmarkContext) Enable(int)                                            {}
+func (*benchmarkContext) Disable(int)                                           {}
+func (*benchmarkContext) Hint(int, int)                                         {}
+func (*benchmarkContext) BlendFunc(int, int)                                    {}
+func (c *benchmarkContext) BufferSubData(target int, offset int, data interface{}) {
+	c.uploaded += len(data.([]float32))
+}
+func (c *benchmarkContext) DrawElements(mode, count, typ, offset int) { c.drawn += count / 6 }
+
+// stubGL replaces tango.Gl, whose constants the shaders use, by one which can't call OpenGL, until the returned
+// function is called.
+func stubGL() func() {
+	old := tango.Gl
+	tango.Gl = &gl.Context{}
+	return func() { tango.Gl = old }
+}
+
+// newBenchmarkShader returns a DefaultShader drawing with a benchmarkContext, which all entities of the RenderSystem
+// are drawn with. tango.Gl has to be stubbed.
+func newBenchmarkShader(rs *RenderSystem, cam *CameraSystem) *basicShader {
+	s := &basicShader{
+		BatchSize:        MaxSprites,
+		context:          &benchmarkContext{},
+		cameraEnabled:    true,
+		projectionMatrix: tango.IdentityMatrix(),
+		viewMatrix:       tango.IdentityMatrix(),
+		projViewMatrix:   tango.IdentityMatrix(),
+		modelMatrix:      tango.IdentityMatrix(),
+		cullingMatrix:    tango.IdentityMatrix(),
+	}
+	s.setTexture(nil)
+	s.useBatch(0)
+	s.SetCamera(cam)
+	for _, e := range rs.entities {
+		e.RenderComponent.shader = s
+	}
+	return s
+}
+
+// benchmarkFrame draws a frame through the camera the way RenderSystem.Update does when there is a single camera and
+// no post-processing: the spatial index is updated, the batches of the shader are started again and the entities are
+// drawn with RenderSystem.draw, which culls them and calls Pre, Draw and Post of the shader. It returns the number of
+// sprites drawn and of floats uploaded.
+//
+// The shader calls the benchmarkContext instead of OpenGL, so neither the time spent by the OpenGL driver nor the time
+// the GPU takes to draw the sprites is measured.
+func benchmarkFrame(rs *RenderSystem, cam *CameraSystem, s *basicShader) (drawn, uploaded int) {
+	context := s.context.(*benchmarkContext)
+	*context = benchmarkContext{}
+
+	rs.updateIndex()
+	s.beginFrame()
+	rs.draw(cam, func(renderEntity) bool { return true })
+	return context.drawn, context.uploaded
+}
+
+func TestSpriteBatchFrames(t *testing.T) {
+	defer stubGL()()
+	rs, cam, spaces := newBenchmarkRenderSystem(MaxSprites + 100)
+	cam.z = 40
+	s := newBenchmarkShader(rs, cam)
+
+	drawn, uploaded := benchmarkFrame(rs, cam, s)
+	assert.Equal(t, len(spaces), drawn)
+	assert.Equal(t, len(spaces)*spriteSize, uploaded, "All sprites should be uploaded during the first frame")
+	assert.Equal(t, 1, s.batch, "The sprites which don't fit in the first batch should be added to the next one")
+
+	_, uploaded = benchmarkFrame(rs, cam, s)
+	assert.Equal(t, 0, uploaded, "Nothing should be uploaded when nothing changed")
+
+	spaces[len(spaces)-1].Position.X++
+	_, uploaded = benchmarkFrame(rs, cam, s)
+	assert.Equal(t, 100*spriteSize, uploaded, "Only the batch of the sprite which moved should be uploaded")
+}
+
+// BenchmarkRenderSystemFrame measures the work of the RenderSystem on the CPU for a frame of benchmarkSprites sprites,
+// as described by benchmarkFrame. It doesn't include the time spent in OpenGL, nor drawing on the GPU.
+func BenchmarkRenderSystemFrame(b *testing.B) {
+	defer stubGL()()
+
+	run := func(b *testing.B, rs *RenderSystem, cam *CameraSystem, frame func(i int)) {
+		s := newBenchmarkShader(rs, cam)
+		benchmarkFrame(rs, cam, s)
+		total := 0
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			frame(i)
+			_, uploaded := benchmarkFrame(rs, cam, s)
+			total += uploaded
+		}
+		b.ReportMetric(float64(total)/float64(b.N), "floats-uploaded/op")
+	}
+
+	b.Run("Static", func(b *testing.B) {
+		rs, cam, _ := newBenchmarkRenderSystem(benchmarkSprites)
+		run(b, rs, cam, func(int) {})
+	})
+
+	b.Run("Moving", func(b *testing.B) {
+		// A tenth of the sprites move in every frame
+		rs, cam, spaces := newBenchmarkRenderSystem(benchmarkSprites)
+		run(b, rs, cam, func(i int) {
+			for j := i % 10; j < len(spaces); j += 10 {
+				spaces[j].Position.X += 0.5
+			}
+		})
+	})
+
+	b.Run("AllVisible", func(b *testing.B) {
+		rs, cam, _ := newBenchmarkRenderSystem(benchmarkSprites)
+		cam.z = 40
+		if drawn, _ := benchmarkFrame(rs, cam, newBenchmarkShader(rs, cam)); drawn != benchmarkSprites {
+			b.Fatalf("All %d sprites should be visible, but %d are", benchmarkSprites, drawn)
+		}
+		run(b, rs, cam, func(int) {})
+	})
+}
@@ -0,0 +1,159 @@
+package common
+
+import (
+	"sort"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// renderIndexMaxObjects is the number of entities a node of the spatial index of the RenderSystem holds before it's
+// split
+const renderIndexMaxObjects = 16
+
+// renderItem is an entity of the RenderSystem in its spatial index
+type renderItem struct {
+	// index is the index of the entity in the sorted entities of the RenderSystem
+	index int
+	// area is the area the entity was drawn in when it was indexed, and bounds its bounding box
+	area    SpaceComponent
+	bounds  tango.AABB
+	indexed bool
+}
+
+// AABB implements the tango.AABBer interface.
+func (i *renderItem) AABB() tango.AABB {
+	return i.bounds
+}
+
+// spriteArea returns the area the basicShader draws a sprite drawn with quads in, in world coordinates.
+func spriteArea(rc *RenderComponent, sc *SpaceComponent) SpaceComponent {
+	if isMultiQuad(rc) {
+		// NineSlices and repeated regions are drawn at the size of their SpaceComponent
+		return SpaceComponent{Position: sc.Position, Width: sc.Width, Height: sc.Height, Rotation: sc.Rotation}
+	}
+	return SpaceComponent{
+		Position: sc.Position,
+		Width:    rc.Drawable.Width() * rc.Scale.X,
+		Height:   rc.Drawable.Height() * rc.Scale.Y,
+		Rotation: sc.Rotation,
+	}
+}
+
+func sameArea(a, b SpaceComponent) bool {
+	return a.Position == b.Position && a.Width == b.Width && a.Height == b.Height && a.Rotation == b.Rotation
+}
+
+// areaBounds returns the bounding box of the area, which may have a negative size
+func areaBounds(area SpaceComponent) tango.AABB {
+	corners := area.Corners()
+	bounds := tango.AABB{Min: corners[0], Max: corners[0]}
+	for _, c := range corners[1:] {
+		bounds.Min.X, bounds.Max.X = math32.Min(bounds.Min.X, c.X), math32.Max(bounds.Max.X, c.X)
+		bounds.Min.Y, bounds.Max.Y = math32.Min(bounds.Min.Y, c.Y), math32.Max(bounds.Max.Y, c.Y)
+	}
+	return bounds
+}
+
+// indexBounds returns the bounds of the spatial index, which are the CameraBounds. Entities outside of them are still
+// indexed, but less efficiently.
+func indexBounds() tango.AABB {
+	bounds := CameraBounds
+	if bounds.Max.X <= bounds.Min.X || bounds.Max.Y <= bounds.Min.Y {
+		bounds.Max = tango.Point{X: bounds.Min.X + tango.GameWidth(), Y: bounds.Min.Y + tango.GameHeight()}
+	}
+	if bounds.Max.X <= bounds.Min.X || bounds.Max.Y <= bounds.Min.Y {
+		bounds.Max = tango.Point{X: bounds.Min.X + 1, Y: bounds.Min.Y + 1}
+	}
+	return bounds
+}
+
+// indexable returns whether the entity is culled with the spatial index, which is the case for the sprites drawn
+// through the camera by the DefaultShader or a Material. Particles are drawn wherever they went instead of at their
+// SpaceComponent, and other shaders are drawn in every frame or do their own culling.
+func (rs *RenderSystem) indexable(e renderEntity) bool {
+	if e.RenderComponent.Drawable == nil {
+		return false
+	}
+	if _, ok := e.RenderComponent.Drawable.(Particles); ok {
+		return false
+	}
+	switch s := rs.shaderOf(e).(type) {
+	case *basicShader:
+		return s.cameraEnabled
+	case *Material:
+		return !s.HUD
+	}
+	return false
+}
+
+// updateIndex moves the entities which changed since the last frame within the spatial index, and lists the ones which
+// aren't in it. This is the only work done for every entity in every frame, regardless of how many are visible.
+func (rs *RenderSystem) updateIndex() {
+	if bounds := indexBounds(); rs.index == nil || rs.indexBounds != bounds {
+		rs.index = tango.NewQuadtree(bounds, false, renderIndexMaxObjects)
+		rs.indexBounds = bounds
+		for _, e := range rs.entities {
+			e.item.indexed = false
+		}
+	}
+
+	rs.unindexed = rs.unindexed[:0]
+	for i, e := range rs.entities {
+		item := e.item
+		item.index = i
+		if !rs.indexable(e) {
+			if item.indexed {
+				rs.index.Remove(item)
+				item.indexed = false
+			}
+			rs.unindexed = append(rs.unindexed, i)
+			continue
+		}
+
+		area := spriteArea(e.RenderComponent, e.SpaceComponent)
+		if item.indexed && sameArea(item.area, area) {
+			continue
+		}
+		item.area = area
+		item.bounds = areaBounds(area)
+		if item.indexed {
+			rs.index.Update(item)
+		} else {
+			rs.index.Insert(item)
+			item.indexed = true
+		}
+	}
+}
+
+// visible returns the indices of the entities which may be visible through the camera, in the order they're drawn.
+// These are the entities of the spatial index within the VisibleBounds of the camera, and all entities which aren't
+// in it. Without a camera, it's only the latter.
+func (rs *RenderSystem) visible(cam *CameraSystem) []int {
+	if cam == nil || rs.index == nil {
+		return rs.unindexed
+	}
+
+	rs.found = rs.found[:0]
+	rs.index.Retrieve(cam.VisibleBounds(), func(item tango.AABBer) bool {
+		rs.found = append(rs.found, item.(*renderItem).index)
+		return false
+	})
+	sort.Ints(rs.found)
+
+	// Both lists are sorted, so they're merged in order
+	rs.drawn = rs.drawn[:0]
+	unindexed, found := rs.unindexed, rs.found
+	for len(unindexed) > 0 && len(found) > 0 {
+		if unindexed[0] < found[0] {
+			rs.drawn = append(rs.drawn, unindexed[0])
+			unindexed = unindexed[1:]
+		} else {
+			rs.drawn = append(rs.drawn, found[0])
+			found = found[1:]
+		}
+	}
+	rs.drawn = append(rs.drawn, unindexed...)
+	rs.drawn = append(rs.drawn, found...)
+	return rs.drawn
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderSystemCulling(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+	CameraBounds = tango.AABB{Max: tango.Point{X: 1000, Y: 1000}}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	sprite := NewTextureSingle(NewImageObject(img))
+	defer sprite.Close()
+	var entities []*ecs.BasicEntity
+	add := func(d Drawable, x, y float32) *SpaceComponent {
+		basic := ecs.NewBasic()
+		space := &SpaceComponent{Position: tango.Point{X: x, Y: y}, Width: 10, Height: 10}
+		rs.Add(&basic, &RenderComponent{Drawable: d, Color: color.White}, space)
+		entities = append(entities, &basic)
+		return space
+	}
+	// drawn returns which of the entities are drawn
+	drawn := func(cam *CameraSystem) []int {
+		var indices []int
+		visible := rs.visible(cam)
+		assert.True(t, sort.IntsAreSorted(visible), "The entities should be drawn in order")
+		for _, i := range visible {
+			for j, basic := range entities {
+				if rs.entities[i].ID() == basic.ID() {
+					indices = append(indices, j)
+				}
+			}
+		}
+		return indices
+	}
+	add(sprite, 10, 10)
+	outside := add(sprite, 600, 600)
+	add(Rectangle{}, 600, 600)
+	add(sprite, 700, 10)
+	// The bounding box of the rotated sprite reaches into the view
+	rotated := add(sprite, 204, 10)
+	rotated.Rotation = 90
+
+	cam := mainCamera(w)
+	w.Update(1)
+	assert.ElementsMatch(t, []int{0, 2, 4}, drawn(cam), "Only the visible sprites and the shape which isn't culled should be drawn")
+	assert.Equal(t, []int{2}, drawn(nil), "Without a camera, only the entities which aren't culled should be drawn")
+	assert.Equal(t, 4, rs.index.Total)
+
+	outside.Position = tango.Point{X: 50, Y: 50}
+	w.Update(1)
+	assert.ElementsMatch(t, []int{0, 1, 2, 4}, drawn(cam), "The sprite should be drawn once it moved into view")
+	assertSoftwarePixel(t, color.RGBA{254, 254, 254, 255}, 55, 55, "The sprite which moved should be drawn")
+
+	rs.Remove(*entities[3])
+	w.Update(1)
+	assert.Equal(t, 3, rs.index.Total, "Removed entities should be removed from the index")
+	assert.ElementsMatch(t, []int{0, 1, 2, 4}, drawn(cam))
+}
+
+func BenchmarkRenderSystemCulling(b *testing.B) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+
+	b.Run("Quadtree", func(b *testing.B) {
+		rs, cam, _ := newBenchmarkRenderSystem(benchmarkSprites)
+		rs.updateIndex()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rs.updateIndex()
+			rs.visible(cam)
+		}
+	})
+
+	b.Run("Linear", func(b *testing.B) {
+		// Every entity is culled by the shader, as was done before the index
+		defer stubGL()()
+		rs, cam, _ := newBenchmarkRenderSystem(benchmarkSprites)
+		s := newBenchmarkShader(rs, cam)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.PrepareCulling()
+			for _, e := range rs.entities {
+				s.ShouldDraw(e.RenderComponent, e.SpaceComponent)
+			}
+		}
+	})
+}
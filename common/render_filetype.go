@@ -13,16 +13,11 @@ import (
 	_ "image/gif"
 	"io"
 
-	// these are for svg support
-
-	"github.com/srwiley/oksvg"
-	"github.com/srwiley/rasterx"
-
 	"github.com/inkeliz-technologies/tango"
 	"github.com/inkeliz-technologies/tango/gl"
 )
 
-// TextureResource is the resource used by the RenderSystem. It uses .jpg, .gif, and .png images
+// TextureResource is the resource used by the RenderSystem. It uses .jpg, .gif, .png and .svg images
 type TextureResource struct {
 	Texture *gl.Texture
 	Width   float32
@@ -40,30 +35,14 @@ type imageLoader struct {
 }
 
 func (i *imageLoader) Load(url string, data io.Reader) error {
-	if getExt(url) == ".svg" {
-		icon, err := oksvg.ReadIconStream(data, oksvg.WarnErrorMode)
-		if err != nil {
-			return err
-		}
-		w, h := int(icon.ViewBox.W), int(icon.ViewBox.H)
-		img := image.NewRGBA(image.Rect(0, 0, w, h))
-		gv := rasterx.NewScannerGV(w, h, img, img.Bounds())
-		r := rasterx.NewDasher(w, h, gv)
-		icon.Draw(r, 1.0)
-		b := img.Bounds()
-		newm := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
-		draw.Draw(newm, newm.Bounds(), img, b.Min, draw.Src)
-		i.images[url] = NewTextureResource(&ImageObject{newm})
-	} else {
-		img, _, err := image.Decode(data)
-		if err != nil {
-			return err
-		}
-		b := img.Bounds()
-		newm := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
-		draw.Draw(newm, newm.Bounds(), img, b.Min, draw.Src)
-		i.images[url] = NewTextureResource(&ImageObject{newm})
+	img, _, err := image.Decode(data)
+	if err != nil {
+		return err
 	}
+	b := img.Bounds()
+	newm := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(newm, newm.Bounds(), img, b.Min, draw.Src)
+	i.images[url] = NewTextureResource(&ImageObject{newm})
 
 	return nil
 }
@@ -93,6 +72,9 @@ type Image interface {
 // UploadTexture sends the image to the GPU, to be kept in GPU RAM
 func UploadTexture(img Image) (id *gl.Texture) {
 	if tango.Headless() {
+		if SoftwareRendering {
+			id = uploadSoftwareTexture(img)
+		}
 		return id
 	}
 
@@ -238,6 +220,7 @@ func (t Texture) View() (float32, float32, float32, float32) {
 // Close removes the Texture data from the GPU.
 func (t Texture) Close() {
 	if tango.Headless() {
+		delete(softwareTextures, t.id)
 		return
 	}
 
@@ -248,5 +231,5 @@ func init() {
 	tango.Files.Register(".jpg", &imageLoader{images: make(map[string]TextureResource)})
 	tango.Files.Register(".png", &imageLoader{images: make(map[string]TextureResource)})
 	tango.Files.Register(".gif", &imageLoader{images: make(map[string]TextureResource)})
-	tango.Files.Register(".svg", &imageLoader{images: make(map[string]TextureResource)})
+	tango.Files.Register(".svg", svgs)
 }
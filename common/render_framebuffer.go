@@ -20,6 +20,9 @@ type RenderTexture struct {
 	tex           *gl.Texture
 	width, height float32
 	depth         bool
+	// flipped flips the view vertically. OpenGL stores the rows of what's rendered to the texture from the bottom up,
+	// so the textures the RenderSystem renders to, such as the Targets of cameras, are flipped to be drawn upright.
+	flipped bool
 }
 
 func CreateRenderBuffer(width, height int) *RenderBuffer {
@@ -91,8 +94,12 @@ func (t *RenderTexture) Texture() *gl.Texture {
 	return t.tex
 }
 
-// View returns the viewport properties of the Texture. The order is Min.X, Min.Y, Max.X, Max.Y.
+// View returns the viewport properties of the Texture. The order is Min.X, Min.Y, Max.X, Max.Y. The view of the
+// Target of a camera is flipped vertically, so it's drawn upright.
 func (t *RenderTexture) View() (float32, float32, float32, float32) {
+	if t.flipped {
+		return 0, 1, 1, 0
+	}
 	return 0, 0, 1, 1
 }
 
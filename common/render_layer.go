@@ -0,0 +1,168 @@
+package common
+
+import (
+	"sort"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+)
+
+// SortMode is the order in which the entities of a RenderLayer are drawn.
+type SortMode uint8
+
+const (
+	// SortZIndex draws entities with a lower z-index first. Entities with the same z-index are grouped by shader and
+	// texture, so they can be batched together. This is the default.
+	SortZIndex SortMode = iota
+	// SortY draws entities with a lower bottom edge first, so entities lower on the screen are drawn on top of those
+	// behind them, as in top-down games. Entities with the same bottom edge are sorted by z-index.
+	SortY
+	// SortCustom sorts entities using RenderLayer.Less.
+	SortCustom
+)
+
+// RenderLayerEntity is an entity of a RenderLayer, as given to RenderLayer.Less.
+type RenderLayerEntity struct {
+	*ecs.BasicEntity
+	*RenderComponent
+	*SpaceComponent
+}
+
+// RenderLayer is a named group of entities, such as "ground", "actors" or "ui", which are drawn together. Entities
+// are put on a layer with RenderComponent.SetLayer. Entities without a layer, or with a layer which wasn't added to
+// the RenderSystem, are on the default layer, which has an empty Name and can be configured like any other layer.
+type RenderLayer struct {
+	Name string
+	// Order is the order in which layers are drawn. Layers with a lower Order are drawn first, below the others.
+	Order int
+	// Sort is the order in which the entities within the layer are drawn.
+	Sort SortMode
+	// Less reports whether entity a should be drawn before entity b, if Sort is SortCustom.
+	Less func(a, b RenderLayerEntity) bool
+	// Hidden prevents drawing all entities of the layer.
+	Hidden bool
+	// CameraDisabled draws the layer on the screen instead of through the camera, such as for a HUD. This replaces
+	// the DefaultShader, LegacyShader and TextShader by their HUD counterparts for the entities of the layer; other
+	// shaders are used as they are.
+	CameraDisabled bool
+}
+
+// defaultRenderLayer is used for entities without a layer, until the default layer is added to the RenderSystem
+var defaultRenderLayer = &RenderLayer{}
+
+// SetLayer puts the entity on the RenderLayer with the given name.
+func (r *RenderComponent) SetLayer(name string) {
+	r.layer = name
+	tango.Mailbox.Dispatch(&renderChangeMessage{})
+}
+
+// Layer returns the name of the RenderLayer the entity is on.
+func (r *RenderComponent) Layer() string {
+	return r.layer
+}
+
+// AddLayer adds the layer to the RenderSystem, replacing any layer with the same name. Changing the Order, Sort or
+// Less of a layer requires adding it again, while Hidden and CameraDisabled can be changed at any time.
+func (rs *RenderSystem) AddLayer(layer *RenderLayer) {
+	if rs.layers == nil {
+		rs.layers = make(map[string]*RenderLayer)
+	}
+	rs.layers[layer.Name] = layer
+	rs.layersChanged()
+}
+
+// RemoveLayer removes the layer with the given name from the RenderSystem. Its entities move to the default layer.
+func (rs *RenderSystem) RemoveLayer(name string) {
+	if _, ok := rs.layers[name]; !ok {
+		return
+	}
+	delete(rs.layers, name)
+	rs.layersChanged()
+}
+
+func (rs *RenderSystem) layersChanged() {
+	rs.sortEveryFrame = false
+	for _, l := range rs.layers {
+		// The positions of the entities change every frame
+		if l.Sort != SortZIndex {
+			rs.sortEveryFrame = true
+		}
+	}
+	rs.sortingNeeded = true
+}
+
+// Layer returns the layer with the given name, or the default layer if the RenderSystem has no such layer.
+func (rs *RenderSystem) Layer(name string) *RenderLayer {
+	if layer, ok := rs.layers[name]; ok {
+		return layer
+	}
+	if layer, ok := rs.layers[""]; ok {
+		return layer
+	}
+	return defaultRenderLayer
+}
+
+// layerOf returns the layer of the entity
+func (rs *RenderSystem) layerOf(e renderEntity) *RenderLayer {
+	if len(rs.layers) == 0 {
+		return defaultRenderLayer
+	}
+	return rs.Layer(e.RenderComponent.layer)
+}
+
+// shaderOf returns the shader the entity is drawn with, taking the CameraDisabled setting of its layer into account
+func (rs *RenderSystem) shaderOf(e renderEntity) Shader {
+	shader := e.RenderComponent.shader
+	if !rs.layerOf(e).CameraDisabled {
+		return shader
+	}
+
+	switch {
+	case compareShaders(shader, DefaultShader):
+		return HUDShader
+	case compareShaders(shader, LegacyShader):
+		return LegacyHUDShader
+	case compareShaders(shader, TextShader):
+		return TextHUDShader
+	}
+	return shader
+}
+
+// sort orders the entities by layer, and within each layer by its SortMode
+func (rs *RenderSystem) sort() {
+	if len(rs.layers) == 0 {
+		sort.Sort(rs.entities)
+		return
+	}
+	sort.Sort(layeredRenderEntityList{rs.entities, rs})
+}
+
+type layeredRenderEntityList struct {
+	renderEntityList
+	rs *RenderSystem
+}
+
+func (r layeredRenderEntityList) Less(i, j int) bool {
+	a, b := r.renderEntityList[i], r.renderEntityList[j]
+	la, lb := r.rs.layerOf(a), r.rs.layerOf(b)
+	if la != lb {
+		if la.Order != lb.Order {
+			return la.Order < lb.Order
+		}
+		return la.Name < lb.Name
+	}
+
+	switch la.Sort {
+	case SortY:
+		ya, yb := a.Position.Y+a.Height, b.Position.Y+b.Height
+		if ya != yb {
+			return ya < yb
+		}
+	case SortCustom:
+		if la.Less != nil {
+			return la.Less(RenderLayerEntity{a.BasicEntity, a.RenderComponent, a.SpaceComponent},
+				RenderLayerEntity{b.BasicEntity, b.RenderComponent, b.SpaceComponent})
+		}
+	}
+	return r.renderEntityList.Less(i, j)
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRenderLayerTestSystem() *RenderSystem {
+	tango.Mailbox = &tango.MessageManager{}
+	return &RenderSystem{ids: make(map[uint64]struct{})}
+}
+
+func addRenderLayerTestEntity(rs *RenderSystem, layer string, z, y float32) *ecs.BasicEntity {
+	basic := ecs.NewBasic()
+	render := &RenderComponent{Drawable: Rectangle{}, StartLayer: layer, StartZIndex: z}
+	rs.Add(&basic, render, &SpaceComponent{Position: tango.Point{Y: y}, Height: 10})
+	return &basic
+}
+
+func renderLayerTestOrder(rs *RenderSystem) []uint64 {
+	rs.sort()
+	var ids []uint64
+	for _, e := range rs.entities {
+		ids = append(ids, e.ID())
+	}
+	return ids
+}
+
+func TestRenderLayerOrder(t *testing.T) {
+	rs := newRenderLayerTestSystem()
+	rs.AddLayer(&RenderLayer{Name: "ui", Order: 10})
+	rs.AddLayer(&RenderLayer{Name: "actors", Order: 5, Sort: SortY})
+	rs.AddLayer(&RenderLayer{Name: "ground", Order: -5})
+
+	hud := addRenderLayerTestEntity(rs, "ui", -100, 0)
+	front := addRenderLayerTestEntity(rs, "actors", 100, 50)
+	back := addRenderLayerTestEntity(rs, "actors", 0, 20)
+	unknown := addRenderLayerTestEntity(rs, "missing", 1, 0)
+	plain := addRenderLayerTestEntity(rs, "", 0, 0)
+	floor := addRenderLayerTestEntity(rs, "ground", 100, 0)
+
+	assert.Equal(t, []uint64{floor.ID(), plain.ID(), unknown.ID(), back.ID(), front.ID(), hud.ID()}, renderLayerTestOrder(rs),
+		"Entities should be sorted by layer, then by the sort mode of the layer")
+	assert.True(t, rs.sortEveryFrame, "Layers sorted by position should be sorted every frame")
+
+	rs.entities[3].Position.Y = 100
+	assert.Equal(t, []uint64{floor.ID(), plain.ID(), unknown.ID(), front.ID(), back.ID(), hud.ID()}, renderLayerTestOrder(rs),
+		"Moving an entity down should draw it on top")
+
+	rs.AddLayer(&RenderLayer{Name: "actors", Order: 5, Sort: SortCustom, Less: func(a, b RenderLayerEntity) bool {
+		return a.ID() > b.ID()
+	}})
+	rs.RemoveLayer("ground")
+	assert.Equal(t, []uint64{plain.ID(), unknown.ID(), floor.ID(), back.ID(), front.ID(), hud.ID()}, renderLayerTestOrder(rs),
+		"The custom sort should be used, and entities of removed layers should move to the default layer")
+}
+
+func TestRenderLayerSettings(t *testing.T) {
+	rs := newRenderLayerTestSystem()
+	ui := &RenderLayer{Name: "ui", CameraDisabled: true}
+	rs.AddLayer(ui)
+
+	addRenderLayerTestEntity(rs, "", 0, 0)
+	addRenderLayerTestEntity(rs, "ui", 0, 0)
+	rs.entities[1].RenderComponent.shader = TextShader
+	rs.entities[1].RenderComponent.SetLayer("")
+	assert.Equal(t, "", rs.entities[1].RenderComponent.Layer())
+	rs.entities[1].RenderComponent.SetLayer("ui")
+
+	assert.True(t, compareShaders(LegacyShader, rs.shaderOf(rs.entities[0])))
+	assert.True(t, compareShaders(TextHUDShader, rs.shaderOf(rs.entities[1])), "Layers without camera should use HUD shaders")
+
+	ui.Hidden = true
+	assert.True(t, rs.layerOf(rs.entities[1]).Hidden)
+	assert.False(t, rs.layerOf(rs.entities[0]).Hidden)
+	assert.Equal(t, defaultRenderLayer, rs.Layer("missing"))
+}
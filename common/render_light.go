@@ -0,0 +1,813 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+const (
+	// LightSystemPriority makes the LightSystem render the light map right before the RenderSystem draws, after the
+	// other systems moved the lights and the camera.
+	LightSystemPriority = RenderSystemPriority + 10
+	// DefaultLightMapZIndex is the z-index of the light map if the LightSystem doesn't set one. It's above the
+	// default z-index of entities, and below the FPSSystem.
+	DefaultLightMapZIndex = 500
+
+	// spotLightFade is the part of the Cone of a spot light over which it fades out towards the edges
+	spotLightFade = 0.2
+
+	lightVertexShader = `
+attribute vec2 in_Position;
+
+uniform mat3 matrixProjView;
+
+varying vec2 var_Position;
+
+void main() {
+  var_Position = in_Position;
+  vec3 matr = matrixProjView * vec3(in_Position, 1.0);
+  gl_Position = vec4(matr.xy, 0, matr.z);
+}
+`
+
+	lightFragmentShader = `
+#ifdef GL_ES
+precision mediump float;
+#endif
+
+varying vec2 var_Position;
+
+uniform sampler2D uf_Normals;
+uniform vec2 uf_Resolution;
+uniform vec3 uf_Light;
+uniform vec3 uf_Color;
+uniform float uf_Radius;
+uniform vec2 uf_Direction;
+uniform vec2 uf_Cone;
+
+void main() {
+  vec2 d = var_Position - uf_Light.xy;
+  float dist = length(d);
+  if (dist >= uf_Radius) {
+    discard;
+  }
+  float light = 1.0 - dist / uf_Radius;
+  light *= light;
+  if (uf_Cone.x > -1.0 && dist > 0.0) {
+    light *= smoothstep(uf_Cone.x, uf_Cone.y, dot(d / dist, uf_Direction));
+  }
+
+  vec4 normal = texture2D(uf_Normals, gl_FragCoord.xy / uf_Resolution);
+  vec3 n = normalize((normal.rgb * 2.0 - 1.0) * vec3(1.0, -1.0, 1.0));
+  vec3 l = normalize(vec3(-d, uf_Light.z));
+  light *= mix(1.0, max(dot(n, l), 0.0), normal.a);
+
+  gl_FragColor = vec4(uf_Color * light, 0.0);
+}
+`
+
+	lightMaskFragmentShader = `
+#ifdef GL_ES
+precision mediump float;
+#endif
+
+uniform vec4 uf_Color;
+
+void main() {
+  gl_FragColor = uf_Color;
+}
+`
+)
+
+// LightType is the kind of light a LightComponent emits.
+type LightType uint8
+
+const (
+	// PointLight lights every direction around the center of its SpaceComponent, up to its Radius.
+	PointLight LightType = iota
+	// SpotLight lights a Cone in its Direction from the center of its SpaceComponent, up to its Radius.
+	SpotLight
+	// AmbientLight lights everything evenly, and isn't shadowed.
+	AmbientLight
+)
+
+// LightComponent makes an entity emit light, which the LightSystem draws into the light map.
+type LightComponent struct {
+	// Type is the kind of light, which defaults to a PointLight.
+	Type LightType
+	// Color is the color of the light. Defaults to white.
+	Color color.Color
+	// Intensity scales the brightness of the Color. A light with an Intensity of 0 doesn't light anything.
+	Intensity float32
+	// Radius is the distance, in game units, at which point and spot lights fade out completely.
+	Radius float32
+	// Direction is the angle, in degrees clockwise from the right, spot lights point at. It's relative to the
+	// Rotation of the SpaceComponent.
+	Direction float32
+	// Cone is the angle, in degrees, spot lights light around their Direction.
+	Cone float32
+	// Height is how high above the scene, in game units, point and spot lights are. It determines how steep they
+	// light the sprites with a normal map. Defaults to a fourth of the Radius.
+	Height float32
+	// NoShadows stops occluders from casting shadows of the light.
+	NoShadows bool
+	// Disabled turns the light off.
+	Disabled bool
+}
+
+// OccluderComponent makes an entity cast shadows of the lights of the LightSystem. The shadows are cast by the
+// hitboxes added to its SpaceComponent with AddShape, or by the SpaceComponent itself if it has none. The edges of
+// the hitboxes facing the lights are lit, and so is everything in front of them.
+type OccluderComponent struct {
+	// Disabled stops the entity from casting shadows.
+	Disabled bool
+}
+
+// NormalMapComponent gives a sprite a normal map, which makes the lights of the LightSystem light its surface
+// depending on where they are. The normal map is drawn like the Drawable of the RenderComponent, so it should be the
+// same size. Its colors are the normals pointing out of the surface, with red to the right, green up and blue
+// towards the camera. The normals aren't rotated with the sprite. Sprites without a normal map are lit as if they
+// face the camera.
+type NormalMapComponent struct {
+	NormalMap Drawable
+}
+
+type lightEntity struct {
+	*ecs.BasicEntity
+	*LightComponent
+	*SpaceComponent
+}
+
+type occluderEntity struct {
+	*ecs.BasicEntity
+	*OccluderComponent
+	*SpaceComponent
+}
+
+type normalMapEntity struct {
+	*ecs.BasicEntity
+	*NormalMapComponent
+	*RenderComponent
+	*SpaceComponent
+}
+
+// normalRender returns the RenderComponent of the entity drawing its normal map instead, or false if it isn't
+// drawn.
+func (e normalMapEntity) normalRender() (RenderComponent, bool) {
+	if e.RenderComponent.Hidden || e.NormalMap == nil {
+		return RenderComponent{}, false
+	}
+	ren := *e.RenderComponent
+	ren.Drawable = e.NormalMap
+	ren.Color = color.White
+	if ren.Scale.X == 0 && ren.Scale.Y == 0 {
+		ren.Scale = tango.Point{X: 1, Y: 1}
+	}
+	return ren, true
+}
+
+// LightSystem lights the scene. Every frame, it renders the lights into a light map through the main camera, which
+// is drawn on top of the entities below its ZIndex, multiplying their colors. Without any light the scene is black,
+// so an AmbientLight is needed to keep the unlit parts visible. The entities at or above the ZIndex, such as the HUD,
+// aren't lit.
+//
+// The RenderSystem must be added to the World before the LightSystem.
+type LightSystem struct {
+	// ZIndex is the z-index the light map is drawn at. Defaults to DefaultLightMapZIndex.
+	ZIndex float32
+
+	lights     []lightEntity
+	occluders  []occluderEntity
+	normalMaps []normalMapEntity
+
+	world   *ecs.World
+	basic   ecs.BasicEntity
+	render  RenderComponent
+	space   SpaceComponent
+	sources []lightSource
+
+	gl       lightRenderer
+	software softwareLighting
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*LightSystem) Priority() int { return LightSystemPriority }
+
+// New adds the light map to the RenderSystem.
+func (ls *LightSystem) New(w *ecs.World) {
+	ls.world = w
+	if ls.ZIndex == 0 {
+		ls.ZIndex = DefaultLightMapZIndex
+	}
+
+	ls.basic = ecs.NewBasic()
+	ls.render = RenderComponent{Drawable: Texture{}, Hidden: true, StartShader: LightMapShader, StartZIndex: ls.ZIndex}
+	for _, system := range w.Systems() {
+		switch sys := system.(type) {
+		case *RenderSystem:
+			sys.Add(&ls.basic, &ls.render, &ls.space)
+		}
+	}
+}
+
+// Add adds a light to the LightSystem.
+func (ls *LightSystem) Add(basic *ecs.BasicEntity, light *LightComponent, space *SpaceComponent) {
+	ls.lights = append(ls.lights, lightEntity{basic, light, space})
+}
+
+// AddOccluder adds an entity casting shadows to the LightSystem.
+func (ls *LightSystem) AddOccluder(basic *ecs.BasicEntity, occluder *OccluderComponent, space *SpaceComponent) {
+	ls.occluders = append(ls.occluders, occluderEntity{basic, occluder, space})
+}
+
+// AddNormalMap adds a sprite with a normal map to the LightSystem.
+func (ls *LightSystem) AddNormalMap(basic *ecs.BasicEntity, normal *NormalMapComponent, render *RenderComponent, space *SpaceComponent) {
+	ls.normalMaps = append(ls.normalMaps, normalMapEntity{basic, normal, render, space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Lightable interface. It's added as a light if it
+// contains a LightComponent, as an occluder if it contains an OccluderComponent, and as a sprite with a normal map if
+// it contains a NormalMapComponent and a RenderComponent, all anonymously.
+func (ls *LightSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Lightable)
+	if light, ok := i.(LightFace); ok {
+		ls.Add(o.GetBasicEntity(), light.GetLightComponent(), o.GetSpaceComponent())
+	}
+	if occluder, ok := i.(OccluderFace); ok {
+		ls.AddOccluder(o.GetBasicEntity(), occluder.GetOccluderComponent(), o.GetSpaceComponent())
+	}
+	if normal, ok := i.(NormalMapFace); ok {
+		if render, ok := i.(RenderFace); ok {
+			ls.AddNormalMap(o.GetBasicEntity(), normal.GetNormalMapComponent(), render.GetRenderComponent(), o.GetSpaceComponent())
+		}
+	}
+}
+
+// Remove removes the entity from the LightSystem, whether it's a light, an occluder or a sprite with a normal map.
+func (ls *LightSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range ls.lights {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		ls.lights = append(ls.lights[:delete], ls.lights[delete+1:]...)
+	}
+
+	delete = -1
+	for index, e := range ls.occluders {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		ls.occluders = append(ls.occluders[:delete], ls.occluders[delete+1:]...)
+	}
+
+	delete = -1
+	for index, e := range ls.normalMaps {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		ls.normalMaps = append(ls.normalMaps[:delete], ls.normalMaps[delete+1:]...)
+	}
+}
+
+// Update renders the light map, and places it over the view of the main camera.
+func (ls *LightSystem) Update(dt float32) {
+	if tango.Headless() && !SoftwareRendering {
+		return
+	}
+	cam := mainCamera(ls.world)
+	if cam == nil {
+		return
+	}
+	if ls.render.zIndex != ls.ZIndex {
+		ls.render.SetZIndex(ls.ZIndex)
+	}
+
+	width, height := lightMapSize(cam)
+	if width <= 0 || height <= 0 {
+		ls.render.Hidden = true
+		return
+	}
+
+	ambient := ls.collect(cam)
+	var lightMap Drawable
+	if tango.Headless() {
+		lightMap = ls.software.render(cam, width, height, ambient, ls.sources, ls.normalMaps)
+	} else {
+		lightMap = ls.gl.render(cam, width, height, ambient, ls.sources, ls.normalMaps)
+	}
+	ls.cover(cam, lightMap)
+}
+
+// collect gathers the visible point and spot lights with their shadows in sources, and returns the sum of the
+// ambient lights.
+func (ls *LightSystem) collect(cam *CameraSystem) (ambient [3]float32) {
+	ls.sources = ls.sources[:0]
+	for _, e := range ls.lights {
+		if e.LightComponent.Disabled {
+			continue
+		}
+		if e.LightComponent.Type == AmbientLight {
+			c := lightColor(e.LightComponent.Color, e.LightComponent.Intensity)
+			for i := range ambient {
+				ambient[i] += c[i]
+			}
+			continue
+		}
+
+		source, ok := newLightSource(e.LightComponent, e.SpaceComponent)
+		if !ok || !cam.IsVisible(source.bounds()) {
+			continue
+		}
+		if !e.LightComponent.NoShadows {
+			for _, o := range ls.occluders {
+				if o.OccluderComponent.Disabled {
+					continue
+				}
+				for _, outline := range occluderOutlines(o.SpaceComponent) {
+					source.shadows = appendShadows(source.shadows, source.position, source.radius, outline)
+				}
+			}
+		}
+		ls.sources = append(ls.sources, source)
+	}
+	return ambient
+}
+
+// cover places the light map so it covers the view of the camera.
+func (ls *LightSystem) cover(cam *CameraSystem, lightMap Drawable) {
+	width, height := viewportSize(cam)
+	m := cam.viewportMatrix()
+	topLeft := m.InverseTransformPoint(tango.Point{})
+	right := m.InverseTransformPoint(tango.Point{X: width})
+	down := m.InverseTransformPoint(tango.Point{Y: height})
+	right.Subtract(topLeft)
+	down.Subtract(topLeft)
+
+	ls.space.Position = topLeft
+	ls.space.Width, ls.space.Height = right.Length(), down.Length()
+	ls.space.Rotation = math32.Atan2(right.Y, right.X) * 180 / math32.Pi
+
+	ls.render.Drawable = lightMap
+	ls.render.Scale = tango.Point{X: ls.space.Width / lightMap.Width(), Y: ls.space.Height / lightMap.Height()}
+	ls.render.Hidden = false
+}
+
+// lightMapSize returns the size, in pixels, of the part of the canvas, or of its Target, the camera renders to.
+func lightMapSize(cam *CameraSystem) (int, int) {
+	if cam.Target != nil {
+		width, height := viewportSize(cam)
+		return int(width), int(height)
+	}
+	if tango.Headless() {
+		v := softwareViewport(image.Rect(0, 0, int(tango.CanvasWidth()), int(tango.CanvasHeight())), cam.Viewport)
+		return v.Dx(), v.Dy()
+	}
+	_, _, width, height := viewportRect(tango.Gl.GetViewport(), cam.Viewport)
+	return width, height
+}
+
+// lightColor returns the red, green and blue of the color, multiplied by its alpha and the intensity.
+func lightColor(c color.Color, intensity float32) [3]float32 {
+	if c == nil {
+		c = color.White
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	a := float32(n.A) / 0xff * intensity
+	return [3]float32{float32(n.R) / 0xff * a, float32(n.G) / 0xff * a, float32(n.B) / 0xff * a}
+}
+
+func smoothstep(edge0, edge1, x float32) float32 {
+	t := math32.Clamp((x-edge0)/(edge1-edge0), 0, 1)
+	return t * t * (3 - 2*t)
+}
+
+// lightSource is a point or spot light, as it's drawn during a frame.
+type lightSource struct {
+	position       tango.Point
+	height, radius float32
+	color          [3]float32
+	direction      tango.Point
+	// cone are the cosines of the angles from the direction at which a spot light starts and stops fading out. They
+	// are below -1 for point lights.
+	cone [2]float32
+	// shadows are the triangles of the shadows cast by the occluders
+	shadows []tango.Point
+}
+
+// newLightSource returns the light as it's drawn, or false if it doesn't light anything.
+func newLightSource(light *LightComponent, space *SpaceComponent) (lightSource, bool) {
+	s := lightSource{
+		position: space.Center(),
+		height:   light.Height,
+		radius:   light.Radius,
+		color:    lightColor(light.Color, light.Intensity),
+		cone:     [2]float32{-2, -2},
+	}
+	if s.radius <= 0 || s.color == [3]float32{} {
+		return s, false
+	}
+	if s.height <= 0 {
+		s.height = s.radius / 4
+	}
+
+	if light.Type == SpotLight && light.Cone < 360 {
+		if light.Cone <= 0 {
+			return s, false
+		}
+		half := light.Cone / 2 * math32.Pi / 180
+		s.cone = [2]float32{math32.Cos(half), math32.Cos(half * (1 - spotLightFade))}
+		sin, cos := math32.Sincos((light.Direction + space.Rotation) * math32.Pi / 180)
+		s.direction = tango.Point{X: cos, Y: sin}
+	}
+	return s, true
+}
+
+// bounds returns the area the light may light.
+func (s *lightSource) bounds() tango.AABB {
+	return tango.AABB{
+		Min: tango.Point{X: s.position.X - s.radius, Y: s.position.Y - s.radius},
+		Max: tango.Point{X: s.position.X + s.radius, Y: s.position.Y + s.radius},
+	}
+}
+
+// at returns how much the light lights the point, ignoring shadows, like the light shader does. The normal is the
+// color of the normal map at the point, and its alpha how much of a normal map there is.
+func (s *lightSource) at(p tango.Point, normal [4]float32) float32 {
+	dx, dy := p.X-s.position.X, p.Y-s.position.Y
+	dist := math32.Sqrt(dx*dx + dy*dy)
+	if dist >= s.radius {
+		return 0
+	}
+	light := 1 - dist/s.radius
+	light *= light
+	if s.cone[0] > -1 && dist > 0 {
+		light *= smoothstep(s.cone[0], s.cone[1], (dx*s.direction.X+dy*s.direction.Y)/dist)
+	}
+
+	if normal[3] > 0 {
+		nx, ny, nz := normal[0]*2-1, 1-normal[1]*2, normal[2]*2-1
+		lx, ly, lz := -dx, -dy, s.height
+		n := math32.Sqrt(nx*nx + ny*ny + nz*nz)
+		l := math32.Sqrt(lx*lx + ly*ly + lz*lz)
+		var diffuse float32
+		if n > 0 && l > 0 {
+			diffuse = math32.Max((nx*lx+ny*ly+nz*lz)/(n*l), 0)
+		}
+		light *= 1 + (diffuse-1)*normal[3]
+	}
+	return light
+}
+
+// shadowed returns whether the point is in the shadows of the light.
+func (s *lightSource) shadowed(p tango.Point) bool {
+	for i := 0; i+2 < len(s.shadows); i += 3 {
+		if pointInTriangle(p, s.shadows[i], s.shadows[i+1], s.shadows[i+2]) {
+			return true
+		}
+	}
+	return false
+}
+
+// occluderOutlines returns the edges of the hitboxes of the SpaceComponent in world coordinates, by hitbox. Without
+// hitboxes, it's the outline of the SpaceComponent itself.
+func occluderOutlines(space *SpaceComponent) [][]tango.Line {
+	if len(space.hitboxes) == 0 {
+		c := space.Corners()
+		return [][]tango.Line{{{P1: c[0], P2: c[1]}, {P1: c[1], P2: c[3]}, {P1: c[3], P2: c[2]}, {P1: c[2], P2: c[0]}}}
+	}
+
+	sin, cos := math32.Sincos(space.Rotation * math32.Pi / 180)
+	world := func(p tango.Point) tango.Point {
+		return tango.Point{X: space.Position.X + p.X*cos - p.Y*sin, Y: space.Position.Y + p.Y*cos + p.X*sin}
+	}
+
+	outlines := make([][]tango.Line, 0, len(space.hitboxes))
+	for _, hb := range space.hitboxes {
+		var lines []tango.Line
+		if len(hb.Lines) > 0 {
+			for _, l := range hb.Lines {
+				lines = append(lines, tango.Line{P1: world(l.P1), P2: world(l.P2)})
+			}
+		} else if hb.Ellipse.Rx > 0 && hb.Ellipse.Ry > 0 {
+			points := appendArc(nil, tango.Point{X: hb.Ellipse.Cx, Y: hb.Ellipse.Cy}, hb.Ellipse.Rx, hb.Ellipse.Ry, 0, 2*math32.Pi)
+			for i := 0; i+1 < len(points); i++ {
+				lines = append(lines, tango.Line{P1: world(points[i]), P2: world(points[i+1])})
+			}
+		}
+		outlines = append(outlines, lines)
+	}
+	return outlines
+}
+
+// appendShadows appends the triangles of the shadows the outline casts from the light, reaching past its radius.
+// Only the edges facing away from the light cast shadows, so the occluder itself is lit.
+func appendShadows(dst []tango.Point, light tango.Point, radius float32, outline []tango.Line) []tango.Point {
+	if len(outline) == 0 {
+		return dst
+	}
+	var center tango.Point
+	for _, l := range outline {
+		center.X += l.P1.X + l.P2.X
+		center.Y += l.P1.Y + l.P2.Y
+	}
+	center.MultiplyScalar(1 / float32(2*len(outline)))
+
+	// project moves the point away from the light, far enough for the shadow to cover the whole radius
+	project := func(p tango.Point) tango.Point {
+		d := p
+		d.Subtract(light)
+		d, _ = d.Normalize()
+		d.MultiplyScalar(2 * radius)
+		return *d.Add(p)
+	}
+
+	for _, l := range outline {
+		mid := tango.Point{X: (l.P1.X + l.P2.X) / 2, Y: (l.P1.Y + l.P2.Y) / 2}
+		normal := tango.Point{X: l.P2.Y - l.P1.Y, Y: l.P1.X - l.P2.X}
+		if (mid.X-center.X)*normal.X+(mid.Y-center.Y)*normal.Y < 0 {
+			normal.MultiplyScalar(-1)
+		}
+		if (light.X-mid.X)*normal.X+(light.Y-mid.Y)*normal.Y >= 0 || l.P1 == light || l.P2 == light {
+			continue
+		}
+
+		// The shadow is a fan from the first point, through the middle so it stays wide enough far away
+		far1, far2, farMid := project(l.P1), project(l.P2), project(mid)
+		dst = append(dst, l.P1, l.P2, far2, l.P1, far2, farMid, l.P1, farMid, far1)
+	}
+	return dst
+}
+
+// lightMapShader draws the light map of the LightSystem, multiplying the colors of what was drawn below it.
+type lightMapShader struct {
+	basicShader
+}
+
+// Pre implements the Shader interface.
+func (s *lightMapShader) Pre() {
+	s.basicShader.Pre()
+	tango.Gl.BlendFunc(tango.Gl.DST_COLOR, tango.Gl.ZERO)
+}
+
+// Post implements the Shader interface.
+func (s *lightMapShader) Post() {
+	s.basicShader.Post()
+	tango.Gl.BlendFunc(tango.Gl.SRC_ALPHA, tango.Gl.ONE_MINUS_SRC_ALPHA)
+}
+
+// lightProgram is a shader of the LightSystem, which draws triangles given in world coordinates.
+type lightProgram struct {
+	program    *gl.Program
+	inPosition int
+	locations  map[string]*gl.UniformLocation
+}
+
+func newLightProgram(fragment string) *lightProgram {
+	program, err := LoadShader(lightVertexShader, fragment)
+	if err != nil {
+		panic(err)
+	}
+	return &lightProgram{
+		program:    program,
+		inPosition: tango.Gl.GetAttribLocation(program, "in_Position"),
+		locations:  make(map[string]*gl.UniformLocation),
+	}
+}
+
+func (p *lightProgram) location(name string) *gl.UniformLocation {
+	loc, ok := p.locations[name]
+	if !ok {
+		loc = tango.Gl.GetUniformLocation(p.program, name)
+		p.locations[name] = loc
+	}
+	return loc
+}
+
+// use makes the program draw through the matrix.
+func (p *lightProgram) use(matrix *tango.Matrix) {
+	tango.Gl.UseProgram(p.program)
+	tango.Gl.UniformMatrix3fv(p.location("matrixProjView"), false, matrix.Val[:])
+}
+
+// lightRenderer renders the light map with OpenGL. The light map starts as the ambient light, and every light is
+// added to it where it isn't shadowed. The shadows of a light are masked in the alpha channel: it's set to 1 before
+// the light is drawn, the shadows set it to 0, and the light is added weighted by it.
+type lightRenderer struct {
+	framebuffer       *Framebuffer
+	normals, lightMap *RenderTexture
+	buffer            *gl.Buffer
+	light, mask       *lightProgram
+	vertices          []float32
+}
+
+func (r *lightRenderer) setup() {
+	r.framebuffer = CreateFramebuffer()
+	r.buffer = tango.Gl.CreateBuffer()
+	r.light = newLightProgram(lightFragmentShader)
+	r.mask = newLightProgram(lightMaskFragmentShader)
+}
+
+// resize creates the textures of the given size, if they aren't yet.
+func (r *lightRenderer) resize(width, height int) {
+	if r.lightMap != nil && int(r.lightMap.Width()) == width && int(r.lightMap.Height()) == height {
+		return
+	}
+	if r.lightMap != nil {
+		r.lightMap.Close()
+		r.normals.Close()
+	}
+	r.normals = CreateRenderTexture(width, height, false)
+	r.lightMap = CreateRenderTexture(width, height, false)
+	r.lightMap.flipped = true
+	tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, nil)
+}
+
+// draw draws the triangles with the program in use.
+func (r *lightRenderer) draw(p *lightProgram, points []tango.Point) {
+	r.vertices = r.vertices[:0]
+	for _, point := range points {
+		r.vertices = append(r.vertices, point.X, point.Y)
+	}
+	tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, r.buffer)
+	tango.Gl.BufferData(tango.Gl.ARRAY_BUFFER, r.vertices, tango.Gl.DYNAMIC_DRAW)
+	tango.Gl.EnableVertexAttribArray(p.inPosition)
+	tango.Gl.VertexAttribPointer(p.inPosition, 2, tango.Gl.FLOAT, false, 0, 0)
+	tango.Gl.DrawArrays(tango.Gl.TRIANGLES, 0, len(points))
+	tango.Gl.DisableVertexAttribArray(p.inPosition)
+}
+
+// resetMask sets the alpha channel of the light map to 1, leaving its colors.
+func (r *lightRenderer) resetMask() {
+	r.mask.use(tango.IdentityMatrix())
+	tango.Gl.Uniform4f(r.mask.location("uf_Color"), 0, 0, 0, 1)
+	tango.Gl.BlendFunc(tango.Gl.ONE, tango.Gl.ONE)
+	r.draw(r.mask, []tango.Point{{X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: -1, Y: 1}, {X: 1, Y: -1}, {X: 1, Y: 1}})
+}
+
+func (r *lightRenderer) render(cam *CameraSystem, width, height int, ambient [3]float32, sources []lightSource, normalMaps []normalMapEntity) Drawable {
+	if r.framebuffer == nil {
+		r.setup()
+	}
+	r.resize(width, height)
+	r.framebuffer.Open(width, height)
+
+	// The normals are transparent where there isn't a normal map
+	r.normals.Bind()
+	tango.Gl.ClearColor(0.5, 0.5, 1, 0)
+	tango.Gl.Clear(tango.Gl.COLOR_BUFFER_BIT)
+	if len(normalMaps) > 0 {
+		DefaultShader.SetCamera(cam)
+		DefaultShader.PrepareCulling()
+		DefaultShader.Pre()
+		for _, e := range normalMaps {
+			if ren, ok := e.normalRender(); ok {
+				DefaultShader.Draw(&ren, e.SpaceComponent)
+			}
+		}
+		DefaultShader.Post()
+	}
+
+	r.lightMap.Bind()
+	tango.Gl.ClearColor(ambient[0], ambient[1], ambient[2], 1)
+	tango.Gl.Clear(tango.Gl.COLOR_BUFFER_BIT)
+	tango.Gl.Enable(tango.Gl.BLEND)
+
+	width32, height32 := viewportSize(cam)
+	world := tango.IdentityMatrix().Translate(-1, 1).Scale(2/width32, -2/height32).Multiply(cam.viewportMatrix())
+	for i := range sources {
+		s := &sources[i]
+		if i > 0 {
+			r.resetMask()
+		}
+		if len(s.shadows) > 0 {
+			r.mask.use(world)
+			tango.Gl.Uniform4f(r.mask.location("uf_Color"), 0, 0, 0, 1)
+			tango.Gl.BlendFunc(tango.Gl.ZERO, tango.Gl.ONE_MINUS_SRC_COLOR)
+			r.draw(r.mask, s.shadows)
+		}
+
+		r.light.use(world)
+		tango.Gl.ActiveTexture(tango.Gl.TEXTURE0)
+		tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, r.normals.Texture())
+		tango.Gl.Uniform1i(r.light.location("uf_Normals"), 0)
+		tango.Gl.Uniform2f(r.light.location("uf_Resolution"), float32(width), float32(height))
+		tango.Gl.Uniform3f(r.light.location("uf_Light"), s.position.X, s.position.Y, s.height)
+		tango.Gl.Uniform3f(r.light.location("uf_Color"), s.color[0], s.color[1], s.color[2])
+		tango.Gl.Uniform1f(r.light.location("uf_Radius"), s.radius)
+		tango.Gl.Uniform2f(r.light.location("uf_Direction"), s.direction.X, s.direction.Y)
+		tango.Gl.Uniform2f(r.light.location("uf_Cone"), s.cone[0], s.cone[1])
+		tango.Gl.BlendFunc(tango.Gl.DST_ALPHA, tango.Gl.ONE)
+		b := s.bounds()
+		r.draw(r.light, []tango.Point{
+			b.Min, {X: b.Max.X, Y: b.Min.Y}, {X: b.Min.X, Y: b.Max.Y},
+			{X: b.Min.X, Y: b.Max.Y}, {X: b.Max.X, Y: b.Min.Y}, b.Max,
+		})
+	}
+	if len(sources) > 0 {
+		r.resetMask()
+	}
+
+	tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, nil)
+	tango.Gl.BlendFunc(tango.Gl.SRC_ALPHA, tango.Gl.ONE_MINUS_SRC_ALPHA)
+	tango.Gl.ClearColor(backgroundColor[0], backgroundColor[1], backgroundColor[2], backgroundColor[3])
+	r.framebuffer.Close()
+	return r.lightMap
+}
+
+// softwareLighting computes the light map like the lightRenderer does, with the CPU, while SoftwareRendering is
+// set.
+type softwareLighting struct {
+	softwareRenderer
+
+	texture  Texture
+	lightMap *image.NRGBA
+	normals  *image.RGBA
+	light    []float32
+}
+
+func (s *softwareLighting) render(cam *CameraSystem, width, height int, ambient [3]float32, sources []lightSource, normalMaps []normalMapEntity) Drawable {
+	bounds := image.Rect(0, 0, width, height)
+	if s.lightMap == nil || s.lightMap.Rect != bounds {
+		if s.lightMap != nil {
+			s.texture.Close()
+		}
+		s.texture = NewTextureSingle(image.NewNRGBA(bounds))
+		s.lightMap = softwareTextures[s.texture.id]
+		s.normals = image.NewRGBA(bounds)
+		s.light = make([]float32, 3*width*height)
+	}
+
+	// The normals are transparent where there isn't a normal map
+	draw.Draw(s.normals, bounds, image.NewUniform(color.RGBA{R: 0x80, G: 0x80, B: 0xff}), image.Point{}, draw.Src)
+	s.canvas, s.clip, s.transform = s.normals, bounds, softwareTransform(bounds, cam)
+	for _, e := range normalMaps {
+		if ren, ok := e.normalRender(); ok {
+			s.draw(DefaultShader, &ren, e.SpaceComponent, 1)
+		}
+	}
+
+	for i := range s.light {
+		s.light[i] = ambient[i%3]
+	}
+
+	viewWidth, viewHeight := viewportSize(cam)
+	toPixels := tango.IdentityMatrix().Scale(float32(width)/viewWidth, float32(height)/viewHeight).Multiply(cam.viewportMatrix())
+	toWorld := *toPixels
+	toWorld.Invert()
+	for i := range sources {
+		l := &sources[i]
+
+		// Only the pixels within the bounds of the light are lit
+		b := l.bounds()
+		area := image.Rectangle{Min: image.Point{X: width, Y: height}}
+		for _, corner := range [4]tango.Point{b.Min, {X: b.Max.X, Y: b.Min.Y}, {X: b.Min.X, Y: b.Max.Y}, b.Max} {
+			p := toPixels.TransformPoint(corner)
+			area.Min.X = int(math32.Min(float32(area.Min.X), math32.Floor(p.X)))
+			area.Min.Y = int(math32.Min(float32(area.Min.Y), math32.Floor(p.Y)))
+			area.Max.X = int(math32.Max(float32(area.Max.X), math32.Ceil(p.X)))
+			area.Max.Y = int(math32.Max(float32(area.Max.Y), math32.Ceil(p.Y)))
+		}
+		area = area.Intersect(bounds)
+
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				p := toWorld.TransformPoint(tango.Point{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+				if l.shadowed(p) {
+					continue
+				}
+				n := s.normals.Pix[s.normals.PixOffset(x, y):]
+				normal := [4]float32{float32(n[0]) / 0xff, float32(n[1]) / 0xff, float32(n[2]) / 0xff, float32(n[3]) / 0xff}
+				light := l.at(p, normal)
+				if light <= 0 {
+					continue
+				}
+				i := 3 * (y*width + x)
+				for k := 0; k < 3; k++ {
+					s.light[i+k] += l.color[k] * light
+				}
+			}
+		}
+	}
+
+	for i := 0; i < width*height; i++ {
+		p := s.lightMap.Pix[4*i : 4*i+4 : 4*i+4]
+		p[0], p[1], p[2], p[3] = softwareChannel(s.light[3*i]), softwareChannel(s.light[3*i+1]), softwareChannel(s.light[3*i+2]), 0xff
+	}
+	return s.texture
+}
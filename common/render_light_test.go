@@ -0,0 +1,231 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLightShadows(t *testing.T) {
+	outline := []tango.Line{
+		{P1: tango.Point{X: 10, Y: -5}, P2: tango.Point{X: 20, Y: -5}},
+		{P1: tango.Point{X: 20, Y: -5}, P2: tango.Point{X: 20, Y: 5}},
+		{P1: tango.Point{X: 20, Y: 5}, P2: tango.Point{X: 10, Y: 5}},
+		{P1: tango.Point{X: 10, Y: 5}, P2: tango.Point{X: 10, Y: -5}},
+	}
+	s := lightSource{radius: 50}
+	s.shadows = appendShadows(nil, s.position, s.radius, outline)
+	assert.Len(t, s.shadows, 3*3*3, "Only the edges facing away from the light should cast shadows")
+
+	assert.True(t, s.shadowed(tango.Point{X: 30}), "The area behind the occluder should be shadowed")
+	assert.True(t, s.shadowed(tango.Point{X: 49, Y: 10}), "The shadow should widen away from the light")
+	assert.False(t, s.shadowed(tango.Point{X: 5}), "The area in front of the occluder should be lit")
+	assert.False(t, s.shadowed(tango.Point{X: 15}), "The occluder itself should be lit")
+	assert.False(t, s.shadowed(tango.Point{X: 30, Y: 20}))
+}
+
+func TestOccluderOutlines(t *testing.T) {
+	space := &SpaceComponent{Position: tango.Point{X: 10, Y: 10}, Width: 20, Height: 10}
+	outlines := occluderOutlines(space)
+	if assert.Len(t, outlines, 1, "Without hitboxes, the SpaceComponent itself should be the outline") {
+		assert.Equal(t, []tango.Line{
+			{P1: tango.Point{X: 10, Y: 10}, P2: tango.Point{X: 30, Y: 10}},
+			{P1: tango.Point{X: 30, Y: 10}, P2: tango.Point{X: 30, Y: 20}},
+			{P1: tango.Point{X: 30, Y: 20}, P2: tango.Point{X: 10, Y: 20}},
+			{P1: tango.Point{X: 10, Y: 20}, P2: tango.Point{X: 10, Y: 10}},
+		}, outlines[0])
+	}
+
+	space.Rotation = 90
+	space.AddShape(Shape{Lines: []tango.Line{{P1: tango.Point{}, P2: tango.Point{X: 10}}}})
+	space.AddShape(Shape{Ellipse: Ellipse{Cx: 5, Cy: 0, Rx: 5, Ry: 5}})
+	outlines = occluderOutlines(space)
+	if !assert.Len(t, outlines, 2, "There should be an outline by hitbox") {
+		return
+	}
+	if assert.Len(t, outlines[0], 1) {
+		p := outlines[0][0].P2
+		assert.InDelta(t, 10, p.X, 1e-4, "The hitboxes should be rotated with the SpaceComponent")
+		assert.InDelta(t, 20, p.Y, 1e-4)
+	}
+	ellipse := outlines[1]
+	if assert.NotEmpty(t, ellipse) {
+		assert.InDelta(t, 0, ellipse[0].P1.PointDistance(ellipse[len(ellipse)-1].P2), 1e-4, "The ellipse should be closed")
+		for _, l := range ellipse {
+			assert.InDelta(t, 5, l.P1.PointDistance(tango.Point{X: 10, Y: 15}), 1e-3)
+		}
+	}
+}
+
+func TestLightSourceAt(t *testing.T) {
+	flat := [4]float32{0.5, 0.5, 1, 0}
+	point, ok := newLightSource(&LightComponent{Intensity: 1, Radius: 10}, &SpaceComponent{})
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, [3]float32{1, 1, 1}, point.color, "The light should default to white")
+	assert.Equal(t, float32(2.5), point.height, "The height should default to a fourth of the radius")
+	assert.InDelta(t, 1, point.at(tango.Point{}, flat), 1e-6)
+	assert.InDelta(t, 0.25, point.at(tango.Point{X: 5}, flat), 1e-6)
+	assert.InDelta(t, 0.25, point.at(tango.Point{Y: -5}, flat), 1e-6)
+	assert.Equal(t, float32(0), point.at(tango.Point{X: 10}, flat), "The light should fade out at its radius")
+
+	spot, _ := newLightSource(&LightComponent{Type: SpotLight, Intensity: 1, Radius: 10, Cone: 90}, &SpaceComponent{})
+	assert.InDelta(t, 0.25, spot.at(tango.Point{X: 5}, flat), 1e-6)
+	assert.Equal(t, float32(0), spot.at(tango.Point{X: -5}, flat), "Spot lights should only light their cone")
+	assert.InDelta(t, 0, spot.at(tango.Point{X: 3, Y: 3}, flat), 1e-6, "Spot lights should fade out at the edge of their cone")
+	spot, _ = newLightSource(&LightComponent{Type: SpotLight, Intensity: 1, Radius: 10, Cone: 90}, &SpaceComponent{Rotation: 90})
+	assert.InDelta(t, 0.25, spot.at(tango.Point{Y: 5}, flat), 1e-6, "Spot lights should turn with the SpaceComponent")
+
+	toward := [4]float32{0, 0.5, 0.5, 1}
+	away := [4]float32{1, 0.5, 0.5, 1}
+	assert.InDelta(t, 0.25*5/float32(5.5901699), point.at(tango.Point{X: 5}, toward), 1e-5, "Normals facing the light should be lit by the angle to it")
+	assert.Equal(t, float32(0), point.at(tango.Point{X: 5}, away), "Normals facing away from the light shouldn't be lit")
+	up := [4]float32{0.5, 1, 0.5, 1}
+	assert.True(t, point.at(tango.Point{Y: 5}, up) > 0, "Normal maps should point up in green")
+
+	_, ok = newLightSource(&LightComponent{Radius: 10}, &SpaceComponent{})
+	assert.False(t, ok, "A light without intensity shouldn't light anything")
+	_, ok = newLightSource(&LightComponent{Type: SpotLight, Intensity: 1, Radius: 10}, &SpaceComponent{})
+	assert.False(t, ok, "A spot light without a cone shouldn't light anything")
+}
+
+// newLightTestWorld returns a software rendered world with a white background, lit by the LightSystem
+func newLightTestWorld() (*ecs.World, *RenderSystem, *LightSystem) {
+	w, rs := newSoftwareTestWorld()
+	addSoftwareTestEntity(rs, Rectangle{}, 0, 0, 200, 100).Color = color.White
+	ls := &LightSystem{}
+	w.AddSystem(ls)
+	return w, rs, ls
+}
+
+func addLightTestEntity(ls *LightSystem, light LightComponent, x, y float32) *ecs.BasicEntity {
+	basic := ecs.NewBasic()
+	ls.Add(&basic, &light, &SpaceComponent{Position: tango.Point{X: x, Y: y}})
+	return &basic
+}
+
+// assertLightPixel asserts the white background is lit by the given amount at the pixel
+func assertLightPixel(t *testing.T, light float32, x, y int, msgAndArgs ...interface{}) {
+	c := uint8(int(softwareChannel(light)) * 0xfe / 0xff)
+	assertSoftwarePixel(t, color.RGBA{R: c, G: c, B: c, A: 0xfe}, x, y, msgAndArgs...)
+}
+
+func TestLightSystem(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs, ls := newLightTestWorld()
+
+	addLightTestEntity(ls, LightComponent{Type: AmbientLight, Intensity: 0.25}, 0, 0)
+	addLightTestEntity(ls, LightComponent{Intensity: 1, Radius: 40}, 50.5, 50.5)
+	w.Update(1)
+
+	assertLightPixel(t, 1, 50, 50, "The center of the light should be fully lit")
+	assertLightPixel(t, 0.5, 70, 50, "The light should fade out with the distance")
+	assertLightPixel(t, 0.25, 95, 50, "Past the radius of the light, there should only be the ambient light")
+
+	hud := addSoftwareTestEntity(rs, Rectangle{}, 150, 0, 10, 10)
+	hud.Color = color.White
+	hud.SetZIndex(DefaultLightMapZIndex + 1)
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{R: 0xfe, G: 0xfe, B: 0xfe, A: 0xfe}, 155, 5, "Entities above the light map shouldn't be lit")
+}
+
+func TestLightSystemShadows(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, _, ls := newLightTestWorld()
+
+	light := addLightTestEntity(ls, LightComponent{Intensity: 1, Radius: 100}, 50.5, 50.5)
+	basic := ecs.NewBasic()
+	occluder := &OccluderComponent{}
+	ls.AddOccluder(&basic, occluder, &SpaceComponent{Position: tango.Point{X: 80, Y: 40}, Width: 10, Height: 20})
+	w.Update(1)
+
+	assertLightPixel(t, 0, 120, 50, "The occluder should cast a shadow")
+	assertLightPixel(t, 0.5625, 75, 50, "The area in front of the occluder should be lit")
+	assertLightPixel(t, 0.4225, 85, 50, "The occluder itself should be lit")
+	assertLightPixel(t, 0.49, 50, 20)
+
+	occluder.Disabled = true
+	w.Update(1)
+	assertLightPixel(t, 0.09, 120, 50, "A disabled occluder shouldn't cast shadows")
+
+	ls.Remove(*light)
+	w.Update(1)
+	assertLightPixel(t, 0, 50, 50, "Removed lights shouldn't light anything")
+}
+
+func TestLightSystemSpotLight(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, _, ls := newLightTestWorld()
+
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{Position: tango.Point{X: 100.5, Y: 50.5}}
+	ls.Add(&basic, &LightComponent{Type: SpotLight, Intensity: 1, Radius: 80, Cone: 90}, space)
+	w.Update(1)
+
+	assertLightPixel(t, 0.390625, 130, 50, "The spot light should light its direction")
+	assertLightPixel(t, 0, 70, 50, "The spot light shouldn't light behind it")
+	assertLightPixel(t, 0, 130, 80, "The spot light should fade out at the edge of its cone")
+
+	space.Rotation = 90
+	w.Update(1)
+	assertLightPixel(t, 0.390625, 100, 80, "The spot light should turn with its SpaceComponent")
+	assertLightPixel(t, 0, 130, 50)
+}
+
+func TestLightSystemNormalMap(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs, ls := newLightTestWorld()
+
+	white := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	white.SetNRGBA(0, 0, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	sprite := NewTextureSingle(white)
+	defer sprite.Close()
+	left := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	left.SetNRGBA(0, 0, color.NRGBA{G: 0x80, B: 0x80, A: 0xff})
+	normalMap := NewTextureSingle(left)
+	defer normalMap.Close()
+
+	addLightTestEntity(ls, LightComponent{Intensity: 1, Radius: 100}, 100.5, 50.5)
+	for _, x := range []float32{40, 140} {
+		basic := ecs.NewBasic()
+		render := &RenderComponent{Drawable: sprite, Scale: tango.Point{X: 10, Y: 10}}
+		space := &SpaceComponent{Position: tango.Point{X: x, Y: 40}, Width: 10, Height: 10}
+		rs.Add(&basic, render, space)
+		ls.AddNormalMap(&basic, &NormalMapComponent{NormalMap: normalMap}, render, space)
+	}
+	w.Update(1)
+
+	assertLightPixel(t, 0, 45, 45, "A normal map facing away from the light shouldn't be lit")
+	c := SoftwareCanvas().RGBAAt(145, 45)
+	assert.InDelta(t, 67, c.R, 2, "A normal map facing the light should be lit by the angle to it")
+	assertLightPixel(t, 0.2763, 145, 35, "Sprites without a normal map should be lit as if they face the camera")
+}
+
+type lightTestEntity struct {
+	*ecs.BasicEntity
+	*LightComponent
+	*OccluderComponent
+	*SpaceComponent
+}
+
+func TestLightSystemAddByInterface(t *testing.T) {
+	ls := &LightSystem{}
+	basic := ecs.NewBasic()
+	ls.AddByInterface(lightTestEntity{&basic, &LightComponent{}, &OccluderComponent{}, &SpaceComponent{}})
+	assert.Len(t, ls.lights, 1)
+	assert.Len(t, ls.occluders, 1, "Entities should be added as occluders as well if they have an OccluderComponent")
+	assert.Empty(t, ls.normalMaps)
+
+	ls.Remove(basic)
+	assert.Empty(t, ls.lights)
+	assert.Empty(t, ls.occluders)
+}
@@ -0,0 +1,208 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+)
+
+// Uniform is the value of a uniform of a shader. It's created by UniformFloat, UniformVec2, UniformVec3, UniformVec4,
+// UniformColor or UniformTexture.
+type Uniform struct {
+	size    int
+	value   [4]float32
+	texture *gl.Texture
+}
+
+// UniformFloat returns a float uniform.
+func UniformFloat(x float32) Uniform {
+	return Uniform{size: 1, value: [4]float32{x}}
+}
+
+// UniformVec2 returns a vec2 uniform.
+func UniformVec2(x, y float32) Uniform {
+	return Uniform{size: 2, value: [4]float32{x, y}}
+}
+
+// UniformVec3 returns a vec3 uniform.
+func UniformVec3(x, y, z float32) Uniform {
+	return Uniform{size: 3, value: [4]float32{x, y, z}}
+}
+
+// UniformVec4 returns a vec4 uniform.
+func UniformVec4(x, y, z, w float32) Uniform {
+	return Uniform{size: 4, value: [4]float32{x, y, z, w}}
+}
+
+// UniformColor returns a vec4 uniform of the red, green, blue and alpha of the color, ranging from 0 to 1. The color
+// isn't premultiplied with its alpha.
+func UniformColor(c color.Color) Uniform {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return UniformVec4(float32(n.R)/0xff, float32(n.G)/0xff, float32(n.B)/0xff, float32(n.A)/0xff)
+}
+
+// UniformTexture returns a sampler2D uniform of the texture of the Drawable.
+func UniformTexture(d Drawable) Uniform {
+	return Uniform{texture: d.Texture()}
+}
+
+// Uniforms are the values of the uniforms of a shader, by name.
+type Uniforms map[string]Uniform
+
+// equal returns whether both have the same values. Being nil is the same as being empty.
+func (u Uniforms) equal(other Uniforms) bool {
+	if len(u) != len(other) {
+		return false
+	}
+	for name, value := range u {
+		if o, ok := other[name]; !ok || o != value {
+			return false
+		}
+	}
+	return true
+}
+
+// apply sets the uniforms of the current program, found by location. Textures are bound to the texture units from
+// unit onward, and the next free unit is returned.
+func (u Uniforms) apply(location func(name string) *gl.UniformLocation, unit int) int {
+	for name, value := range u {
+		loc := location(name)
+		switch value.size {
+		case 0:
+			tango.Gl.ActiveTexture(tango.Gl.TEXTURE0 + unit)
+			tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, value.texture)
+			tango.Gl.Uniform1i(loc, unit)
+			unit++
+		case 1:
+			tango.Gl.Uniform1f(loc, value.value[0])
+		case 2:
+			tango.Gl.Uniform2f(loc, value.value[0], value.value[1])
+		case 3:
+			tango.Gl.Uniform3f(loc, value.value[0], value.value[1], value.value[2])
+		case 4:
+			tango.Gl.Uniform4f(loc, value.value[0], value.value[1], value.value[2], value.value[3])
+		}
+	}
+	// Other shaders rely on the first unit being active
+	tango.Gl.ActiveTexture(tango.Gl.TEXTURE0)
+	return unit
+}
+
+// Material is a Shader drawing sprites, like the DefaultShader, with a custom vertex and fragment shader. It's used
+// by setting it as the shader of a RenderComponent, which can set the values of the uniforms in its Uniforms.
+// Entities with the same values are batched together.
+//
+// The vertex shader is given the attributes in_Position, in_TexCoords and in_Color, and the uniform mat3
+// matrixProjView, the same as the DefaultShader. Without a vertex shader, the one of the DefaultShader is used, which
+// passes var_Color and var_TexCoords on to the fragment shader. The texture of the Drawable is in the sampler2D
+// uf_Texture. A dissolve effect would be:
+//
+//	dissolve := NewMaterial("", `
+//	varying vec4 var_Color;
+//	varying vec2 var_TexCoords;
+//	uniform sampler2D uf_Texture;
+//	uniform sampler2D uf_Noise;
+//	uniform float uf_Progress;
+//
+//	void main() {
+//	  if (texture2D(uf_Noise, var_TexCoords).r < uf_Progress) discard;
+//	  gl_FragColor = var_Color * texture2D(uf_Texture, var_TexCoords);
+//	}`)
+//	dissolve.Uniforms["uf_Noise"] = UniformTexture(noise)
+//	render.SetShader(dissolve)
+//	render.Uniforms = Uniforms{"uf_Progress": UniformFloat(0.5)}
+type Material struct {
+	// Vertex and Fragment are the sources of the shaders.
+	Vertex, Fragment string
+	// Uniforms are the values of the uniforms for all entities drawn with the material. The Uniforms of a
+	// RenderComponent replace these.
+	Uniforms Uniforms
+	// HUD draws the entities on the HUD, like the HUDShader, instead of through the camera.
+	HUD bool
+
+	basicShader
+
+	locations map[string]*gl.UniformLocation
+	current   Uniforms
+}
+
+// NewMaterial returns a Material with the given vertex and fragment shader. The vertex shader may be empty to use the
+// one of the DefaultShader. The Material is added with AddShader, and is set up when it's first drawn if the
+// RenderSystem was already added.
+func NewMaterial(vertex, fragment string) *Material {
+	m := &Material{
+		Vertex:   vertex,
+		Fragment: fragment,
+		Uniforms: Uniforms{},
+	}
+	m.cameraEnabled = true
+	AddShader(m)
+	return m
+}
+
+// Setup compiles the shaders of the Material.
+func (m *Material) Setup(w *ecs.World) error {
+	vertex := m.Vertex
+	if vertex == "" {
+		vertex = defaultVertexShader
+	}
+	m.cameraEnabled = !m.HUD
+	if err := m.basicShader.setup(vertex, m.Fragment); err != nil {
+		return err
+	}
+	m.locations = make(map[string]*gl.UniformLocation)
+	return nil
+}
+
+func (m *Material) ensureSetup() {
+	if m.program != nil {
+		return
+	}
+	if err := m.Setup(nil); err != nil {
+		panic(err)
+	}
+}
+
+func (m *Material) location(name string) *gl.UniformLocation {
+	loc, ok := m.locations[name]
+	if !ok {
+		loc = tango.Gl.GetUniformLocation(m.program, name)
+		m.locations[name] = loc
+	}
+	return loc
+}
+
+// PrepareCulling implements the CullingShader interface.
+func (m *Material) PrepareCulling() {
+	m.ensureSetup()
+	m.basicShader.PrepareCulling()
+}
+
+// Pre implements the Shader interface.
+func (m *Material) Pre() {
+	m.ensureSetup()
+	m.basicShader.Pre()
+	m.Uniforms.apply(m.location, 1)
+	m.current = nil
+}
+
+// Draw implements the Shader interface.
+func (m *Material) Draw(ren *RenderComponent, space *SpaceComponent) {
+	if !ren.Uniforms.equal(m.current) {
+		m.flush()
+		// The values of the previous entity which this one doesn't replace go back to the ones of the material
+		unit := m.Uniforms.apply(m.location, 1)
+		ren.Uniforms.apply(m.location, unit)
+		m.current = ren.Uniforms
+	}
+	m.basicShader.Draw(ren, space)
+}
+
+// SetCamera implements the Shader interface.
+func (m *Material) SetCamera(c *CameraSystem) {
+	m.ensureSetup()
+	m.cameraEnabled = !m.HUD
+	m.basicShader.SetCamera(c)
+}
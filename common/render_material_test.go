@@ -0,0 +1,41 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniforms(t *testing.T) {
+	assert.Equal(t, UniformVec4(1, 0, 0.5, 0.5), UniformVec4(1, 0, 0.5, 0.5))
+	assert.NotEqual(t, UniformVec2(1, 0), UniformVec3(1, 0, 0), "Uniforms of different types should differ")
+	assert.Equal(t, UniformVec4(1, 0, 1, float32(0x80)/0xff), UniformColor(color.NRGBA{R: 0xff, B: 0xff, A: 0x80}))
+	assert.Equal(t, UniformColor(color.NRGBA{R: 0xff, A: 0x80}), UniformColor(color.RGBA{R: 0x80, A: 0x80}),
+		"Colors should not be premultiplied")
+
+	first, second := &gl.Texture{}, &gl.Texture{}
+	assert.True(t, UniformTexture(&Texture{id: first}) == UniformTexture(&Texture{id: first}))
+	assert.False(t, UniformTexture(&Texture{id: first}) == UniformTexture(&Texture{id: second}), "Textures should be compared by identity")
+
+	var none Uniforms
+	progress := Uniforms{"uf_Progress": UniformFloat(0.5)}
+	assert.True(t, none.equal(Uniforms{}), "Nil uniforms should equal empty ones")
+	assert.True(t, progress.equal(Uniforms{"uf_Progress": UniformFloat(0.5)}))
+	assert.False(t, progress.equal(Uniforms{"uf_Progress": UniformFloat(0.25)}))
+	assert.False(t, progress.equal(Uniforms{"uf_Other": UniformFloat(0.5)}))
+	assert.False(t, progress.equal(none))
+}
+
+func TestMaterial(t *testing.T) {
+	defer func(s []Shader) { shaders = s }(shaders)
+
+	dissolve := NewMaterial("", defaultFragmentShader)
+	assert.Equal(t, Shader(dissolve), shaders[len(shaders)-1], "The material should be added as shader")
+	assert.NotNil(t, dissolve.Uniforms, "Uniforms should be ready to be set")
+	assert.False(t, isHUDShader(dissolve))
+
+	dissolve.HUD = true
+	assert.True(t, isHUDShader(dissolve), "A material can be drawn on the HUD")
+}
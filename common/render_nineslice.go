@@ -0,0 +1,82 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/tango/gl"
+)
+
+// NineSlice is a Drawable which draws an image at the size of its SpaceComponent without stretching its borders,
+// such as for the panels and buttons of a UI. The image is sliced into nine parts by the insets of its borders. The
+// corners are drawn at their size, the edges are stretched, or tiled, along their length, and the center is
+// stretched, or tiled, to fill what's left. It's drawn by the DefaultShader and the HUDShader, and batched with the
+// other sprites of the same texture.
+//
+// The size it's drawn at is the size of the SpaceComponent divided by the Scale of the RenderComponent, so the Scale
+// scales the borders. If the SpaceComponent is smaller than the borders, they're shrunk to fit.
+type NineSlice struct {
+	// Drawable is the image which is sliced, such as a Texture or a cell of a Spritesheet.
+	Drawable Drawable
+	// Left, Top, Right and Bottom are the insets of the borders from the edges of the Drawable, in pixels.
+	Left, Top, Right, Bottom float32
+	// TileEdges repeats the edges along their length instead of stretching them.
+	TileEdges bool
+	// TileCenter repeats the center instead of stretching it.
+	TileCenter bool
+}
+
+// Texture returns the texture of the Drawable. This implements the Drawable interface.
+func (n NineSlice) Texture() *gl.Texture { return n.Drawable.Texture() }
+
+// Width returns the width of the Drawable. The NineSlice is drawn at the width of its SpaceComponent instead. This
+// implements the Drawable interface.
+func (n NineSlice) Width() float32 { return n.Drawable.Width() }
+
+// Height returns the height of the Drawable. The NineSlice is drawn at the height of its SpaceComponent instead. This
+// implements the Drawable interface.
+func (n NineSlice) Height() float32 { return n.Drawable.Height() }
+
+// View returns the view of the Drawable. This implements the Drawable interface.
+func (n NineSlice) View() (float32, float32, float32, float32) { return n.Drawable.View() }
+
+// Close closes the Drawable. This implements the Drawable interface.
+func (n NineSlice) Close() { n.Drawable.Close() }
+
+// nineSliceAxis divides the size along an axis into the spans of the first border, the middle and the last border.
+// The borders are the given number of pixels of the source, of which u to u2 are the texture coordinates.
+func nineSliceAxis(size, source, first, last, u, u2 float32) [3]quadSpan {
+	firstU := u + (u2-u)*first/source
+	lastU := u2 - (u2-u)*last/source
+	if first+last > size {
+		first, last = first*size/(first+last), last*size/(first+last)
+	}
+	return [3]quadSpan{
+		{from: 0, to: first, u: u, u2: firstU},
+		{from: first, to: size - last, u: firstU, u2: lastU},
+		{from: size - last, to: size, u: lastU, u2: u2},
+	}
+}
+
+// quads calls fn for each quad drawing the NineSlice at the given size.
+func (n NineSlice) quads(width, height float32, fn func(x, y quadSpan)) {
+	u, v, u2, v2 := n.Drawable.View()
+	sourceWidth, sourceHeight := n.Drawable.Width(), n.Drawable.Height()
+	columns := nineSliceAxis(width, sourceWidth, n.Left, n.Right, u, u2)
+	rows := nineSliceAxis(height, sourceHeight, n.Top, n.Bottom, v, v2)
+	// The size of a tile is the size of the middle of the source
+	tileWidth := sourceWidth - n.Left - n.Right
+	tileHeight := sourceHeight - n.Top - n.Bottom
+
+	for j, row := range rows {
+		for i, column := range columns {
+			// The center tiles along both axes, and the edges only along their length
+			tileX, tileY := i == 1 && n.TileEdges, j == 1 && n.TileEdges
+			if i == 1 && j == 1 {
+				tileX, tileY = n.TileCenter, n.TileCenter
+			}
+			row.tiles(tileHeight, tileY, false, func(y quadSpan) {
+				column.tiles(tileWidth, tileX, false, func(x quadSpan) {
+					fn(x, y)
+				})
+			})
+		}
+	}
+}
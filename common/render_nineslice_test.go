@@ -0,0 +1,91 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func nineSliceTestQuads(n NineSlice, width, height float32) (x, y []quadSpan) {
+	n.quads(width, height, func(qx, qy quadSpan) {
+		x = append(x, qx)
+		y = append(y, qy)
+	})
+	return x, y
+}
+
+func assertNineSliceSpan(t *testing.T, expected, actual quadSpan, msgAndArgs ...interface{}) {
+	assert.InDeltaSlice(t, []float32{expected.from, expected.to, expected.u, expected.u2},
+		[]float32{actual.from, actual.to, actual.u, actual.u2}, 1e-5, msgAndArgs...)
+}
+
+func TestNineSliceQuads(t *testing.T) {
+	slice := NineSlice{Drawable: Texture{width: 6, height: 6, viewport: tango.AABB{Max: tango.Point{X: 1, Y: 1}}}, Left: 2, Top: 2, Right: 2, Bottom: 2}
+
+	x, y := nineSliceTestQuads(slice, 20, 10)
+	if assert.Len(t, x, 9, "Without tiling, each of the nine parts should be a quad") {
+		assertNineSliceSpan(t, quadSpan{from: 0, to: 2, u: 0, u2: 1.0 / 3}, x[0], "The corners should not be scaled")
+		assertNineSliceSpan(t, quadSpan{from: 2, to: 18, u: 1.0 / 3, u2: 2.0 / 3}, x[1], "The edges should be stretched")
+		assertNineSliceSpan(t, quadSpan{from: 18, to: 20, u: 2.0 / 3, u2: 1}, x[2])
+		assertNineSliceSpan(t, quadSpan{from: 8, to: 10, u: 2.0 / 3, u2: 1}, y[8])
+	}
+
+	slice.TileEdges = true
+	x, _ = nineSliceTestQuads(slice, 20, 10)
+	assert.Len(t, x, 4+2*8+2*3+1, "The edges should be tiled along their length")
+
+	slice.TileCenter = true
+	x, _ = nineSliceTestQuads(slice, 19, 10)
+	if assert.Len(t, x, 4+2*8+2*3+8*3) {
+		assertNineSliceSpan(t, quadSpan{from: 16, to: 17, u: 1.0 / 3, u2: 0.5}, x[8], "The last tile should be cut off")
+	}
+
+	slice.TileEdges, slice.TileCenter = false, false
+	x, _ = nineSliceTestQuads(slice, 2, 2)
+	if assert.Len(t, x, 4, "Without room for the middle, only the borders should be drawn") {
+		assertNineSliceSpan(t, quadSpan{from: 0, to: 1, u: 0, u2: 1.0 / 3}, x[0], "The borders should be shrunk to fit")
+	}
+}
+
+func TestNineSliceSoftwareRendering(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	red, green, blue := color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{G: 0xff, A: 0xff}, color.NRGBA{B: 0xff, A: 0xff}
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			switch {
+			case x == 1 && y == 1:
+				img.SetNRGBA(x, y, blue)
+			case x == 1 || y == 1:
+				img.SetNRGBA(x, y, green)
+			default:
+				img.SetNRGBA(x, y, red)
+			}
+		}
+	}
+	texture := NewTextureSingle(NewImageObject(img))
+	defer texture.Close()
+
+	render := addSoftwareTestEntity(rs, NineSlice{Drawable: texture, Left: 1, Top: 1, Right: 1, Bottom: 1}, 10, 10, 30, 20)
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 10, 10, "The corners should be drawn at their size")
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 11, 10)
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 38, 10, "The edges should be stretched")
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 39, 29)
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 11, 11, "The center should fill the rest")
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 38, 28)
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 40, 30, "The NineSlice should be the size of the SpaceComponent")
+
+	render.Scale = tango.Point{X: 2, Y: 2}
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 11, 11, "The Scale should scale the borders")
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 12, 12)
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 39, 29)
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 40, 30)
+}
@@ -0,0 +1,360 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+)
+
+// PostProcessVertexShader is the vertex shader used by every PostProcessPass. It draws a quad covering the whole
+// canvas and passes the texture coordinates on to the fragment shader in var_TexCoords.
+const PostProcessVertexShader = `
+attribute vec2 in_Position;
+
+varying vec2 var_TexCoords;
+
+void main() {
+  var_TexCoords = in_Position * 0.5 + 0.5;
+  gl_Position = vec4(in_Position, 0.0, 1.0);
+}
+`
+
+// PostProcessFragmentHeader declares the varying and uniforms every PostProcessPass is given. It can be put in front
+// of the fragment shader of a custom pass.
+const PostProcessFragmentHeader = `
+#ifdef GL_ES
+precision mediump float;
+#endif
+
+varying vec2 var_TexCoords;
+
+uniform sampler2D uf_Texture;
+uniform sampler2D uf_Scene;
+uniform vec2 uf_Resolution;
+uniform float uf_Time;
+`
+
+// PostProcessPass is a full-screen pass applied to the rendered scene by the RenderSystem, such as a blur or a
+// vignette. The fragment shader of a pass is given:
+//
+//	varying vec2 var_TexCoords;     // the texture coordinates of the pixel, from 0 to 1
+//	uniform sampler2D uf_Texture;   // the output of the previous pass, or the scene for the first pass
+//	uniform sampler2D uf_Scene;     // the scene as it was rendered, before any pass
+//	uniform vec2 uf_Resolution;     // the size of the canvas in pixels
+//	uniform float uf_Time;          // the seconds passed since post-processing started
+//
+// and any uniforms set in Uniforms. These are declared by PostProcessFragmentHeader.
+type PostProcessPass struct {
+	// Fragment is the source of the fragment shader. It's compiled with PostProcessVertexShader the first time the
+	// pass is drawn.
+	Fragment string
+	// Program is the compiled shader. It can be set instead of Fragment by loading a fragment shader together with
+	// the PostProcessVertexShader using LoadShader.
+	Program *gl.Program
+	// Uniforms are the values of the uniforms of the fragment shader.
+	Uniforms Uniforms
+	// Disabled skips the pass.
+	Disabled bool
+
+	inPosition int
+	locations  map[string]*gl.UniformLocation
+}
+
+func (p *PostProcessPass) setup() {
+	if p.Program == nil {
+		var err error
+		p.Program, err = LoadShader(PostProcessVertexShader, p.Fragment)
+		if err != nil {
+			panic(err)
+		}
+	}
+	p.inPosition = tango.Gl.GetAttribLocation(p.Program, "in_Position")
+	p.locations = make(map[string]*gl.UniformLocation)
+}
+
+func (p *PostProcessPass) location(name string) *gl.UniformLocation {
+	loc, ok := p.locations[name]
+	if !ok {
+		loc = tango.Gl.GetUniformLocation(p.Program, name)
+		p.locations[name] = loc
+	}
+	return loc
+}
+
+// postProcessor renders the scene to a texture, and applies the passes to it. The passes take turns drawing to two
+// textures, and the last one draws to the canvas.
+type postProcessor struct {
+	passes []*PostProcessPass
+
+	framebuffer   *Framebuffer
+	scene         *RenderTexture
+	targets       [2]*RenderTexture
+	vertexBuffer  *gl.Buffer
+	width, height int
+	time          float32
+}
+
+// active returns the passes which aren't disabled.
+func (p *postProcessor) active() []*PostProcessPass {
+	var active []*PostProcessPass
+	for _, pass := range p.passes {
+		if !pass.Disabled {
+			active = append(active, pass)
+		}
+	}
+	return active
+}
+
+func (p *postProcessor) createTarget() *RenderTexture {
+	t := CreateRenderTexture(p.width, p.height, false)
+	tango.Gl.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_WRAP_S, tango.Gl.CLAMP_TO_EDGE)
+	tango.Gl.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_WRAP_T, tango.Gl.CLAMP_TO_EDGE)
+	return t
+}
+
+// resize creates the textures the size of the canvas, if they aren't yet.
+func (p *postProcessor) resize(width, height int) {
+	if p.framebuffer == nil {
+		p.framebuffer = CreateFramebuffer()
+		p.vertexBuffer = tango.Gl.CreateBuffer()
+		tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, p.vertexBuffer)
+		tango.Gl.BufferData(tango.Gl.ARRAY_BUFFER, []float32{-1, -1, 1, -1, -1, 1, 1, 1}, tango.Gl.STATIC_DRAW)
+	}
+	if p.scene != nil && p.width == width && p.height == height {
+		return
+	}
+
+	if p.scene != nil {
+		p.scene.Close()
+		p.targets[0].Close()
+		p.targets[1].Close()
+	}
+	p.width, p.height = width, height
+	p.scene = p.createTarget()
+	p.targets[0] = p.createTarget()
+	p.targets[1] = p.createTarget()
+	tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, nil)
+}
+
+// begin makes everything drawn afterwards draw to the scene texture instead of the canvas.
+func (p *postProcessor) begin(canvas [4]int32) {
+	p.resize(int(canvas[2]), int(canvas[3]))
+	p.framebuffer.Open(p.width, p.height)
+	p.scene.Bind()
+	tango.Gl.Clear(tango.Gl.COLOR_BUFFER_BIT)
+}
+
+// end applies the passes to the scene texture, drawing the result to the canvas.
+func (p *postProcessor) end(passes []*PostProcessPass, dt float32) {
+	p.time += dt
+
+	tango.Gl.Disable(tango.Gl.BLEND)
+	tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, p.vertexBuffer)
+
+	input := p.scene
+	for i, pass := range passes {
+		var output *RenderTexture
+		if i == len(passes)-1 {
+			p.framebuffer.Close()
+		} else {
+			output = p.targets[i%2]
+			output.Bind()
+		}
+
+		if pass.locations == nil {
+			pass.setup()
+		}
+		tango.Gl.UseProgram(pass.Program)
+
+		tango.Gl.ActiveTexture(tango.Gl.TEXTURE1)
+		tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, p.scene.Texture())
+		tango.Gl.ActiveTexture(tango.Gl.TEXTURE0)
+		tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, input.Texture())
+
+		tango.Gl.Uniform1i(pass.location("uf_Texture"), 0)
+		tango.Gl.Uniform1i(pass.location("uf_Scene"), 1)
+		tango.Gl.Uniform2f(pass.location("uf_Resolution"), float32(p.width), float32(p.height))
+		tango.Gl.Uniform1f(pass.location("uf_Time"), p.time)
+		pass.Uniforms.apply(pass.location, 2)
+
+		tango.Gl.EnableVertexAttribArray(pass.inPosition)
+		tango.Gl.VertexAttribPointer(pass.inPosition, 2, tango.Gl.FLOAT, false, 0, 0)
+		tango.Gl.DrawArrays(tango.Gl.TRIANGLE_STRIP, 0, 4)
+		tango.Gl.DisableVertexAttribArray(pass.inPosition)
+
+		input = output
+	}
+
+	tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, nil)
+	tango.Gl.Enable(tango.Gl.BLEND)
+}
+
+// AddPostProcessPass adds passes to the end of the post-processing chain. Once there is a pass which isn't disabled,
+// the scene, including the HUD, is rendered to a texture, and the passes are applied to it in order before it's drawn
+// to the canvas.
+func (rs *RenderSystem) AddPostProcessPass(passes ...*PostProcessPass) {
+	rs.postProcess.passes = append(rs.postProcess.passes, passes...)
+}
+
+// RemovePostProcessPass removes the pass from the post-processing chain.
+func (rs *RenderSystem) RemovePostProcessPass(pass *PostProcessPass) {
+	delete := -1
+	for index, p := range rs.postProcess.passes {
+		if p == pass {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		rs.postProcess.passes = append(rs.postProcess.passes[:delete], rs.postProcess.passes[delete+1:]...)
+	}
+}
+
+// PostProcessPasses returns the passes of the post-processing chain, in the order they're applied.
+func (rs *RenderSystem) PostProcessPasses() []*PostProcessPass {
+	return rs.postProcess.passes
+}
+
+// NewVignettePass returns a pass which darkens the corners of the canvas. Its uniforms are uf_Intensity, how dark the
+// corners get from 0 to 1, and uf_Radius, the distance from the center at which the darkening starts, where 1 is the
+// distance to the edges.
+func NewVignettePass(intensity float32) *PostProcessPass {
+	return &PostProcessPass{
+		Fragment: PostProcessFragmentHeader + `
+uniform float uf_Intensity;
+uniform float uf_Radius;
+
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  float dist = length(var_TexCoords - 0.5) * 2.0;
+  float vignette = smoothstep(uf_Radius, 1.5, dist) * uf_Intensity;
+  gl_FragColor = vec4(color.rgb * (1.0 - vignette), color.a);
+}
+`,
+		Uniforms: Uniforms{
+			"uf_Intensity": UniformFloat(intensity),
+			"uf_Radius":    UniformFloat(0.5),
+		},
+	}
+}
+
+// NewCRTPass returns a pass which makes the canvas look like an old monitor. Its uniforms are uf_Curvature, how much
+// the screen bulges, and uf_Scanlines, how dark the scanlines are from 0 to 1.
+func NewCRTPass(curvature float32) *PostProcessPass {
+	return &PostProcessPass{
+		Fragment: PostProcessFragmentHeader + `
+uniform float uf_Curvature;
+uniform float uf_Scanlines;
+
+void main() {
+  vec2 uv = var_TexCoords * 2.0 - 1.0;
+  uv *= 1.0 + uf_Curvature * dot(uv.yx, uv.yx);
+  uv = uv * 0.5 + 0.5;
+  if (uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0) {
+    gl_FragColor = vec4(0.0, 0.0, 0.0, 1.0);
+    return;
+  }
+
+  vec2 shift = vec2(1.0 / uf_Resolution.x, 0.0);
+  vec4 color = texture2D(uf_Texture, uv);
+  color.r = texture2D(uf_Texture, uv + shift).r;
+  color.b = texture2D(uf_Texture, uv - shift).b;
+
+  float scanline = 1.0 - uf_Scanlines * (0.5 + 0.5 * sin(uv.y * uf_Resolution.y * 3.14159));
+  gl_FragColor = vec4(color.rgb * scanline, color.a);
+}
+`,
+		Uniforms: Uniforms{
+			"uf_Curvature": UniformFloat(curvature),
+			"uf_Scanlines": UniformFloat(0.25),
+		},
+	}
+}
+
+// NewColorGradingPass returns a pass which adjusts the colors of the canvas. Its uniforms are uf_Brightness, which is
+// added to the colors, uf_Contrast and uf_Saturation, where 1 leaves the colors unchanged, and uf_Tint, a color the
+// colors are multiplied with, which is white by default.
+func NewColorGradingPass(brightness, contrast, saturation float32) *PostProcessPass {
+	return &PostProcessPass{
+		Fragment: PostProcessFragmentHeader + `
+uniform float uf_Brightness;
+uniform float uf_Contrast;
+uniform float uf_Saturation;
+uniform vec4 uf_Tint;
+
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  vec3 rgb = color.rgb + uf_Brightness;
+  rgb = (rgb - 0.5) * uf_Contrast + 0.5;
+  float luma = dot(rgb, vec3(0.299, 0.587, 0.114));
+  rgb = mix(vec3(luma), rgb, uf_Saturation);
+  gl_FragColor = vec4(clamp(rgb * uf_Tint.rgb, 0.0, 1.0), color.a);
+}
+`,
+		Uniforms: Uniforms{
+			"uf_Brightness": UniformFloat(brightness),
+			"uf_Contrast":   UniformFloat(contrast),
+			"uf_Saturation": UniformFloat(saturation),
+			"uf_Tint":       UniformColor(color.White),
+		},
+	}
+}
+
+const postProcessBlurFragment = PostProcessFragmentHeader + `
+uniform vec2 uf_Direction;
+
+void main() {
+  vec2 offset = uf_Direction / uf_Resolution;
+  vec4 color = texture2D(uf_Texture, var_TexCoords) * 0.227027;
+  color += texture2D(uf_Texture, var_TexCoords + offset * 1.384615) * 0.316216;
+  color += texture2D(uf_Texture, var_TexCoords - offset * 1.384615) * 0.316216;
+  color += texture2D(uf_Texture, var_TexCoords + offset * 3.230769) * 0.070270;
+  color += texture2D(uf_Texture, var_TexCoords - offset * 3.230769) * 0.070270;
+  gl_FragColor = color;
+}
+`
+
+// NewBlurPasses returns two passes which blur the canvas horizontally and vertically. Their uniform uf_Direction is
+// the distance between the samples in pixels, along the direction of the pass, so radius is about 3 times that
+// distance.
+func NewBlurPasses(radius float32) []*PostProcessPass {
+	return []*PostProcessPass{
+		{Fragment: postProcessBlurFragment, Uniforms: Uniforms{"uf_Direction": UniformVec2(radius/3, 0)}},
+		{Fragment: postProcessBlurFragment, Uniforms: Uniforms{"uf_Direction": UniformVec2(0, radius/3)}},
+	}
+}
+
+// NewBloomPasses returns the passes which make the bright parts of the canvas glow. The first pass keeps the parts
+// brighter than the threshold, which are blurred by the next two passes and added to the scene with the given
+// intensity by the last pass. As that is the scene before any pass, the bloom passes should come first.
+func NewBloomPasses(threshold, intensity float32) []*PostProcessPass {
+	extract := &PostProcessPass{
+		Fragment: PostProcessFragmentHeader + `
+uniform float uf_Threshold;
+
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  float luma = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+  gl_FragColor = vec4(color.rgb * smoothstep(uf_Threshold, 1.0, luma), 1.0);
+}
+`,
+		Uniforms: Uniforms{"uf_Threshold": UniformFloat(threshold)},
+	}
+	combine := &PostProcessPass{
+		Fragment: PostProcessFragmentHeader + `
+uniform float uf_Intensity;
+
+void main() {
+  vec4 scene = texture2D(uf_Scene, var_TexCoords);
+  vec4 bloom = texture2D(uf_Texture, var_TexCoords);
+  gl_FragColor = vec4(scene.rgb + bloom.rgb * uf_Intensity, scene.a);
+}
+`,
+		Uniforms: Uniforms{"uf_Intensity": UniformFloat(intensity)},
+	}
+
+	passes := []*PostProcessPass{extract}
+	passes = append(passes, NewBlurPasses(8)...)
+	return append(passes, combine)
+}
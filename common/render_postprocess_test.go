@@ -0,0 +1,34 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostProcessPasses(t *testing.T) {
+	rs := &RenderSystem{}
+	vignette := NewVignettePass(0.5)
+	blur := NewBlurPasses(6)
+	rs.AddPostProcessPass(vignette)
+	rs.AddPostProcessPass(blur...)
+	assert.Equal(t, []*PostProcessPass{vignette, blur[0], blur[1]}, rs.PostProcessPasses())
+
+	blur[0].Disabled = true
+	assert.Equal(t, []*PostProcessPass{vignette, blur[1]}, rs.postProcess.active(), "Disabled passes should be skipped")
+
+	rs.RemovePostProcessPass(vignette)
+	assert.Equal(t, []*PostProcessPass{blur[0], blur[1]}, rs.PostProcessPasses())
+	rs.RemovePostProcessPass(vignette)
+	assert.Len(t, rs.PostProcessPasses(), 2, "Removing a pass which isn't in the chain should do nothing")
+
+	assert.Equal(t, UniformVec2(2, 0), blur[0].Uniforms["uf_Direction"])
+	assert.Equal(t, UniformVec2(0, 2), blur[1].Uniforms["uf_Direction"])
+
+	bloom := NewBloomPasses(0.8, 1.5)
+	if assert.Len(t, bloom, 4) {
+		assert.Equal(t, UniformFloat(0.8), bloom[0].Uniforms["uf_Threshold"])
+		assert.Equal(t, UniformFloat(1.5), bloom[3].Uniforms["uf_Intensity"])
+		assert.Contains(t, bloom[3].Fragment, "uf_Scene", "Bloom should be added to the scene")
+	}
+}
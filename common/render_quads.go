@@ -0,0 +1,150 @@
+package common
+
+import (
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// quadSpan is a part of a row or column of a sprite drawn with more than one quad, from and to positions in the
+// sprite, with the texture coordinates there.
+type quadSpan struct {
+	from, to, u, u2 float32
+}
+
+// tiles calls fn for the span, or, if tile is set, for each tile repeating the texture along the span at its size
+// in the source. With mirror, every other tile is flipped.
+func (s quadSpan) tiles(source float32, tile, mirror bool, fn func(quadSpan)) {
+	if s.to <= s.from {
+		return
+	}
+	if !tile || source <= 0 {
+		fn(s)
+		return
+	}
+	flipped := false
+	for from := s.from; from < s.to; from += source {
+		t := quadSpan{from: from, to: from + source, u: s.u, u2: s.u2}
+		if flipped {
+			t.u, t.u2 = s.u2, s.u
+		}
+		if t.to > s.to {
+			// The last tile is cut off where the span ends
+			t.u2 = t.u + (t.u2-t.u)*(s.to-from)/source
+			t.to = s.to
+		}
+		fn(t)
+		flipped = mirror && !flipped
+	}
+}
+
+// isRegion returns whether the Drawable is a part of its texture, such as a cell of a Spritesheet.
+func isRegion(d Drawable) bool {
+	u, v, u2, v2 := d.View()
+	return u != 0 || v != 0 || u2 != 1 || v2 != 1
+}
+
+// isMultiQuad returns whether the entity is drawn with more than one quad by the basicShader. These are NineSlices,
+// Particles, and regions of textures which are repeated, as the wrapping of OpenGL would repeat the whole texture.
+func isMultiQuad(ren *RenderComponent) bool {
+	switch ren.Drawable.(type) {
+	case NineSlice, Particles:
+		return true
+	}
+	return (ren.Repeat == Repeat || ren.Repeat == MirroredRepeat) && isRegion(ren.Drawable)
+}
+
+// drawnSize returns the size an entity drawn with more than one quad is drawn at, which is the size of its
+// SpaceComponent before the Scale is applied.
+func drawnSize(ren *RenderComponent, space *SpaceComponent) (float32, float32) {
+	width, height := space.Width, space.Height
+	if ren.Scale.X != 0 {
+		width /= math32.Abs(ren.Scale.X)
+	}
+	if ren.Scale.Y != 0 {
+		height /= math32.Abs(ren.Scale.Y)
+	}
+	return width, height
+}
+
+// quads calls fn for each quad drawing an entity for which isMultiQuad is true.
+func quads(ren *RenderComponent, space *SpaceComponent, fn func(x, y quadSpan)) {
+	width, height := drawnSize(ren, space)
+	if slice, ok := ren.Drawable.(NineSlice); ok {
+		slice.quads(width, height, fn)
+		return
+	}
+
+	// The region is repeated over the entity, starting at the top left
+	u, v, u2, v2 := ren.Drawable.View()
+	mirror := ren.Repeat == MirroredRepeat
+	quadSpan{from: 0, to: height, u: v, u2: v2}.tiles(ren.Drawable.Height(), true, mirror, func(y quadSpan) {
+		quadSpan{from: 0, to: width, u: u, u2: u2}.tiles(ren.Drawable.Width(), true, mirror, func(x quadSpan) {
+			fn(x, y)
+		})
+	})
+}
+
+// countQuads returns the number of quads the basicShader draws for the entity.
+func (s *basicShader) countQuads(ren *RenderComponent, space *SpaceComponent) int {
+	if !isMultiQuad(ren) {
+		return 1
+	}
+	if particles, ok := ren.Drawable.(Particles); ok {
+		return len(particles.Component.Particles())
+	}
+	count := 0
+	quads(ren, space, func(x, y quadSpan) { count++ })
+	return count
+}
+
+// generateQuads fills the buffer with the countQuads quads drawing the entity.
+func (s *basicShader) generateQuads(ren *RenderComponent, space *SpaceComponent, buffer []float32) {
+	if !isMultiQuad(ren) {
+		s.generateBufferContent(ren, space, buffer)
+		return
+	}
+	if particles, ok := ren.Drawable.(Particles); ok {
+		particles.generateQuads(s, ren, buffer)
+		return
+	}
+
+	tint := colorToFloat32(ren.Color)
+	modelMatrix := s.makeModelMatrix(ren, space)
+	offset := 0
+	quads(ren, space, func(x, y quadSpan) {
+		quad := buffer[offset : offset+spriteSize]
+		copy(quad, []float32{
+			x.from, y.from, x.u, y.u, tint,
+			x.to, y.from, x.u2, y.u, tint,
+			x.to, y.to, x.u2, y.u2, tint,
+			x.from, y.to, x.u, y.u2, tint,
+		})
+		for i := 0; i < spriteSize; i += 5 {
+			s.multModel(modelMatrix, quad[i:i+2])
+		}
+		offset += spriteSize
+	})
+}
+
+// drawQuads adds the quads of an entity drawn with more than one quad to the batch, flushing it whenever it's full.
+func (s *basicShader) drawQuads(ren *RenderComponent, space *SpaceComponent) {
+	size := s.countQuads(ren, space) * spriteSize
+	if cap(s.quadBuffer) < size {
+		s.quadBuffer = make([]float32, size)
+	}
+	buffer := s.quadBuffer[:size]
+	s.generateQuads(ren, space, buffer)
+
+	for len(buffer) > 0 {
+		if s.idx == len(s.vertices) {
+			s.flush()
+		}
+		ren.Buffer = s.vertexBuffer
+		n := len(s.vertices) - s.idx
+		if n > len(buffer) {
+			n = len(buffer)
+		}
+		ren.BufferContent = s.vertices[s.idx : s.idx+n]
+		s.add(buffer[:n])
+		buffer = buffer[n:]
+	}
+}
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuadSpanTiles(t *testing.T) {
+	var tiles []quadSpan
+	quadSpan{from: 0, to: 5, u: 0.25, u2: 0.5}.tiles(2, true, true, func(s quadSpan) {
+		tiles = append(tiles, s)
+	})
+	assert.Equal(t, []quadSpan{
+		{from: 0, to: 2, u: 0.25, u2: 0.5},
+		{from: 2, to: 4, u: 0.5, u2: 0.25},
+		{from: 4, to: 5, u: 0.25, u2: 0.375},
+	}, tiles, "Every other tile should be mirrored, and the last one cut off")
+
+	tiles = nil
+	quadSpan{from: 0, to: 5, u: 0.25, u2: 0.5}.tiles(2, false, false, func(s quadSpan) {
+		tiles = append(tiles, s)
+	})
+	assert.Equal(t, []quadSpan{{from: 0, to: 5, u: 0.25, u2: 0.5}}, tiles, "The span should be stretched without tiling")
+}
+
+func TestRepeatBufferContent(t *testing.T) {
+	s := &basicShader{modelMatrix: tango.IdentityMatrix()}
+	ren := &RenderComponent{
+		Drawable: Texture{width: 4, height: 2, viewport: tango.AABB{Max: tango.Point{X: 1, Y: 1}}},
+		Scale:    tango.Point{X: 1, Y: 1},
+		Color:    color.White,
+		Repeat:   Repeat,
+	}
+	space := &SpaceComponent{Width: 8, Height: 8}
+	assert.False(t, isMultiQuad(ren), "A whole texture should be repeated by OpenGL")
+	assert.Equal(t, 1, s.countQuads(ren, space))
+
+	buffer := make([]float32, spriteSize)
+	s.generateBufferContent(ren, space, buffer)
+	assert.Equal(t, []float32{8, 8, 2, 4}, buffer[10:14], "The texture should be repeated over the width and height of the SpaceComponent")
+
+	ren.Drawable = Texture{width: 4, height: 2, viewport: tango.AABB{Max: tango.Point{X: 0.5, Y: 1}}}
+	assert.True(t, isMultiQuad(ren), "A region should be repeated with a quad for each time")
+	assert.Equal(t, 2*4, s.countQuads(ren, space))
+}
+
+func TestRepeatSoftwareRendering(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	red, green, blue := color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{G: 0xff, A: 0xff}, color.NRGBA{B: 0xff, A: 0xff}
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		img.SetNRGBA(0, y, red)
+		img.SetNRGBA(1, y, green)
+		img.SetNRGBA(2, y, blue)
+		img.SetNRGBA(3, y, blue)
+	}
+	texture := NewTextureSingle(NewImageObject(img))
+	defer texture.Close()
+	sheet := NewSpritesheetFromTexture(&TextureResource{Texture: texture.Texture(), Width: 4, Height: 2}, 2, 2)
+
+	render := addSoftwareTestEntity(rs, sheet.Cell(0), 10, 10, 7, 4)
+	render.Repeat = Repeat
+	w.Update(1)
+	for i, c := range []color.RGBA{{R: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {R: 0xff, A: 0xff}, {G: 0xff, A: 0xff},
+		{R: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {R: 0xff, A: 0xff}, {A: 0xff}} {
+		assertSoftwarePixel(t, c, 10+i, 13, "The cell should be repeated without the rest of the spritesheet, at %d", i)
+	}
+
+	render.Repeat = MirroredRepeat
+	w.Update(1)
+	for i, c := range []color.RGBA{{R: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {R: 0xff, A: 0xff},
+		{R: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {A: 0xff}} {
+		assertSoftwarePixel(t, c, 10+i, 10, "Every other time the cell is repeated, it should be mirrored, at %d", i)
+	}
+}
@@ -84,12 +84,19 @@ type CullingShader interface {
 type basicShader struct {
 	BatchSize int
 
+	context     batchContext
 	indices     []uint16
 	indexBuffer *gl.Buffer
 	program     *gl.Program
 
+	// vertices and vertexBuffer are those of the current batch, which is filled from start to idx
+	batches                      []*spriteBatch
+	batch                        int
 	vertices                     []float32
 	vertexBuffer                 *gl.Buffer
+	start                        int
+	changed                      bool
+	quadBuffer                   []float32
 	lastTexture                  *gl.Texture
 	lastRepeating                TextureRepeating
 	lastMagFilter, lastMinFilter ZoomFilter
@@ -115,15 +122,18 @@ type basicShader struct {
 }
 
 func (s *basicShader) Setup(w *ecs.World) error {
+	return s.setup(defaultVertexShader, defaultFragmentShader)
+}
+
+// setup prepares the batching of sprites drawn with the given vertex and fragment shader
+func (s *basicShader) setup(vertexShader, fragmentShader string) error {
 	if s.BatchSize > MaxSprites {
 		return fmt.Errorf("%d is greater than the maximum batch size of %d", s.BatchSize, MaxSprites)
 	}
 	if s.BatchSize <= 0 {
 		s.BatchSize = MaxSprites
 	}
-	// Create the vertex buffer for batching.
-	s.vertices = make([]float32, s.BatchSize*spriteSize)
-	s.vertexBuffer = tango.Gl.CreateBuffer()
+	s.context = tango.Gl
 	// Create and populate indices buffer. The size of the buffer depends on the batch size.
 	// These should never change, so we can just initialize them once here and be done with it.
 	numIndicies := s.BatchSize * 6
@@ -137,7 +147,7 @@ func (s *basicShader) Setup(w *ecs.World) error {
 		s.indices[i+5] = uint16(j + 3)
 	}
 	var err error
-	s.program, err = LoadShader(defaultVertexShader, defaultFragmentShader)
+	s.program, err = LoadShader(vertexShader, fragmentShader)
 	if err != nil {
 		return err
 	}
@@ -158,26 +168,28 @@ func (s *basicShader) Setup(w *ecs.World) error {
 	s.cullingMatrix = tango.IdentityMatrix()
 
 	s.setTexture(nil)
+	// Create the vertex buffer for batching.
+	s.useBatch(0)
 
 	return nil
 }
 
 func (s *basicShader) Pre() {
-	tango.Gl.Enable(tango.Gl.BLEND)
-	tango.Gl.BlendFunc(tango.Gl.SRC_ALPHA, tango.Gl.ONE_MINUS_SRC_ALPHA)
+	s.context.Enable(tango.Gl.BLEND)
+	s.context.BlendFunc(tango.Gl.SRC_ALPHA, tango.Gl.ONE_MINUS_SRC_ALPHA)
 	// Enable shader and buffer, enable attributes in shader
-	tango.Gl.UseProgram(s.program)
-	tango.Gl.BindBuffer(tango.Gl.ELEMENT_ARRAY_BUFFER, s.indexBuffer)
-	tango.Gl.EnableVertexAttribArray(s.inPosition)
-	tango.Gl.EnableVertexAttribArray(s.inTexCoords)
-	tango.Gl.EnableVertexAttribArray(s.inColor)
+	s.context.UseProgram(s.program)
+	s.context.BindBuffer(tango.Gl.ELEMENT_ARRAY_BUFFER, s.indexBuffer)
+	s.context.EnableVertexAttribArray(s.inPosition)
+	s.context.EnableVertexAttribArray(s.inTexCoords)
+	s.context.EnableVertexAttribArray(s.inColor)
 
-	tango.Gl.Enable(tango.Gl.POLYGON_SMOOTH)
-	tango.Gl.Enable(tango.Gl.LINE_SMOOTH)
+	s.context.Enable(tango.Gl.POLYGON_SMOOTH)
+	s.context.Enable(tango.Gl.LINE_SMOOTH)
 
-	tango.Gl.Hint(tango.Gl.TEXTURE_COMPRESSION_HINT, tango.Gl.NICEST)
-	tango.Gl.Hint(tango.Gl.POLYGON_SMOOTH_HINT, tango.Gl.NICEST)
-	tango.Gl.Hint(tango.Gl.LINE_SMOOTH_HINT, tango.Gl.NICEST)
+	s.context.Hint(tango.Gl.TEXTURE_COMPRESSION_HINT, tango.Gl.NICEST)
+	s.context.Hint(tango.Gl.POLYGON_SMOOTH_HINT, tango.Gl.NICEST)
+	s.context.Hint(tango.Gl.LINE_SMOOTH_HINT, tango.Gl.NICEST)
 
 	// The matrixProjView shader uniform is projection * view.
 	// We do the multiplication on the CPU instead of sending each matrix to the shader and letting the GPU do the multiplication,
@@ -187,29 +199,21 @@ func (s *basicShader) Pre() {
 		s.projViewMatrix = s.projectionMatrix.Multiply(s.viewMatrix)
 		s.projViewChange = false
 	}
-	tango.Gl.UniformMatrix3fv(s.matrixProjView, false, s.projViewMatrix.Val[:])
-
-	// Since we are batching client side, we only have one VBO, so we can just bind it now and use it for the entire frame.
-	tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, s.vertexBuffer)
-	tango.Gl.VertexAttribPointer(s.inPosition, 2, tango.Gl.FLOAT, false, 20, 0)
-	tango.Gl.VertexAttribPointer(s.inTexCoords, 2, tango.Gl.FLOAT, false, 20, 8)
-	tango.Gl.VertexAttribPointer(s.inColor, 4, tango.Gl.UNSIGNED_BYTE, true, 20, 16)
+	s.context.UniformMatrix3fv(s.matrixProjView, false, s.projViewMatrix.Val[:])
 }
 
 func (s *basicShader) PrepareCulling() {
 	s.projViewChange = true
 	// (Re)initialize the projection matrix.
 	s.projectionMatrix.Identity()
-	if tango.ScaleOnResize() {
-		s.projectionMatrix.Scale(1/(tango.GameWidth()/2), 1/(-tango.GameHeight()/2))
-	} else {
-		s.projectionMatrix.Scale(1/(tango.CanvasWidth()/(2*tango.CanvasScale())), 1/(-tango.CanvasHeight()/(2*tango.CanvasScale())))
-	}
+	width, height := projectionSize(s.camera, s.cameraEnabled)
+	s.projectionMatrix.Scale(1/(width/2), 1/(-height/2))
 	// (Re)initialize the view matrix
 	s.viewMatrix.Identity()
 	if s.cameraEnabled {
+		x, y, angle := s.camera.view()
 		s.viewMatrix.Scale(1/s.camera.z, 1/s.camera.z)
-		s.viewMatrix.Translate(-s.camera.x, -s.camera.y).Rotate(s.camera.angle)
+		s.viewMatrix.Translate(-x, -y).Rotate(angle)
 	} else {
 		scaleX, scaleY := s.projectionMatrix.ScaleComponent()
 		s.viewMatrix.Translate(-1/scaleX, 1/scaleY)
@@ -220,11 +224,16 @@ func (s *basicShader) PrepareCulling() {
 }
 
 func (s *basicShader) ShouldDraw(rc *RenderComponent, sc *SpaceComponent) bool {
-	tsc := SpaceComponent{
-		Position: sc.Position,
-		Width:    rc.Drawable.Width() * rc.Scale.X,
-		Height:   rc.Drawable.Height() * rc.Scale.Y,
-		Rotation: sc.Rotation,
+	var tsc SpaceComponent
+	if particles, ok := rc.Drawable.(Particles); ok {
+		// Particles are drawn wherever they went, in world coordinates
+		if len(particles.Component.Particles()) == 0 {
+			return false
+		}
+		bounds := particles.bounds(rc.Scale)
+		tsc = SpaceComponent{Position: bounds.Min, Width: bounds.Max.X - bounds.Min.X, Height: bounds.Max.Y - bounds.Min.Y}
+	} else {
+		tsc = spriteArea(rc, sc)
 	}
 
 	c := tsc.Corners()
@@ -243,9 +252,10 @@ func (s *basicShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 	// If our texture (or any of its properties) has changed or we've reached the end of our buffer, flush before moving on.
 	if s.lastTexture != ren.Drawable.Texture() {
 		s.flush()
-		tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, ren.Drawable.Texture())
+		s.context.BindTexture(tango.Gl.TEXTURE_2D, ren.Drawable.Texture())
 		s.setTexture(ren.Drawable.Texture())
-	} else if s.idx == len(s.vertices) {
+	}
+	if s.idx == len(s.vertices) {
 		s.flush()
 	}
 
@@ -260,8 +270,8 @@ func (s *basicShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 		case MirroredRepeat:
 			val = tango.Gl.MIRRORED_REPEAT
 		}
-		tango.Gl.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_WRAP_S, val)
-		tango.Gl.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_WRAP_T, val)
+		s.context.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_WRAP_S, val)
+		s.context.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_WRAP_T, val)
 
 		s.lastRepeating = ren.Repeat
 	}
@@ -275,7 +285,7 @@ func (s *basicShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 		case FilterLinear:
 			val = tango.Gl.LINEAR
 		}
-		tango.Gl.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_MAG_FILTER, val)
+		s.context.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_MAG_FILTER, val)
 
 		s.lastMagFilter = ren.magFilter
 	}
@@ -289,14 +299,17 @@ func (s *basicShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 		case FilterLinear:
 			val = tango.Gl.LINEAR_MIPMAP_LINEAR
 		}
-		tango.Gl.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_MIN_FILTER, val)
+		s.context.TexParameteri(tango.Gl.TEXTURE_2D, tango.Gl.TEXTURE_MIN_FILTER, val)
 
 		s.lastMinFilter = ren.minFilter
 	}
 
 	// Update the vertex buffer data.
+	if isMultiQuad(ren) {
+		s.drawQuads(ren, space)
+		return
+	}
 	s.updateBuffer(ren, space)
-	s.idx += 20
 }
 
 func (s *basicShader) Post() {
@@ -304,15 +317,15 @@ func (s *basicShader) Post() {
 	s.setTexture(nil)
 
 	// Cleanup
-	tango.Gl.DisableVertexAttribArray(s.inPosition)
-	tango.Gl.DisableVertexAttribArray(s.inTexCoords)
-	tango.Gl.DisableVertexAttribArray(s.inColor)
+	s.context.DisableVertexAttribArray(s.inPosition)
+	s.context.DisableVertexAttribArray(s.inTexCoords)
+	s.context.DisableVertexAttribArray(s.inColor)
 
-	tango.Gl.BindTexture(tango.Gl.TEXTURE_2D, nil)
-	tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, nil)
-	tango.Gl.BindBuffer(tango.Gl.ELEMENT_ARRAY_BUFFER, nil)
+	s.context.BindTexture(tango.Gl.TEXTURE_2D, nil)
+	s.context.BindBuffer(tango.Gl.ARRAY_BUFFER, nil)
+	s.context.BindBuffer(tango.Gl.ELEMENT_ARRAY_BUFFER, nil)
 
-	tango.Gl.Disable(tango.Gl.BLEND)
+	s.context.Disable(tango.Gl.BLEND)
 }
 
 // setTexture resets all last* values from basicShader to a new default value (255)
@@ -323,31 +336,42 @@ func (s *basicShader) setTexture(texture *gl.Texture) {
 	s.lastRepeating = 255
 }
 
+// flush draws the sprites added to the batch since the last flush. They're only uploaded if they differ from what the
+// vertex buffer already holds, which is what was drawn at the same place of the batch during the previous frame.
 func (s *basicShader) flush() {
 	// If we haven't rendered anything yet, no point in flushing.
-	if s.idx == 0 {
+	if s.idx == s.start {
 		return
 	}
-	tango.Gl.BufferData(tango.Gl.ARRAY_BUFFER, s.vertices, tango.Gl.STATIC_DRAW)
-	// We only want to draw the indicies up to the number of sprites in the current batch.
-	count := s.idx / 20 * 6
-	tango.Gl.DrawElements(tango.Gl.TRIANGLES, count, tango.Gl.UNSIGNED_SHORT, 0)
-	s.idx = 0
-	// We need to reset the vertex buffer so that when we start drawing again, we don't accidentally use junk data.
-	// The "simpler" way to do this would be to just create a new slice with make(), however that would cause the
-	// previous slice to be marked for garbage collection and we'd prefer to keep the GC activity to a minimum.
-	for i := range s.vertices {
-		s.vertices[i] = 0
+	batch := s.batches[s.batch]
+	s.context.BindBuffer(tango.Gl.ARRAY_BUFFER, s.vertexBuffer)
+	s.context.VertexAttribPointer(s.inPosition, 2, tango.Gl.FLOAT, false, 20, 0)
+	s.context.VertexAttribPointer(s.inTexCoords, 2, tango.Gl.FLOAT, false, 20, 8)
+	s.context.VertexAttribPointer(s.inColor, 4, tango.Gl.UNSIGNED_BYTE, true, 20, 16)
+	if s.dirty() {
+		s.context.BufferSubData(tango.Gl.ARRAY_BUFFER, s.start*4, s.vertices[s.start:s.idx])
+		if s.idx > batch.uploaded {
+			batch.uploaded = s.idx
+		}
+	}
+
+	// We only want to draw the indices of the sprites added since the last flush.
+	count := (s.idx - s.start) / spriteSize * 6
+	s.context.DrawElements(tango.Gl.TRIANGLES, count, tango.Gl.UNSIGNED_SHORT, s.start/spriteSize*6*2)
+	s.start = s.idx
+	s.changed = false
+	if s.idx == len(s.vertices) {
+		s.useBatch(s.batch + 1)
 	}
 }
 
 func (s *basicShader) updateBuffer(ren *RenderComponent, space *SpaceComponent) {
 	// For backwards compatibility, ren.Buffer is set to the VBO and ren.BufferContent
 	// is set to the slice of the vertex buffer for the current sprite. This same slice is
-	// populated with vertex data via generateBufferContent.
+	// populated with the vertex data cached in the RenderComponent.
 	ren.Buffer = s.vertexBuffer
-	ren.BufferContent = s.vertices[s.idx : s.idx+20]
-	s.generateBufferContent(ren, space, ren.BufferContent)
+	ren.BufferContent = s.vertices[s.idx : s.idx+spriteSize]
+	s.add(s.cachedVertices(ren, space))
 }
 
 func (s *basicShader) makeModelMatrix(ren *RenderComponent, space *SpaceComponent) *tango.Matrix {
@@ -375,20 +399,20 @@ func (s *basicShader) generateBufferContent(ren *RenderComponent, space *SpaceCo
 	if ren.Repeat != NoRepeat {
 		u2 = space.Width / (ren.Drawable.Width() * ren.Scale.X)
 		w *= u2
-		v2 = space.Width / (ren.Drawable.Height() * ren.Scale.Y)
+		v2 = space.Height / (ren.Drawable.Height() * ren.Scale.Y)
 		h *= v2
 	}
 
 	var changed bool
 
-	//setBufferValue(buffer, 0, 0, &changed)
-	//setBufferValue(buffer, 1, 0, &changed)
+	setBufferValue(buffer, 0, 0, &changed)
+	setBufferValue(buffer, 1, 0, &changed)
 	setBufferValue(buffer, 2, u, &changed)
 	setBufferValue(buffer, 3, v, &changed)
 	setBufferValue(buffer, 4, tint, &changed)
 
 	setBufferValue(buffer, 5, w, &changed)
-	//setBufferValue(buffer, 6, 0, &changed)
+	setBufferValue(buffer, 6, 0, &changed)
 	setBufferValue(buffer, 7, u2, &changed)
 	setBufferValue(buffer, 8, v, &changed)
 	setBufferValue(buffer, 9, tint, &changed)
@@ -399,7 +423,7 @@ func (s *basicShader) generateBufferContent(ren *RenderComponent, space *SpaceCo
 	setBufferValue(buffer, 13, v2, &changed)
 	setBufferValue(buffer, 14, tint, &changed)
 
-	//setBufferValue(buffer, 15, 0, &changed)
+	setBufferValue(buffer, 15, 0, &changed)
 	setBufferValue(buffer, 16, h, &changed)
 	setBufferValue(buffer, 17, u, &changed)
 	setBufferValue(buffer, 18, v2, &changed)
@@ -427,7 +451,8 @@ func (s *basicShader) SetCamera(c *CameraSystem) {
 	s.projViewChange = true
 	if s.cameraEnabled {
 		s.camera = c
-		s.viewMatrix.Identity().Translate(-s.camera.x, -s.camera.y).Rotate(s.camera.angle)
+		x, y, angle := s.camera.view()
+		s.viewMatrix.Identity().Translate(-x, -y).Rotate(angle)
 	} else {
 		scaleX, scaleY := s.projectionMatrix.ScaleComponent()
 		s.viewMatrix.Translate(-1/scaleX, 1/scaleY)
@@ -540,20 +565,17 @@ func (l *legacyShader) Pre() {
 	tango.Gl.EnableVertexAttribArray(l.inPosition)
 	tango.Gl.EnableVertexAttribArray(l.inColor)
 
-	if tango.ScaleOnResize() {
-		l.projectionMatrix[0] = 1 / (tango.GameWidth() / 2)
-		l.projectionMatrix[4] = 1 / (-tango.GameHeight() / 2)
-	} else {
-		l.projectionMatrix[0] = 1 / (tango.CanvasWidth() / (2 * tango.CanvasScale()))
-		l.projectionMatrix[4] = 1 / (-tango.CanvasHeight() / (2 * tango.CanvasScale()))
-	}
+	width, height := projectionSize(l.camera, l.cameraEnabled)
+	l.projectionMatrix[0] = 1 / (width / 2)
+	l.projectionMatrix[4] = 1 / (-height / 2)
 
 	if l.cameraEnabled {
-		l.viewMatrix[1], l.viewMatrix[0] = math32.Sincos(l.camera.angle * math32.Pi / 180)
+		x, y, angle := l.camera.view()
+		l.viewMatrix[1], l.viewMatrix[0] = math32.Sincos(angle * math32.Pi / 180)
 		l.viewMatrix[3] = -l.viewMatrix[1]
 		l.viewMatrix[4] = l.viewMatrix[0]
-		l.viewMatrix[6] = -l.camera.x
-		l.viewMatrix[7] = -l.camera.y
+		l.viewMatrix[6] = -x
+		l.viewMatrix[7] = -y
 		l.viewMatrix[8] = l.camera.z
 	} else {
 		l.viewMatrix[6] = -1 / l.projectionMatrix[0]
@@ -565,11 +587,19 @@ func (l *legacyShader) Pre() {
 }
 
 func (l *legacyShader) updateBuffer(ren *RenderComponent, space *SpaceComponent) {
-	if len(ren.BufferContent) == 0 {
-		ren.BufferContent = make([]float32, l.computeBufferSize(ren.Drawable)) // because we add at most this many elements to it
-	}
-	if changed := l.generateBufferContent(ren, space, ren.BufferContent); !changed {
-		return
+	if shape, ok := ren.Drawable.(vectorShape); ok {
+		// The number of triangles of vector shapes depends on their size, so the buffer is resized to fit them
+		var changed bool
+		if ren.BufferContent, changed = vectorBufferContent(shape, ren, space, ren.BufferContent); !changed || len(ren.BufferContent) == 0 {
+			return
+		}
+	} else {
+		if len(ren.BufferContent) == 0 {
+			ren.BufferContent = make([]float32, l.computeBufferSize(ren.Drawable)) // because we add at most this many elements to it
+		}
+		if changed := l.generateBufferContent(ren, space, ren.BufferContent); !changed {
+			return
+		}
 	}
 
 	if ren.Buffer == nil {
@@ -828,6 +858,10 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 func (l *legacyShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 	if l.lastBuffer != ren.Buffer || ren.Buffer == nil {
 		l.updateBuffer(ren, space)
+		if ren.Buffer == nil {
+			// Nothing to draw, such as a vector shape without any triangles
+			return
+		}
 
 		tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, ren.Buffer)
 		tango.Gl.VertexAttribPointer(l.inPosition, 2, tango.Gl.FLOAT, false, 12, 0)
@@ -885,6 +919,8 @@ func (l *legacyShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 			tango.Gl.LineWidth(borderWidth)
 			tango.Gl.DrawArrays(tango.Gl.LINE_LOOP, len(shape.Points), len(shape.Points))
 		}
+	case vectorShape:
+		tango.Gl.DrawArrays(tango.Gl.TRIANGLES, 0, len(ren.BufferContent)/3)
 	default:
 		unsupportedType(ren.Drawable)
 	}
@@ -1027,20 +1063,17 @@ func (l *textShader) Pre() {
 	tango.Gl.EnableVertexAttribArray(l.inTexCoords)
 	tango.Gl.EnableVertexAttribArray(l.inColor)
 
-	if tango.ScaleOnResize() {
-		l.projectionMatrix[0] = 1 / (tango.GameWidth() / 2)
-		l.projectionMatrix[4] = 1 / (-tango.GameHeight() / 2)
-	} else {
-		l.projectionMatrix[0] = 1 / (tango.CanvasWidth() / (2 * tango.CanvasScale()))
-		l.projectionMatrix[4] = 1 / (-tango.CanvasHeight() / (2 * tango.CanvasScale()))
-	}
+	width, height := projectionSize(l.camera, l.cameraEnabled)
+	l.projectionMatrix[0] = 1 / (width / 2)
+	l.projectionMatrix[4] = 1 / (-height / 2)
 
 	if l.cameraEnabled {
-		l.viewMatrix[1], l.viewMatrix[0] = math32.Sincos(l.camera.angle * math32.Pi / 180)
+		x, y, angle := l.camera.view()
+		l.viewMatrix[1], l.viewMatrix[0] = math32.Sincos(angle * math32.Pi / 180)
 		l.viewMatrix[3] = -l.viewMatrix[1]
 		l.viewMatrix[4] = l.viewMatrix[0]
-		l.viewMatrix[6] = -l.camera.x
-		l.viewMatrix[7] = -l.camera.y
+		l.viewMatrix[6] = -x
+		l.viewMatrix[7] = -y
 		l.viewMatrix[8] = l.camera.z
 	} else {
 		l.viewMatrix[6] = -1 / l.projectionMatrix[0]
@@ -1150,6 +1183,10 @@ func (l *textShader) generateBufferContent(ren *RenderComponent, space *SpaceCom
 func (l *textShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 	if l.lastBuffer != ren.Buffer || ren.Buffer == nil {
 		l.updateBuffer(ren, space)
+		if ren.Buffer == nil {
+			// Nothing to draw, such as an empty Text, for which no buffer was created yet
+			return
+		}
 
 		tango.Gl.BindBuffer(tango.Gl.ARRAY_BUFFER, ren.Buffer)
 		tango.Gl.VertexAttribPointer(l.inPosition, 2, tango.Gl.FLOAT, false, 20, 0)
@@ -1254,6 +1291,8 @@ var (
 	TextHUDShader = &textShader{cameraEnabled: false}
 
 	BlendmapShader = &blendmapShader{cameraEnabled: true}
+	// LightMapShader is the shader used to draw the light map of the LightSystem.
+	LightMapShader = &lightMapShader{basicShader{cameraEnabled: true}}
 	shadersSet     bool
 	atlasCache     = make(map[Font]FontAtlas)
 	shaders        = []Shader{
@@ -1264,6 +1303,7 @@ var (
 		TextShader,
 		TextHUDShader,
 		BlendmapShader,
+		LightMapShader,
 	}
 )
 
@@ -1326,13 +1366,7 @@ func LoadShader(vertSrc, fragSrc string) (*gl.Program, error) {
 func newCamera(w *ecs.World) {
 	shaderInitMutex.Lock()
 	defer shaderInitMutex.Unlock()
-	var cam *CameraSystem
-	for _, system := range w.Systems() {
-		switch sys := system.(type) {
-		case *CameraSystem:
-			cam = sys
-		}
-	}
+	cam := mainCamera(w)
 	if cam == nil {
 		log.Println("Camera system was not found when changing scene!")
 		return
@@ -1342,6 +1376,26 @@ func newCamera(w *ecs.World) {
 	}
 }
 
+// setShadersCamera makes all shaders render through the given camera
+func setShadersCamera(cam *CameraSystem) {
+	shaderInitMutex.Lock()
+	defer shaderInitMutex.Unlock()
+	for _, shader := range shaders {
+		shader.SetCamera(cam)
+	}
+}
+
+// beginShadersFrame makes the shaders batching sprites start again from their first batch
+func beginShadersFrame() {
+	shaderInitMutex.Lock()
+	defer shaderInitMutex.Unlock()
+	for _, shader := range shaders {
+		if b, ok := shader.(batchingShader); ok {
+			b.beginFrame()
+		}
+	}
+}
+
 // VertexShaderCompilationError is returned whenever the `LoadShader` method was unable to compile your Vertex-shader (GLSL)
 type VertexShaderCompilationError struct {
 	OpenGLError string
@@ -221,16 +221,14 @@ func (s *blendmapShader) Pre() {
 func (s *blendmapShader) PrepareCulling() {
 	// (Re)initialize the projection matrix.
 	s.projectionMatrix.Identity()
-	if tango.ScaleOnResize() {
-		s.projectionMatrix.Scale(1/(tango.GameWidth()/2), 1/(-tango.GameHeight()/2))
-	} else {
-		s.projectionMatrix.Scale(1/(tango.CanvasWidth()/(2*tango.CanvasScale())), 1/(-tango.CanvasHeight()/(2*tango.CanvasScale())))
-	}
+	width, height := viewportSize(s.camera)
+	s.projectionMatrix.Scale(1/(width/2), 1/(-height/2))
 	// (Re)initialize the view matrix
 	s.viewMatrix.Identity()
 	if s.cameraEnabled {
+		x, y, angle := s.camera.view()
 		s.viewMatrix.Scale(1/s.camera.z, 1/s.camera.z)
-		s.viewMatrix.Translate(-s.camera.x, -s.camera.y).Rotate(s.camera.angle)
+		s.viewMatrix.Translate(-x, -y).Rotate(angle)
 	} else {
 		scaleX, scaleY := s.projectionMatrix.ScaleComponent()
 		s.viewMatrix.Translate(-1/scaleX, 1/scaleY)
@@ -490,7 +488,8 @@ func (s *blendmapShader) multModel(m *tango.Matrix, v []float32) {
 func (s *blendmapShader) SetCamera(c *CameraSystem) {
 	if s.cameraEnabled {
 		s.camera = c
-		s.viewMatrix.Identity().Translate(-s.camera.x, -s.camera.y).Rotate(s.camera.angle)
+		x, y, angle := s.camera.view()
+		s.viewMatrix.Identity().Translate(-x, -y).Rotate(angle)
 	} else {
 		scaleX, scaleY := s.projectionMatrix.ScaleComponent()
 		s.viewMatrix.Translate(-1/scaleX, 1/scaleY)
@@ -90,3 +90,20 @@ this is incorrect GLSL syntax
 var incorrectFragShader = `
 this is incorrect GLSL syntax
 `
+
+// TestPostProcessCompilation tests whether the built-in post-processing passes compile.
+func TestPostProcessCompilation(t *testing.T) {
+	tango.Run(tango.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &testScene{})
+	tango.CreateWindow(&tango.RunOptions{Title: "Title", Width: 100, Height: 100, Fullscreen: true})
+	defer tango.DestroyWindow()
+
+	passes := []*PostProcessPass{NewVignettePass(1), NewCRTPass(0.1), NewColorGradingPass(0, 1, 1)}
+	passes = append(passes, NewBloomPasses(0.8, 1)...)
+	for _, pass := range passes {
+		_, err := LoadShader(PostProcessVertexShader, pass.Fragment)
+		assert.NoError(t, err)
+	}
+}
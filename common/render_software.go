@@ -0,0 +1,501 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// SoftwareRendering makes the RenderSystem draw with the CPU when running headless, into the image returned by
+// SoftwareCanvas, such as to compare the scene against an image in a test. It draws the same geometry as the
+// DefaultShader, LegacyShader and TextShader and their HUD variants, and the light map of the LightSystem. Materials
+// are drawn like the DefaultShader, other shaders aren't drawn at all, and neither are the Targets of cameras or
+// post-processing passes.
+//
+// It must be set before textures are created, as the pixels of textures are only kept in memory while it's set.
+var SoftwareRendering bool
+
+var (
+	// softwareTextures are the pixels of the textures created while SoftwareRendering is set
+	softwareTextures = make(map[*gl.Texture]*image.NRGBA)
+	softwareCanvas   *image.RGBA
+)
+
+// SoftwareCanvas returns the last frame drawn by the RenderSystem while SoftwareRendering is set, or nil if there is
+// none. The image is the size of the canvas, and is reused for the next frame.
+func SoftwareCanvas() *image.RGBA {
+	return softwareCanvas
+}
+
+// uploadSoftwareTexture keeps the pixels of the image in memory, in place of uploading them to the GPU
+func uploadSoftwareTexture(img Image) *gl.Texture {
+	id := new(gl.Texture)
+	softwareTextures[id] = ImageToNRGBA(img, img.Width(), img.Height())
+	return id
+}
+
+// softwareVertex is a vertex on the canvas, in pixels, with its texture coordinates and color
+type softwareVertex struct {
+	x, y, u, v float32
+	color      [4]float32
+}
+
+// softwareSampler reads the colors of a texture
+type softwareSampler struct {
+	img    *image.NRGBA
+	repeat TextureRepeating
+	linear bool
+}
+
+var softwareWhite = [4]float32{1, 1, 1, 1}
+
+// wrap returns the pixel at index i of a row or column of n pixels, repeating the texture as OpenGL would
+func (s softwareSampler) wrap(i, n int) int {
+	switch s.repeat {
+	case Repeat:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+	case MirroredRepeat:
+		i %= 2 * n
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+	default:
+		if i < 0 {
+			i = 0
+		} else if i >= n {
+			i = n - 1
+		}
+	}
+	return i
+}
+
+func (s softwareSampler) texel(x, y int) [4]float32 {
+	width, height := s.img.Rect.Dx(), s.img.Rect.Dy()
+	i := s.img.PixOffset(s.img.Rect.Min.X+s.wrap(x, width), s.img.Rect.Min.Y+s.wrap(y, height))
+	p := s.img.Pix[i : i+4 : i+4]
+	return [4]float32{float32(p[0]) / 0xff, float32(p[1]) / 0xff, float32(p[2]) / 0xff, float32(p[3]) / 0xff}
+}
+
+// sample returns the color of the texture at the texture coordinates, which range from 0 to 1
+func (s softwareSampler) sample(u, v float32) [4]float32 {
+	if s.img == nil {
+		return softwareWhite
+	}
+
+	x, y := u*float32(s.img.Rect.Dx()), v*float32(s.img.Rect.Dy())
+	if !s.linear {
+		return s.texel(int(math32.Floor(x)), int(math32.Floor(y)))
+	}
+
+	x, y = x-0.5, y-0.5
+	x0, y0 := math32.Floor(x), math32.Floor(y)
+	fx, fy := x-x0, y-y0
+	c00, c10 := s.texel(int(x0), int(y0)), s.texel(int(x0)+1, int(y0))
+	c01, c11 := s.texel(int(x0), int(y0)+1), s.texel(int(x0)+1, int(y0)+1)
+
+	var c [4]float32
+	for i := range c {
+		top := c00[i] + (c10[i]-c00[i])*fx
+		bottom := c01[i] + (c11[i]-c01[i])*fx
+		c[i] = top + (bottom-top)*fy
+	}
+	return c
+}
+
+// unpackColor is the inverse of colorToFloat32, returning the components ranging from 0 to 1
+func unpackColor(f float32) [4]float32 {
+	bits := math32.Float32bits(f)
+	return [4]float32{
+		float32(bits&0xff) / 0xff,
+		float32(bits>>8&0xff) / 0xff,
+		float32(bits>>16&0xff) / 0xff,
+		float32(bits>>24) / 0xff,
+	}
+}
+
+// softwareRasterizer draws triangles onto an image, blending them like the shaders do
+type softwareRasterizer struct {
+	canvas *image.RGBA
+	// clip is the area of the canvas that can be drawn to
+	clip image.Rectangle
+	// transform transforms the positions the shaders are given into pixels on the canvas
+	transform *tango.Matrix
+	// multiply multiplies the canvas by the colors drawn, like the LightMapShader, instead of blending them
+	multiply bool
+}
+
+// vertex returns the vertex at the given position, as given to the shaders
+func (r *softwareRasterizer) vertex(x, y, u, v float32, c [4]float32) softwareVertex {
+	p := r.transform.TransformPoint(tango.Point{X: x, Y: y})
+	return softwareVertex{x: p.X, y: p.Y, u: u, v: v, color: c}
+}
+
+// edge returns twice the signed area of the triangle a, b, p
+func edge(a, b softwareVertex, x, y float32) float32 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// ownsEdge decides which of two triangles sharing an edge draws the pixels exactly on it, so they're drawn once
+func ownsEdge(a, b softwareVertex) bool {
+	return b.y > a.y || b.y == a.y && b.x < a.x
+}
+
+// triangle draws the triangle, sampling the texture at the pixels whose centers are inside of it
+func (r *softwareRasterizer) triangle(a, b, c softwareVertex, s softwareSampler) {
+	area := edge(a, b, c.x, c.y)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+
+	bounds := image.Rect(
+		int(math32.Floor(math32.Min(a.x, math32.Min(b.x, c.x)))),
+		int(math32.Floor(math32.Min(a.y, math32.Min(b.y, c.y)))),
+		int(math32.Ceil(math32.Max(a.x, math32.Max(b.x, c.x))))+1,
+		int(math32.Ceil(math32.Max(a.y, math32.Max(b.y, c.y))))+1,
+	).Intersect(r.clip)
+
+	ownsA, ownsB, ownsC := ownsEdge(b, c), ownsEdge(c, a), ownsEdge(a, b)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		py := float32(y) + 0.5
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px := float32(x) + 0.5
+			wa, wb, wc := edge(b, c, px, py), edge(c, a, px, py), edge(a, b, px, py)
+			if wa < 0 || wb < 0 || wc < 0 || wa == 0 && !ownsA || wb == 0 && !ownsB || wc == 0 && !ownsC {
+				continue
+			}
+
+			wa, wb, wc = wa/area, wb/area, wc/area
+			texel := s.sample(wa*a.u+wb*b.u+wc*c.u, wa*a.v+wb*b.v+wc*c.v)
+			var color [4]float32
+			for i := range color {
+				color[i] = (wa*a.color[i] + wb*b.color[i] + wc*c.color[i]) * texel[i]
+			}
+			r.blend(x, y, color)
+		}
+	}
+}
+
+// line draws a line of the given width in pixels between both vertices, in the color of the first one
+func (r *softwareRasterizer) line(a, b softwareVertex, width float32) {
+	dx, dy := b.x-a.x, b.y-a.y
+	length := math32.Sqrt(dx*dx + dy*dy)
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*width/2, dx/length*width/2
+
+	corner := func(v softwareVertex, side float32) softwareVertex {
+		return softwareVertex{x: v.x + nx*side, y: v.y + ny*side, color: a.color}
+	}
+	r.triangle(corner(a, 1), corner(b, 1), corner(b, -1), softwareSampler{})
+	r.triangle(corner(a, 1), corner(b, -1), corner(a, -1), softwareSampler{})
+}
+
+// blend blends the color onto the pixel, with the SRC_ALPHA, ONE_MINUS_SRC_ALPHA function the shaders use
+func (r *softwareRasterizer) blend(x, y int, c [4]float32) {
+	i := r.canvas.PixOffset(x, y)
+	p := r.canvas.Pix[i : i+4 : i+4]
+	if r.multiply {
+		for k := 0; k < 4; k++ {
+			p[k] = softwareChannel(math32.Clamp(c[k], 0, 1) * float32(p[k]) / 0xff)
+		}
+		return
+	}
+	alpha := math32.Clamp(c[3], 0, 1)
+	for k := 0; k < 3; k++ {
+		p[k] = softwareChannel(math32.Clamp(c[k], 0, 1)*alpha + float32(p[k])/0xff*(1-alpha))
+	}
+	p[3] = softwareChannel(alpha + float32(p[3])/0xff*(1-alpha))
+}
+
+func softwareChannel(v float32) uint8 {
+	return uint8(math32.Clamp(v, 0, 1)*0xff + 0.5)
+}
+
+// softwareRenderer generates the geometry of the entities like the shaders do, and draws it with the CPU
+type softwareRenderer struct {
+	softwareRasterizer
+
+	sprite basicShader
+	legacy legacyShader
+	text   textShader
+	model  *tango.Matrix
+	buffer []float32
+}
+
+// scratch returns a buffer of the given size filled with zeroes, as the shaders leave zeroes in their buffers unset
+func (r *softwareRenderer) scratch(size int) []float32 {
+	if cap(r.buffer) < size {
+		r.buffer = make([]float32, size)
+	}
+	r.buffer = r.buffer[:size]
+	for i := range r.buffer {
+		r.buffer[i] = 0
+	}
+	return r.buffer
+}
+
+// modelMatrix returns the model matrix of the LegacyShader and TextShader
+func (r *softwareRenderer) modelMatrix(ren *RenderComponent, space *SpaceComponent) *tango.Matrix {
+	scale := tango.GetGlobalScale()
+	return r.model.Identity().
+		Translate(space.Position.X*scale.X, space.Position.Y*scale.Y).
+		Rotate(space.Rotation).
+		Scale(ren.Scale.X*scale.X, ren.Scale.Y*scale.Y)
+}
+
+// draw draws the entity as the shader would
+func (r *softwareRenderer) draw(shader Shader, ren *RenderComponent, space *SpaceComponent, zoom float32) {
+	if r.model == nil {
+		r.model = tango.IdentityMatrix()
+		r.sprite.modelMatrix = tango.IdentityMatrix()
+	}
+
+	switch shader.(type) {
+	case *basicShader, *Material:
+		r.drawSprite(ren, space)
+	case *lightMapShader:
+		r.multiply = true
+		r.drawSprite(ren, space)
+		r.multiply = false
+	case *legacyShader:
+		r.drawShape(ren, space, zoom)
+	case *textShader:
+		r.drawText(ren, space)
+	}
+}
+
+func (r *softwareRenderer) drawSprite(ren *RenderComponent, space *SpaceComponent) {
+	img, ok := softwareTextures[ren.Drawable.Texture()]
+	if !ok {
+		return
+	}
+	s := softwareSampler{img: img, repeat: ren.Repeat, linear: ren.magFilter == FilterLinear}
+
+	buffer := r.scratch(spriteSize * r.sprite.countQuads(ren, space))
+	r.sprite.generateQuads(ren, space, buffer)
+
+	var v [4]softwareVertex
+	for offset := 0; offset < len(buffer); offset += spriteSize {
+		for i := range v {
+			b := buffer[offset+i*5 : offset+i*5+5]
+			// The buffer already contains the model transformation
+			v[i] = r.vertex(b[0], b[1], b[2], b[3], unpackColor(b[4]))
+		}
+		r.triangle(v[0], v[1], v[2], s)
+		r.triangle(v[0], v[2], v[3], s)
+	}
+}
+
+func (r *softwareRenderer) drawShape(ren *RenderComponent, space *SpaceComponent, zoom float32) {
+	var buffer []float32
+	if shape, ok := ren.Drawable.(vectorShape); ok {
+		buffer, _ = vectorBufferContent(shape, ren, space, r.buffer)
+		r.buffer = buffer
+	} else {
+		buffer = r.scratch(r.legacy.computeBufferSize(ren.Drawable))
+		r.legacy.generateBufferContent(ren, space, buffer)
+	}
+
+	model := r.modelMatrix(ren, space)
+	vertex := func(i int) softwareVertex {
+		p := model.TransformPoint(tango.Point{X: buffer[i*3], Y: buffer[i*3+1]})
+		return r.vertex(p.X, p.Y, 0, 0, unpackColor(buffer[i*3+2]))
+	}
+	triangles := func(first, count int) {
+		for i := first; i+2 < first+count; i += 3 {
+			r.triangle(vertex(i), vertex(i+1), vertex(i+2), softwareSampler{})
+		}
+	}
+	fan := func(first, count int) {
+		for i := first + 1; i+1 < first+count; i++ {
+			r.triangle(vertex(first), vertex(i), vertex(i+1), softwareSampler{})
+		}
+	}
+
+	switch shape := ren.Drawable.(type) {
+	case Triangle:
+		if shape.BorderWidth > 0 {
+			triangles(0, 21)
+		} else {
+			triangles(0, 3)
+		}
+	case Rectangle:
+		if shape.BorderWidth > 0 {
+			triangles(0, 30)
+		} else {
+			triangles(0, 6)
+		}
+	case Circle:
+		if shape.BorderWidth > 0 {
+			fan(300, 300)
+		}
+		fan(0, 300)
+	case ComplexTriangles:
+		n := len(shape.Points)
+		triangles(0, n)
+		if shape.BorderWidth > 0 {
+			for i := 0; i < n; i++ {
+				r.line(vertex(n+i), vertex(n+(i+1)%n), shape.BorderWidth/zoom)
+			}
+		}
+	case vectorShape:
+		triangles(0, len(buffer)/3)
+	}
+}
+
+func (r *softwareRenderer) drawText(ren *RenderComponent, space *SpaceComponent) {
+	txt, ok := ren.Drawable.(Text)
+	if !ok {
+		unsupportedType(ren.Drawable)
+		return
+	}
+
+	buffer := r.scratch(20 * len(txt.Text))
+	r.text.generateBufferContent(ren, space, buffer)
+	// Generating the buffer created the atlas if there was none yet
+	s := softwareSampler{img: softwareTextures[atlasCache[*txt.Font].Texture]}
+
+	model := r.modelMatrix(ren, space)
+	var v [4]softwareVertex
+	for offset := 0; offset < len(buffer); offset += 20 {
+		for i := range v {
+			b := buffer[offset+i*5 : offset+i*5+5]
+			p := model.TransformPoint(tango.Point{X: b[0], Y: b[1]})
+			v[i] = r.vertex(p.X, p.Y, b[2], b[3], unpackColor(b[4]))
+		}
+		r.triangle(v[0], v[1], v[2], s)
+		r.triangle(v[0], v[2], v[3], s)
+	}
+}
+
+// softwareViewport returns the pixels of the canvas covered by the viewport of a camera
+func softwareViewport(canvas image.Rectangle, viewport tango.AABB) image.Rectangle {
+	if viewport == (tango.AABB{}) {
+		return canvas
+	}
+
+	width, height := float32(canvas.Dx()), float32(canvas.Dy())
+	return image.Rect(
+		int(math32.Floor(viewport.Min.X*width+0.5)),
+		int(math32.Floor(viewport.Min.Y*height+0.5)),
+		int(math32.Floor(viewport.Max.X*width+0.5)),
+		int(math32.Floor(viewport.Max.Y*height+0.5)),
+	).Add(canvas.Min)
+}
+
+// softwareTransform returns the transformation of the positions given to the shaders into pixels in the viewport,
+// through the camera, or for the HUD if it's nil.
+func softwareTransform(viewport image.Rectangle, cam *CameraSystem) *tango.Matrix {
+	width, height := viewportSize(cam)
+	m := tango.IdentityMatrix().
+		Translate(float32(viewport.Min.X), float32(viewport.Min.Y)).
+		Scale(float32(viewport.Dx())/width, float32(viewport.Dy())/height)
+	if cam == nil {
+		return m
+	}
+
+	// The positions given to the shaders already contain the GlobalScale
+	scale := tango.GetGlobalScale()
+	return m.Multiply(cam.viewportMatrix()).Scale(1/scale.X, 1/scale.Y)
+}
+
+// renderSoftware draws the cameras and the HUD to the SoftwareCanvas, like render does with OpenGL
+func (rs *RenderSystem) renderSoftware() {
+	bounds := image.Rect(0, 0, int(tango.CanvasWidth()), int(tango.CanvasHeight()))
+	if softwareCanvas == nil || softwareCanvas.Rect != bounds {
+		softwareCanvas = image.NewRGBA(bounds)
+	}
+	background := color.RGBA{
+		R: softwareChannel(backgroundColor[0]),
+		G: softwareChannel(backgroundColor[1]),
+		B: softwareChannel(backgroundColor[2]),
+		A: softwareChannel(backgroundColor[3]),
+	}
+	draw.Draw(softwareCanvas, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+	rs.software.canvas = softwareCanvas
+
+	screens := 0
+	var single *CameraSystem
+	for _, cam := range rs.cameras {
+		if cam.Target == nil {
+			screens++
+			single = cam
+		}
+	}
+
+	hud := softwareTransform(bounds, nil)
+	if screens == 1 && single.fullscreen() {
+		// A single camera shares the canvas with the HUD, so they're drawn together in the order of their z-index
+		rs.drawSoftware(bounds, single, hud, func(e renderEntity) bool {
+			return isHUDShader(rs.shaderOf(e)) || single.renders(e.RenderComponent)
+		})
+		return
+	}
+
+	for _, cam := range rs.cameras {
+		if cam.Target != nil {
+			continue
+		}
+		rs.drawSoftware(softwareViewport(bounds, cam.Viewport), cam, nil, func(e renderEntity) bool {
+			return !isHUDShader(rs.shaderOf(e)) && cam.renders(e.RenderComponent)
+		})
+	}
+
+	// The HUD is drawn once over the whole canvas, on top of all cameras
+	rs.drawSoftware(bounds, nil, hud, func(e renderEntity) bool {
+		return isHUDShader(rs.shaderOf(e))
+	})
+}
+
+// drawSoftware draws the entities passing the filter within the viewport, through the camera, or with the HUD
+// transformation for HUD entities.
+func (rs *RenderSystem) drawSoftware(viewport image.Rectangle, cam *CameraSystem, hud *tango.Matrix, filter func(e renderEntity) bool) {
+	r := &rs.software
+	r.clip = viewport
+
+	var world *tango.Matrix
+	zoom := float32(1)
+	if cam != nil {
+		world = softwareTransform(viewport, cam)
+		zoom = cam.z
+	}
+
+	for _, i := range rs.visible(cam) {
+		e := rs.entities[i]
+		if e.RenderComponent.Hidden || rs.layerOf(e).Hidden || !filter(e) {
+			continue
+		}
+
+		// Setting the same defaults as draw
+		if e.RenderComponent.Scale.X == 0 && e.RenderComponent.Scale.Y == 0 {
+			e.RenderComponent.Scale = tango.Point{X: 1, Y: 1}
+		}
+		if e.RenderComponent.Color == nil {
+			e.RenderComponent.Color = color.White
+		}
+
+		shader := rs.shaderOf(e)
+		if isHUDShader(shader) {
+			r.transform = hud
+			r.draw(shader, e.RenderComponent, e.SpaceComponent, 1)
+		} else {
+			r.transform = world
+			r.draw(shader, e.RenderComponent, e.SpaceComponent, zoom)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newSoftwareTestWorld() (*ecs.World, *RenderSystem) {
+	tango.Run(tango.RunOptions{HeadlessMode: true, NoRun: true, Width: 200, Height: 100}, &cameraFollowerTestScene{})
+	tango.Mailbox = &tango.MessageManager{}
+	tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+	CameraBounds = tango.AABB{Max: tango.Point{X: 200, Y: 100}}
+	SetBackground(color.Black)
+
+	w := &ecs.World{}
+	rs := &RenderSystem{}
+	w.AddSystem(rs)
+	return w, rs
+}
+
+func addSoftwareTestEntity(rs *RenderSystem, d Drawable, x, y, width, height float32) *RenderComponent {
+	basic := ecs.NewBasic()
+	render := &RenderComponent{Drawable: d}
+	rs.Add(&basic, render, &SpaceComponent{Position: tango.Point{X: x, Y: y}, Width: width, Height: height})
+	return render
+}
+
+// assertSoftwarePixel allows each channel to be off by one, as colors are drawn with an alpha of 0xfe
+func assertSoftwarePixel(t *testing.T, expected color.RGBA, x, y int, msgAndArgs ...interface{}) {
+	actual := SoftwareCanvas().RGBAAt(x, y)
+	e := []float64{float64(expected.R), float64(expected.G), float64(expected.B), float64(expected.A)}
+	a := []float64{float64(actual.R), float64(actual.G), float64(actual.B), float64(actual.A)}
+	assert.InDeltaSlice(t, e, a, 1, msgAndArgs...)
+}
+
+func TestSoftwareRenderingSprite(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 0xff, A: 0xff})
+	texture := NewTextureSingle(NewImageObject(img))
+	defer texture.Close()
+
+	render := addSoftwareTestEntity(rs, texture, 10, 20, 2, 1)
+	render.Scale = tango.Point{X: 10, Y: 10}
+	w.Update(1)
+
+	canvas := SoftwareCanvas()
+	if !assert.NotNil(t, canvas) {
+		return
+	}
+	assert.Equal(t, image.Rect(0, 0, 200, 100), canvas.Rect, "The canvas should be the size of the window")
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 5, 25, "The background should be drawn around the sprite")
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 10, 20, "The first pixel of the sprite should be at its position")
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 19, 29)
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 20, 20, "The sprite should be scaled")
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 29, 29)
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 30, 30)
+
+	render.Color = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80}
+	w.Update(1)
+	// The color is premultiplied, and blended with its alpha once more, the same as OpenGL does
+	assert.InDelta(t, 0x40, SoftwareCanvas().RGBAAt(15, 25).R, 1, "The color of the sprite should be blended with the background")
+}
+
+func TestSoftwareRenderingShapes(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	render := addSoftwareTestEntity(rs, Rectangle{BorderWidth: 2, BorderColor: color.White}, 10, 10, 20, 20)
+	render.Color = color.RGBA{G: 0xff, A: 0xff}
+	circle := addSoftwareTestEntity(rs, Circle{}, 100, 10, 40, 40)
+	circle.Color = color.RGBA{R: 0xff, A: 0xff}
+	w.Update(1)
+
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, 10, 20, "The border should be drawn inside the rectangle")
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 20, 20)
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 31, 20)
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 120, 30, "The center of the circle should be drawn")
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 101, 11, "The corners of the circle should not be drawn")
+}
+
+func TestSoftwareRenderingCamera(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	addSoftwareTestEntity(rs, Rectangle{}, 50, 50, 10, 10)
+	hud := addSoftwareTestEntity(rs, Rectangle{}, 50, 50, 10, 10)
+	hud.Color = color.RGBA{B: 0xff, A: 0xff}
+	hud.SetShader(LegacyHUDShader)
+	hud.SetZIndex(1)
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 55, 55, "The HUD should be drawn on top")
+
+	var cam *CameraSystem
+	for _, system := range w.Systems() {
+		if c, ok := system.(*CameraSystem); ok {
+			cam = c
+		}
+	}
+	cam.moveX(-40)
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 55, 55, "The HUD should not move with the camera")
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, 95, 55, "The world should move with the camera")
+
+	cam.zoomTo(2)
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, 97, 52, "Zooming out should draw the world smaller")
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 92, 52)
+}
+
+func TestSoftwareRenderingText(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	ttf, err := truetype.Parse(goregular.TTF)
+	if !assert.NoError(t, err) {
+		return
+	}
+	fnt := &Font{Size: 24, FG: color.White, TTF: ttf}
+	addSoftwareTestEntity(rs, Text{Font: fnt, Text: "ll"}, 10, 10, 0, 0)
+	w.Update(1)
+
+	lit := 0
+	canvas := SoftwareCanvas()
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if canvas.RGBAAt(x, y).R > 0x80 {
+				lit++
+				assert.True(t, x >= 10 && y >= 10 && x < 60 && y < 60, "The text should be drawn at its position")
+			}
+		}
+	}
+	assert.True(t, lit > 20, "The glyphs of the text should be drawn")
+}
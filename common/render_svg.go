@@ -0,0 +1,220 @@
+package common
+
+import (
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// svgLoader loads .svg files. It keeps the parsed icons, so they can be rasterized again at other resolutions, and
+// a TextureResource of each at the size of its view box.
+type svgLoader struct {
+	icons  map[string]*oksvg.SvgIcon
+	images map[string]TextureResource
+}
+
+// svgs is the FileLoader registered for .svg files
+var svgs = &svgLoader{icons: make(map[string]*oksvg.SvgIcon), images: make(map[string]TextureResource)}
+
+func (l *svgLoader) Load(url string, data io.Reader) error {
+	icon, err := oksvg.ReadIconStream(data, oksvg.WarnErrorMode)
+	if err != nil {
+		return err
+	}
+	width, height := svgSize(icon, 1)
+	l.icons[url] = icon
+	l.images[url] = NewTextureResource(rasterizeSVG(icon, width, height))
+	return nil
+}
+
+func (l *svgLoader) Unload(url string) error {
+	delete(l.icons, url)
+	delete(l.images, url)
+	return nil
+}
+
+func (l *svgLoader) Resource(url string) (tango.Resource, error) {
+	texture, ok := l.images[url]
+	if !ok {
+		return nil, fmt.Errorf("resource not loaded by `FileLoader`: %q", url)
+	}
+
+	return texture, nil
+}
+
+// icon returns the parsed icon of a loaded .svg file.
+func (l *svgLoader) icon(url string) (*oksvg.SvgIcon, error) {
+	icon, ok := l.icons[url]
+	if !ok {
+		return nil, fmt.Errorf("resource not loaded by `FileLoader`: %q", url)
+	}
+	return icon, nil
+}
+
+// svgSize returns the size, in pixels, of the icon rasterized at the scale.
+func svgSize(icon *oksvg.SvgIcon, scale float32) (int, int) {
+	width := math32.Ceil(float32(icon.ViewBox.W) * scale)
+	height := math32.Ceil(float32(icon.ViewBox.H) * scale)
+	return int(math32.Max(width, 1)), int(math32.Max(height, 1))
+}
+
+// rasterizeSVG draws the view box of the icon into an image of the given size.
+func rasterizeSVG(icon *oksvg.SvgIcon, width, height int) *image.NRGBA {
+	icon.Transform = rasterx.Identity.
+		Scale(float64(width)/icon.ViewBox.W, float64(height)/icon.ViewBox.H).
+		Translate(-icon.ViewBox.X, -icon.ViewBox.Y)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	scanner := rasterx.NewScannerGV(width, height, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(width, height, scanner), 1)
+	return ImageToNRGBA(img, width, height)
+}
+
+// RasterizeSVG rasterizes a .svg file loaded with tango.Files at the given resolution, in pixels, into a new
+// TextureResource. The view box of the file is stretched to the resolution.
+func RasterizeSVG(url string, width, height int) (TextureResource, error) {
+	icon, err := svgs.icon(url)
+	if err != nil {
+		return TextureResource{}, err
+	}
+	if width <= 0 || height <= 0 {
+		return TextureResource{}, fmt.Errorf("invalid resolution %dx%d for %q", width, height, url)
+	}
+	return NewTextureResource(rasterizeSVG(icon, width, height)), nil
+}
+
+// SVG is a Drawable of a .svg file, rasterized into a texture at the scale it's drawn at. Its size is the size of the
+// view box of the file, whatever the resolution of the texture, so it can be rasterized again at another scale
+// without changing the size it's drawn at. The SVGSystem does so whenever the scale changes, so it stays crisp.
+type SVG struct {
+	icon    *oksvg.SvgIcon
+	texture *gl.Texture
+	scale   float32
+}
+
+// LoadedSVG returns an SVG of a .svg file loaded with tango.Files, rasterized at the GlobalScale.
+func LoadedSVG(url string) (*SVG, error) {
+	icon, err := svgs.icon(url)
+	if err != nil {
+		return nil, err
+	}
+	s := &SVG{icon: icon}
+	s.Rasterize(math32.Max(tango.GetGlobalScale().X, tango.GetGlobalScale().Y))
+	return s, nil
+}
+
+// Rasterize rasterizes the SVG again if it's drawn at another scale than the one it was rasterized at. The scale is
+// rounded up to a multiple of 1/4, so it isn't rasterized for every small change of the scale, such as while it's
+// being tweened.
+func (s *SVG) Rasterize(scale float32) {
+	scale = math32.Ceil(math32.Abs(scale)*4) / 4
+	if scale == 0 || scale == s.scale {
+		return
+	}
+
+	width, height := svgSize(s.icon, scale)
+	texture := UploadTexture(NewImageObject(rasterizeSVG(s.icon, width, height)))
+	s.Close()
+	s.texture, s.scale = texture, scale
+}
+
+// Scale returns the scale the SVG is rasterized at.
+func (s *SVG) Scale() float32 {
+	return s.scale
+}
+
+// Texture returns the texture of the SVG rasterized at its Scale. This implements the Drawable interface.
+func (s *SVG) Texture() *gl.Texture { return s.texture }
+
+// Width returns the width of the view box of the SVG. This implements the Drawable interface.
+func (s *SVG) Width() float32 { return float32(s.icon.ViewBox.W) }
+
+// Height returns the height of the view box of the SVG. This implements the Drawable interface.
+func (s *SVG) Height() float32 { return float32(s.icon.ViewBox.H) }
+
+// View always returns 0, 0, 1, 1. This implements the Drawable interface.
+func (s *SVG) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
+
+// Close removes the texture of the SVG from the GPU. It's rasterized again when it's drawn at another scale. This
+// implements the Drawable interface.
+func (s *SVG) Close() {
+	if s.texture != nil {
+		Texture{id: s.texture}.Close()
+	}
+	s.texture, s.scale = nil, 0
+}
+
+type svgEntity struct {
+	*ecs.BasicEntity
+	*RenderComponent
+}
+
+// SVGSystem rasterizes the SVGs drawn by its entities again whenever the scale they're drawn at changes, which is
+// the Scale of their RenderComponent times the GlobalScale. An SVG drawn by more than one entity is rasterized at
+// the largest of their scales.
+type SVGSystem struct {
+	entities []svgEntity
+	scales   map[*SVG]float32
+}
+
+// Add starts tracking the given entity.
+func (s *SVGSystem) Add(basic *ecs.BasicEntity, render *RenderComponent) {
+	s.entities = append(s.entities, svgEntity{basic, render})
+}
+
+// AddByInterface allows an Entity to be added directly using the SVGable interface, which every entity containing
+// the BasicEntity and RenderComponent anonymously automatically satisfies.
+func (s *SVGSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(SVGable)
+	s.Add(o.GetBasicEntity(), o.GetRenderComponent())
+}
+
+// Remove stops tracking the given entity.
+func (s *SVGSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range s.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		s.entities = append(s.entities[:delete], s.entities[delete+1:]...)
+	}
+}
+
+// Update rasterizes the SVGs of which the scale changed.
+func (s *SVGSystem) Update(dt float32) {
+	if s.scales == nil {
+		s.scales = make(map[*SVG]float32)
+	}
+	global := tango.GetGlobalScale()
+	globalScale := math32.Max(math32.Abs(global.X), math32.Abs(global.Y))
+
+	for _, e := range s.entities {
+		svg, ok := e.RenderComponent.Drawable.(*SVG)
+		if !ok {
+			continue
+		}
+		scale := e.RenderComponent.Scale
+		if scale.X == 0 && scale.Y == 0 {
+			scale = tango.Point{X: 1, Y: 1}
+		}
+		if drawn := math32.Max(math32.Abs(scale.X), math32.Abs(scale.Y)) * globalScale; drawn > s.scales[svg] {
+			s.scales[svg] = drawn
+		}
+	}
+
+	for svg, scale := range s.scales {
+		svg.Rasterize(scale)
+		delete(s.scales, svg)
+	}
+}
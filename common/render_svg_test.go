@@ -0,0 +1,116 @@
+package common
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+// svgTestIcon is red on the left half of its view box, and blue on the right half
+const svgTestIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10" width="10" height="10">
+<rect x="0" y="0" width="5" height="10" fill="#ff0000"/>
+<rect x="5" y="0" width="5" height="10" fill="#0000ff"/>
+</svg>`
+
+func loadSVGTestIcon(t *testing.T, url string) bool {
+	return assert.NoError(t, tango.Files.LoadReaderData(url, strings.NewReader(svgTestIcon)))
+}
+
+func TestSVGLoader(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	newSoftwareTestWorld()
+	if !loadSVGTestIcon(t, "loader.svg") {
+		return
+	}
+	defer tango.Files.Unload("loader.svg")
+
+	res, err := tango.Files.Resource("loader.svg")
+	if !assert.NoError(t, err) {
+		return
+	}
+	texture := res.(TextureResource)
+	assert.Equal(t, float32(10), texture.Width, "The file should be rasterized at the size of its view box")
+	assert.Equal(t, float32(10), texture.Height)
+
+	texture, err = RasterizeSVG("loader.svg", 40, 20)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Texture{id: texture.Texture}.Close()
+	img := softwareTextures[texture.Texture]
+	if assert.NotNil(t, img) {
+		assert.Equal(t, 40, img.Rect.Dx(), "The file should be rasterized at the requested resolution")
+		assert.Equal(t, 20, img.Rect.Dy())
+		assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, img.NRGBAAt(19, 10))
+		assert.Equal(t, color.NRGBA{B: 0xff, A: 0xff}, img.NRGBAAt(20, 10), "The view box should be stretched")
+	}
+
+	_, err = RasterizeSVG("loader.svg", 0, 20)
+	assert.Error(t, err, "A resolution without pixels should fail")
+	_, err = RasterizeSVG("missing.svg", 10, 10)
+	assert.Error(t, err, "A file which isn't loaded should fail")
+}
+
+func TestSVGSystem(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+	defer tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+	if !loadSVGTestIcon(t, "system.svg") {
+		return
+	}
+	defer tango.Files.Unload("system.svg")
+
+	svg, err := LoadedSVG("system.svg")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer svg.Close()
+	assert.Equal(t, float32(1), svg.Scale())
+	assert.Equal(t, float32(10), svg.Width())
+
+	sys := &SVGSystem{}
+	w.AddSystem(sys)
+	basic := ecs.NewBasic()
+	render := &RenderComponent{Drawable: svg, Scale: tango.Point{X: 3, Y: 3}}
+	space := &SpaceComponent{Position: tango.Point{X: 10, Y: 10}, Width: 30, Height: 30}
+	sys.Add(&basic, render)
+	rs.Add(&basic, render, space)
+
+	first := svg.Texture()
+	w.Update(1)
+	assert.Equal(t, float32(3), svg.Scale(), "The SVG should be rasterized at the Scale it's drawn at")
+	if img := softwareTextures[svg.Texture()]; assert.NotNil(t, img) {
+		assert.Equal(t, 30, img.Rect.Dx())
+	}
+	_, ok := softwareTextures[first]
+	assert.False(t, ok, "The texture at the earlier scale should be closed")
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 24, 20)
+	assertSoftwarePixel(t, color.RGBA{B: 0xff, A: 0xff}, 25, 20, "The SVG should be drawn at the size of its view box")
+
+	tango.SetGlobalScale(tango.Point{X: 2, Y: 2})
+	w.Update(1)
+	assert.Equal(t, float32(6), svg.Scale(), "The SVG should be rasterized at the GlobalScale as well")
+
+	tango.SetGlobalScale(tango.Point{X: 1, Y: 1})
+	render.Scale = tango.Point{X: 1.1, Y: 1.1}
+	w.Update(1)
+	assert.Equal(t, float32(1.25), svg.Scale(), "The scale should be rounded up")
+	texture := svg.Texture()
+	render.Scale = tango.Point{X: 1.2, Y: 1.2}
+	w.Update(1)
+	assert.Equal(t, texture, svg.Texture(), "Small changes of the scale shouldn't rasterize the SVG again")
+
+	other := ecs.NewBasic()
+	sys.Add(&other, &RenderComponent{Drawable: svg, Scale: tango.Point{X: 2, Y: 2}})
+	w.Update(1)
+	assert.Equal(t, float32(2), svg.Scale(), "An SVG drawn by more than one entity should be rasterized at the largest scale")
+	sys.Remove(other)
+	w.Update(1)
+	assert.Equal(t, float32(1.25), svg.Scale())
+}
@@ -0,0 +1,658 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/gl"
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// LineCap is the shape of the ends of a stroke which isn't closed.
+type LineCap uint8
+
+const (
+	// LineCapButt ends the stroke at its end points
+	LineCapButt LineCap = iota
+	// LineCapSquare extends the stroke past its end points by half its width
+	LineCapSquare
+	// LineCapRound ends the stroke with half a circle around its end points
+	LineCapRound
+)
+
+// LineJoin is the shape of the corners where the segments of a stroke meet.
+type LineJoin uint8
+
+const (
+	// LineJoinMiter extends the outer edges of the segments until they meet. Corners sharper than the miter limit
+	// are beveled instead.
+	LineJoinMiter LineJoin = iota
+	// LineJoinBevel cuts off the corner between the outer edges of the segments
+	LineJoinBevel
+	// LineJoinRound rounds the corner with a circle around the point of the corner
+	LineJoinRound
+)
+
+const (
+	// miterLimit is the maximum ratio between the length of a miter and the width of the stroke, as with SVG
+	miterLimit = 4
+	// curveTolerance is the maximum distance, in units, between a curve and the segments approximating it
+	curveTolerance = 0.1
+)
+
+// vectorShape is a Drawable which is tessellated into triangles on the CPU, and drawn by the LegacyShader.
+type vectorShape interface {
+	Drawable
+	// tessellate returns the triangles of the shape drawn at the given size, three points each: those filled with the
+	// Color of the RenderComponent, and those of its border.
+	tessellate(width, height float32) (fill, border []tango.Point)
+	// borderColor returns the color of the border.
+	borderColor() color.Color
+}
+
+// Line is a straight line between two points, drawn in the Color of the RenderComponent.
+type Line struct {
+	// From and To are the end points of the line. They should be defined on a scale from 0 to 1, where (0, 0) is the
+	// top-left of the area defined by the SpaceComponent.
+	From, To tango.Point
+	// LineWidth is the width of the line, in units.
+	LineWidth float32
+	// Cap is the shape of the ends of the line.
+	Cap LineCap
+}
+
+// Texture always returns nil. Line is drawable without a Texture. This implements the Drawable interface.
+func (Line) Texture() *gl.Texture { return nil }
+
+// Width always returns 0. This implements the Drawable interface.
+func (Line) Width() float32 { return 0 }
+
+// Height always returns 0. This implements the Drawable interface.
+func (Line) Height() float32 { return 0 }
+
+// View always returns 0, 0, 1, 1. This implements the Drawable interface.
+func (Line) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
+
+// Close does nothing, because there's no Texture on the GPU. This implements the Drawable interface.
+func (Line) Close() {}
+
+func (l Line) tessellate(width, height float32) (fill, border []tango.Point) {
+	points := scalePoints([]tango.Point{l.From, l.To}, width, height)
+	return strokePolyline(nil, points, l.LineWidth, LineJoinMiter, l.Cap, false), nil
+}
+
+func (Line) borderColor() color.Color { return nil }
+
+// Polyline is a line through a number of points, drawn in the Color of the RenderComponent.
+type Polyline struct {
+	// Points are the points the line goes through. They should be defined on a scale from 0 to 1, where (0, 0) is the
+	// top-left of the area defined by the SpaceComponent.
+	Points []tango.Point
+	// Closed connects the last point to the first one.
+	Closed bool
+	// LineWidth is the width of the line, in units.
+	LineWidth float32
+	// Join is the shape of the corners of the line.
+	Join LineJoin
+	// Cap is the shape of the ends of the line, if it isn't Closed.
+	Cap LineCap
+}
+
+// Texture always returns nil. Polyline is drawable without a Texture. This implements the Drawable interface.
+func (Polyline) Texture() *gl.Texture { return nil }
+
+// Width always returns 0. This implements the Drawable interface.
+func (Polyline) Width() float32 { return 0 }
+
+// Height always returns 0. This implements the Drawable interface.
+func (Polyline) Height() float32 { return 0 }
+
+// View always returns 0, 0, 1, 1. This implements the Drawable interface.
+func (Polyline) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
+
+// Close does nothing, because there's no Texture on the GPU. This implements the Drawable interface.
+func (Polyline) Close() {}
+
+func (p Polyline) tessellate(width, height float32) (fill, border []tango.Point) {
+	return strokePolyline(nil, scalePoints(p.Points, width, height), p.LineWidth, p.Join, p.Cap, p.Closed), nil
+}
+
+func (Polyline) borderColor() color.Color { return nil }
+
+// RoundedRectangle is a rectangle with rounded corners; the dimensions are controlled via the `SpaceComponent`.
+type RoundedRectangle struct {
+	// Radius is the radius of the corners, in units. It's reduced to half the width or height if it's larger.
+	Radius float32
+
+	BorderWidth float32
+	BorderColor color.Color
+}
+
+// Texture always returns nil. RoundedRectangle is drawable without a Texture. This implements the Drawable interface.
+func (RoundedRectangle) Texture() *gl.Texture { return nil }
+
+// Width always returns 0. This implements the Drawable interface.
+func (RoundedRectangle) Width() float32 { return 0 }
+
+// Height always returns 0. This implements the Drawable interface.
+func (RoundedRectangle) Height() float32 { return 0 }
+
+// View always returns 0, 0, 1, 1. This implements the Drawable interface.
+func (RoundedRectangle) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
+
+// Close does nothing, because there's no Texture on the GPU. This implements the Drawable interface.
+func (RoundedRectangle) Close() {}
+
+func (r RoundedRectangle) tessellate(width, height float32) (fill, border []tango.Point) {
+	outline := roundedRectangleOutline(0, 0, width, height, r.Radius)
+	fill = appendConvex(nil, outline)
+
+	if r.BorderWidth > 0 {
+		// Like the border of a Rectangle, the border is drawn on the inside
+		b := math32.Min(r.BorderWidth, math32.Min(width, height)/2)
+		inner := roundedRectangleOutline(b/2, b/2, width-b, height-b, math32.Max(r.Radius-b/2, 0))
+		border = strokePolyline(nil, inner, b, LineJoinMiter, LineCapButt, true)
+	}
+	return fill, border
+}
+
+func (r RoundedRectangle) borderColor() color.Color { return r.BorderColor }
+
+// roundedRectangleOutline returns the outline of a rectangle with rounded corners, clockwise from the top-left.
+func roundedRectangleOutline(x, y, width, height, radius float32) []tango.Point {
+	radius = math32.Max(0, math32.Min(radius, math32.Min(width, height)/2))
+	if radius == 0 {
+		return []tango.Point{{X: x, Y: y}, {X: x + width, Y: y}, {X: x + width, Y: y + height}, {X: x, Y: y + height}}
+	}
+
+	corners := []tango.Point{
+		{X: x + width - radius, Y: y + radius},
+		{X: x + width - radius, Y: y + height - radius},
+		{X: x + radius, Y: y + height - radius},
+		{X: x + radius, Y: y + radius},
+	}
+	var outline []tango.Point
+	for i, center := range corners {
+		// The corners go from the top-right around, each a quarter circle starting 90 degrees further
+		start := float32(i-1) * math32.Pi / 2
+		outline = appendArc(outline, center, radius, radius, start, math32.Pi/2)
+	}
+	return outline
+}
+
+// Arc is a part of the outline of an ellipse, which fits the area defined by the SpaceComponent, drawn in the Color
+// of the RenderComponent. If its LineWidth is zero, the sector of the ellipse is filled instead.
+type Arc struct {
+	// StartAngle and EndAngle are the angles the arc goes between, in degrees clockwise from the right.
+	StartAngle, EndAngle float32
+	// LineWidth is the width of the arc, in units. The arc is drawn on the inside of the ellipse.
+	LineWidth float32
+	// Cap is the shape of the ends of the arc.
+	Cap LineCap
+}
+
+// Texture always returns nil. Arc is drawable without a Texture. This implements the Drawable interface.
+func (Arc) Texture() *gl.Texture { return nil }
+
+// Width always returns 0. This implements the Drawable interface.
+func (Arc) Width() float32 { return 0 }
+
+// Height always returns 0. This implements the Drawable interface.
+func (Arc) Height() float32 { return 0 }
+
+// View always returns 0, 0, 1, 1. This implements the Drawable interface.
+func (Arc) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
+
+// Close does nothing, because there's no Texture on the GPU. This implements the Drawable interface.
+func (Arc) Close() {}
+
+func (a Arc) tessellate(width, height float32) (fill, border []tango.Point) {
+	center := tango.Point{X: width / 2, Y: height / 2}
+	start := a.StartAngle * math32.Pi / 180
+	sweep := (a.EndAngle - a.StartAngle) * math32.Pi / 180
+	if a.LineWidth <= 0 {
+		outline := appendArc([]tango.Point{center}, center, width/2, height/2, start, sweep)
+		return appendConvex(nil, outline), nil
+	}
+
+	points := appendArc(nil, center, (width-a.LineWidth)/2, (height-a.LineWidth)/2, start, sweep)
+	closed := math32.Abs(sweep) >= 2*math32.Pi
+	if closed {
+		points = points[:len(points)-1]
+	}
+	return strokePolyline(nil, points, a.LineWidth, LineJoinMiter, a.Cap, closed), nil
+}
+
+func (Arc) borderColor() color.Color { return nil }
+
+// Polygon is a form made out of a closed outline, which may have holes in it. It's split into triangles on the CPU,
+// so it may be concave.
+type Polygon struct {
+	// Points are the points of the outline. They should be defined on a scale from 0 to 1, where (0, 0) is the
+	// top-left of the area defined by the SpaceComponent. The outline may go either way around, but shouldn't cross
+	// itself.
+	Points []tango.Point
+	// Holes are the outlines of the holes in the polygon, defined like the Points. They should be within the
+	// polygon, and not overlap each other.
+	Holes [][]tango.Point
+
+	// BorderWidth indicates the width of the border, centered on the outline and the outlines of the holes
+	BorderWidth float32
+	BorderColor color.Color
+}
+
+// Texture always returns nil. Polygon is drawable without a Texture. This implements the Drawable interface.
+func (Polygon) Texture() *gl.Texture { return nil }
+
+// Width always returns 0. This implements the Drawable interface.
+func (Polygon) Width() float32 { return 0 }
+
+// Height always returns 0. This implements the Drawable interface.
+func (Polygon) Height() float32 { return 0 }
+
+// View always returns 0, 0, 1, 1. This implements the Drawable interface.
+func (Polygon) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
+
+// Close does nothing, because there's no Texture on the GPU. This implements the Drawable interface.
+func (Polygon) Close() {}
+
+func (p Polygon) tessellate(width, height float32) (fill, border []tango.Point) {
+	outline := scalePoints(p.Points, width, height)
+	holes := make([][]tango.Point, len(p.Holes))
+	for i, hole := range p.Holes {
+		holes[i] = scalePoints(hole, width, height)
+	}
+	fill = triangulatePolygon(outline, holes)
+
+	if p.BorderWidth > 0 {
+		border = strokePolyline(nil, outline, p.BorderWidth, LineJoinMiter, LineCapButt, true)
+		for _, hole := range holes {
+			border = strokePolyline(border, hole, p.BorderWidth, LineJoinMiter, LineCapButt, true)
+		}
+	}
+	return fill, border
+}
+
+func (p Polygon) borderColor() color.Color { return p.BorderColor }
+
+// vectorBufferContent fills the buffer with the triangles of the shape for the LegacyShader, and returns it, resized to
+// fit them, along with whether it changed.
+func vectorBufferContent(shape vectorShape, ren *RenderComponent, space *SpaceComponent, buffer []float32) ([]float32, bool) {
+	fill, border := shape.tessellate(space.Width, space.Height)
+	size := 3 * (len(fill) + len(border))
+	changed := len(buffer) != size
+	if cap(buffer) < size {
+		buffer = make([]float32, size)
+	}
+	buffer = buffer[:size]
+
+	index := 0
+	appendPoints := func(points []tango.Point, tint float32) {
+		for _, p := range points {
+			setBufferValue(buffer, index, p.X, &changed)
+			setBufferValue(buffer, index+1, p.Y, &changed)
+			setBufferValue(buffer, index+2, tint, &changed)
+			index += 3
+		}
+	}
+	appendPoints(fill, colorToFloat32(ren.Color))
+	if len(border) > 0 {
+		appendPoints(border, colorToFloat32(shape.borderColor()))
+	}
+	return buffer, changed
+}
+
+// scalePoints returns the points, defined on a scale from 0 to 1, scaled to the given size.
+func scalePoints(points []tango.Point, width, height float32) []tango.Point {
+	scaled := make([]tango.Point, len(points))
+	for i, p := range points {
+		scaled[i] = tango.Point{X: p.X * width, Y: p.Y * height}
+	}
+	return scaled
+}
+
+// cleanPoints returns the points without the ones which are the same as the point before them, including the last
+// point of a closed outline which is the same as the first one.
+func cleanPoints(points []tango.Point, closed bool) []tango.Point {
+	cleaned := make([]tango.Point, 0, len(points))
+	for _, p := range points {
+		if len(cleaned) == 0 || !samePoint(cleaned[len(cleaned)-1], p) {
+			cleaned = append(cleaned, p)
+		}
+	}
+	if closed && len(cleaned) > 1 && samePoint(cleaned[0], cleaned[len(cleaned)-1]) {
+		cleaned = cleaned[:len(cleaned)-1]
+	}
+	return cleaned
+}
+
+func samePoint(a, b tango.Point) bool {
+	return math32.Abs(a.X-b.X) < 1e-6 && math32.Abs(a.Y-b.Y) < 1e-6
+}
+
+// arcSegments returns the number of segments approximating an arc of the given radius and angle, in radians, within
+// the curveTolerance, with at least 16 segments for a whole circle.
+func arcSegments(radius, angle float32) int {
+	step := float32(math32.Pi / 8)
+	if radius > curveTolerance {
+		step = math32.Min(step, 2*math32.Acos(1-curveTolerance/radius))
+	}
+	return int(math32.Max(1, math32.Ceil(math32.Abs(angle)/step)))
+}
+
+// appendArc appends the points of an elliptic arc around the center, from the start angle, in radians, along the
+// sweep.
+func appendArc(dst []tango.Point, center tango.Point, rx, ry, start, sweep float32) []tango.Point {
+	segments := arcSegments(math32.Max(rx, ry), sweep)
+	for i := 0; i <= segments; i++ {
+		sin, cos := math32.Sincos(start + sweep*float32(i)/float32(segments))
+		dst = append(dst, tango.Point{X: center.X + rx*cos, Y: center.Y + ry*sin})
+	}
+	return dst
+}
+
+// appendConvex appends the triangles filling a convex outline, fanning out from its first point.
+func appendConvex(dst, outline []tango.Point) []tango.Point {
+	for i := 1; i+1 < len(outline); i++ {
+		dst = append(dst, outline[0], outline[i], outline[i+1])
+	}
+	return dst
+}
+
+// appendFan appends the triangles filling a circle sector around the center, from the start angle, in radians, along
+// the sweep.
+func appendFan(dst []tango.Point, center tango.Point, radius, start, sweep float32) []tango.Point {
+	return appendConvex(dst, appendArc([]tango.Point{center}, center, radius, radius, start, sweep))
+}
+
+// strokePolyline appends the triangles of a stroke of the given width along the points.
+func strokePolyline(dst, points []tango.Point, width float32, join LineJoin, lineCap LineCap, closed bool) []tango.Point {
+	points = cleanPoints(points, closed)
+	if width <= 0 || len(points) == 0 {
+		return dst
+	}
+	half := width / 2
+
+	if len(points) == 1 {
+		// A stroke without length is a dot, if it has caps
+		p := points[0]
+		switch lineCap {
+		case LineCapSquare:
+			dst = appendConvex(dst, []tango.Point{
+				{X: p.X - half, Y: p.Y - half}, {X: p.X + half, Y: p.Y - half},
+				{X: p.X + half, Y: p.Y + half}, {X: p.X - half, Y: p.Y + half},
+			})
+		case LineCapRound:
+			dst = appendFan(dst, p, half, 0, 2*math32.Pi)
+		}
+		return dst
+	}
+	if len(points) == 2 {
+		closed = false
+	}
+
+	n := len(points)
+	if !closed && lineCap == LineCapSquare {
+		// The square caps are the segments at the ends extended by half the width
+		start, end := points[1], points[n-1]
+		start, _ = start.Subtract(points[0]).Normalize()
+		end, _ = end.Subtract(points[n-2]).Normalize()
+		points[0].Subtract(*start.MultiplyScalar(half))
+		points[n-1].Add(*end.MultiplyScalar(half))
+	}
+
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		a, b := points[i], points[(i+1)%n]
+		normal := strokeNormal(a, b)
+		normal.MultiplyScalar(half)
+		dst = append(dst,
+			tango.Point{X: a.X + normal.X, Y: a.Y + normal.Y},
+			tango.Point{X: a.X - normal.X, Y: a.Y - normal.Y},
+			tango.Point{X: b.X + normal.X, Y: b.Y + normal.Y},
+			tango.Point{X: b.X + normal.X, Y: b.Y + normal.Y},
+			tango.Point{X: a.X - normal.X, Y: a.Y - normal.Y},
+			tango.Point{X: b.X - normal.X, Y: b.Y - normal.Y},
+		)
+	}
+
+	for i := 0; i < n; i++ {
+		if !closed && (i == 0 || i == n-1) {
+			continue
+		}
+		dst = appendJoin(dst, points[(i+n-1)%n], points[i], points[(i+1)%n], half, join)
+	}
+
+	if !closed && lineCap == LineCapRound {
+		start := strokeNormal(points[0], points[1])
+		end := strokeNormal(points[n-2], points[n-1])
+		dst = appendFan(dst, points[0], half, math32.Atan2(start.Y, start.X), math32.Pi)
+		dst = appendFan(dst, points[n-1], half, math32.Atan2(end.Y, end.X), -math32.Pi)
+	}
+	return dst
+}
+
+// strokeNormal returns the unit normal of the segment from a to b, which is its direction rotated by 90 degrees.
+func strokeNormal(a, b tango.Point) tango.Point {
+	direction, _ := b.Subtract(a).Normalize()
+	return tango.Point{X: -direction.Y, Y: direction.X}
+}
+
+// appendJoin appends the triangles joining the segments from prev to p and from p to next, on the outside of the
+// corner. The inside is covered by the segments overlapping.
+func appendJoin(dst []tango.Point, prev, p, next tango.Point, half float32, join LineJoin) []tango.Point {
+	n0, n1 := strokeNormal(prev, p), strokeNormal(p, next)
+	cross := n0.X*n1.Y - n0.Y*n1.X
+	dot := n0.X*n1.X + n0.Y*n1.Y
+	if math32.Abs(cross) < 1e-6 && dot > 0 {
+		// The segments are in line
+		return dst
+	}
+	if cross > 0 {
+		// The segments turn towards their normals, so the outside is the other way
+		n0.MultiplyScalar(-1)
+		n1.MultiplyScalar(-1)
+	}
+	a := tango.Point{X: p.X + n0.X*half, Y: p.Y + n0.Y*half}
+	b := tango.Point{X: p.X + n1.X*half, Y: p.Y + n1.Y*half}
+
+	switch join {
+	case LineJoinRound:
+		return appendFan(dst, p, half, math32.Atan2(n0.Y, n0.X), math32.Atan2(n0.X*n1.Y-n0.Y*n1.X, dot))
+	case LineJoinMiter:
+		// The miter points along the sum of the normals, which is twice the cosine of half the angle between them
+		mid := tango.Point{X: n0.X + n1.X, Y: n0.Y + n1.Y}
+		if cos := mid.Length() / 2; cos > 1/float32(miterLimit) {
+			length := half / cos
+			m := tango.Point{X: p.X + mid.X/(2*cos)*length, Y: p.Y + mid.Y/(2*cos)*length}
+			return append(dst, p, a, m, p, m, b)
+		}
+	}
+	return append(dst, p, a, b)
+}
+
+// signedArea returns the area of the outline, which is positive if it goes around with increasing angles.
+func signedArea(outline []tango.Point) float32 {
+	var area float32
+	for i, a := range outline {
+		b := outline[(i+1)%len(outline)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+func reversePoints(points []tango.Point) {
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+}
+
+// triangulatePolygon returns the triangles filling the outline, without the holes. The holes are joined to the
+// outline with bridges, which turns it into a single outline that can be split into triangles by ear clipping.
+func triangulatePolygon(outline []tango.Point, holes [][]tango.Point) []tango.Point {
+	polygon := cleanPoints(outline, true)
+	if len(polygon) < 3 {
+		return nil
+	}
+	if signedArea(polygon) < 0 {
+		reversePoints(polygon)
+	}
+
+	// The holes are bridged from right to left, so a bridge never crosses a hole which isn't bridged yet
+	var sorted [][]tango.Point
+	for _, hole := range holes {
+		if hole = cleanPoints(hole, true); len(hole) >= 3 {
+			sorted = append(sorted, hole)
+		}
+	}
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && rightmost(sorted[j-1]).X < rightmost(sorted[j]).X; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	for _, hole := range sorted {
+		if signedArea(hole) > 0 {
+			reversePoints(hole)
+		}
+		polygon = bridgeHole(polygon, hole)
+	}
+
+	return earClip(polygon)
+}
+
+// rightmost returns the point with the largest X.
+func rightmost(points []tango.Point) tango.Point {
+	return points[rightmostIndex(points)]
+}
+
+func rightmostIndex(points []tango.Point) int {
+	index := 0
+	for i, p := range points {
+		if p.X > points[index].X {
+			index = i
+		}
+	}
+	return index
+}
+
+// bridgeHole joins the hole to the polygon, by going from a point of the polygon to the rightmost point of the hole,
+// around the hole, and back. The point of the polygon is one which can be seen from the hole, found by going right.
+func bridgeHole(polygon, hole []tango.Point) []tango.Point {
+	m := rightmostIndex(hole)
+	hp := hole[m]
+
+	// Find the closest edge to the right of the hole
+	bridge := -1
+	closest := float32(math32.MaxFloat32)
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+		if (a.Y > hp.Y) == (b.Y > hp.Y) {
+			continue
+		}
+		x := a.X + (hp.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+		if x < hp.X || x >= closest {
+			continue
+		}
+		closest = x
+		// The end of the edge furthest to the right is the candidate
+		bridge = i
+		if b.X > a.X {
+			bridge = (i + 1) % len(polygon)
+		}
+	}
+	if bridge < 0 {
+		return polygon
+	}
+
+	// Other points of the polygon may be in the way, in which case the one at the smallest angle is seen instead
+	intersection := tango.Point{X: closest, Y: hp.Y}
+	candidate := polygon[bridge]
+	best := float32(math32.MaxFloat32)
+	for i, p := range polygon {
+		if i == bridge || samePoint(p, candidate) || !pointInTriangle(p, hp, intersection, candidate) {
+			continue
+		}
+		angle := math32.Abs(math32.Atan2(p.Y-hp.Y, p.X-hp.X))
+		if angle < best {
+			best = angle
+			bridge = i
+		}
+	}
+
+	bridged := make([]tango.Point, 0, len(polygon)+len(hole)+2)
+	bridged = append(bridged, polygon[:bridge+1]...)
+	for i := 0; i <= len(hole); i++ {
+		bridged = append(bridged, hole[(m+i)%len(hole)])
+	}
+	bridged = append(bridged, polygon[bridge])
+	return append(bridged, polygon[bridge+1:]...)
+}
+
+// pointInTriangle returns whether p is within the triangle abc, or on its edges.
+func pointInTriangle(p, a, b, c tango.Point) bool {
+	d1 := (p.X-b.X)*(a.Y-b.Y) - (a.X-b.X)*(p.Y-b.Y)
+	d2 := (p.X-c.X)*(b.Y-c.Y) - (b.X-c.X)*(p.Y-c.Y)
+	d3 := (p.X-a.X)*(c.Y-a.Y) - (c.X-a.X)*(p.Y-a.Y)
+	negative := d1 < 0 || d2 < 0 || d3 < 0
+	positive := d1 > 0 || d2 > 0 || d3 > 0
+	return !(negative && positive)
+}
+
+// earClip splits an outline with a positive signedArea into triangles, by cutting off the corners which don't
+// contain any other point of the outline.
+func earClip(polygon []tango.Point) []tango.Point {
+	indices := make([]int, len(polygon))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	var triangles []tango.Point
+	for i := 0; len(indices) > 3; {
+		n := len(indices)
+		ear := -1
+		for tries := 0; tries < n; tries++ {
+			j := (i + tries) % n
+			if isEar(polygon, indices, j) {
+				ear = j
+				break
+			}
+		}
+		if ear < 0 {
+			// Only degenerate corners are left, such as points in line, so one is cut off without a triangle
+			indices = append(indices[:i%n], indices[i%n+1:]...)
+			continue
+		}
+
+		a, b, c := polygon[indices[(ear+n-1)%n]], polygon[indices[ear]], polygon[indices[(ear+1)%n]]
+		triangles = append(triangles, a, b, c)
+		indices = append(indices[:ear], indices[ear+1:]...)
+		i = ear % len(indices)
+	}
+	a, b, c := polygon[indices[0]], polygon[indices[1]], polygon[indices[2]]
+	if (b.X-a.X)*(c.Y-b.Y)-(b.Y-a.Y)*(c.X-b.X) > 0 {
+		triangles = append(triangles, a, b, c)
+	}
+	return triangles
+}
+
+// isEar returns whether the corner at index j of the remaining outline is convex, and doesn't contain any other point.
+func isEar(polygon []tango.Point, indices []int, j int) bool {
+	n := len(indices)
+	a, b, c := polygon[indices[(j+n-1)%n]], polygon[indices[j]], polygon[indices[(j+1)%n]]
+	if (b.X-a.X)*(c.Y-b.Y)-(b.Y-a.Y)*(c.X-b.X) <= 0 {
+		return false
+	}
+	for _, index := range indices {
+		p := polygon[index]
+		if samePoint(p, a) || samePoint(p, b) || samePoint(p, c) {
+			continue
+		}
+		if pointInTriangle(p, a, b, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/math32"
+	"github.com/stretchr/testify/assert"
+)
+
+// trianglesArea returns the area covered by the triangles, counting overlapping parts more than once
+func trianglesArea(triangles []tango.Point) float32 {
+	var area float32
+	for i := 0; i+2 < len(triangles); i += 3 {
+		area += math32.Abs(signedArea(triangles[i : i+3]))
+	}
+	return area
+}
+
+// trianglesContain returns whether any of the triangles contains the point
+func trianglesContain(triangles []tango.Point, p tango.Point) bool {
+	for i := 0; i+2 < len(triangles); i += 3 {
+		if pointInTriangle(p, triangles[i], triangles[i+1], triangles[i+2]) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStrokePolylineCaps(t *testing.T) {
+	line := []tango.Point{{X: 0, Y: 0}, {X: 10, Y: 0}}
+
+	butt := strokePolyline(nil, line, 2, LineJoinMiter, LineCapButt, false)
+	assert.Len(t, butt, 6, "A straight line should be a quad")
+	assert.InDelta(t, 20, trianglesArea(butt), 1e-4)
+	assert.False(t, trianglesContain(butt, tango.Point{X: -0.5}), "Butt caps should end at the end points")
+
+	square := strokePolyline(nil, line, 2, LineJoinMiter, LineCapSquare, false)
+	assert.InDelta(t, 24, trianglesArea(square), 1e-4, "Square caps should extend the line by half its width")
+	assert.True(t, trianglesContain(square, tango.Point{X: -0.5}))
+
+	round := strokePolyline(nil, line, 2, LineJoinMiter, LineCapRound, false)
+	assert.InEpsilon(t, 20+math32.Pi, trianglesArea(round), 0.01, "Round caps should add half a circle at each end")
+	assert.True(t, trianglesContain(round, tango.Point{X: 10.9}))
+	assert.False(t, trianglesContain(round, tango.Point{X: 10.9, Y: 0.9}))
+
+	assert.Empty(t, strokePolyline(nil, line, 0, LineJoinMiter, LineCapButt, false), "A line without width shouldn't be drawn")
+	assert.InEpsilon(t, math32.Pi, trianglesArea(strokePolyline(nil, line[:1], 2, LineJoinMiter, LineCapRound, false)), 0.03,
+		"A single point with round caps should be a dot")
+}
+
+func TestStrokePolylineJoins(t *testing.T) {
+	corner := []tango.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}}
+
+	miter := strokePolyline(nil, corner, 2, LineJoinMiter, LineCapButt, false)
+	assert.True(t, trianglesContain(miter, tango.Point{X: 10.95, Y: -0.95}), "The miter should meet at the outer corner")
+	assert.False(t, trianglesContain(miter, tango.Point{X: 11.05, Y: -1.05}))
+
+	bevel := strokePolyline(nil, corner, 2, LineJoinBevel, LineCapButt, false)
+	assert.False(t, trianglesContain(bevel, tango.Point{X: 10.9, Y: -0.9}), "The bevel should cut off the corner")
+	assert.True(t, trianglesContain(bevel, tango.Point{X: 10.4, Y: -0.4}))
+
+	round := strokePolyline(nil, corner, 2, LineJoinRound, LineCapButt, false)
+	assert.True(t, trianglesContain(round, tango.Point{X: 10.6, Y: -0.6}), "The round join should be a circle around the corner")
+	assert.False(t, trianglesContain(round, tango.Point{X: 10.9, Y: -0.9}))
+
+	sharp := []tango.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 1}}
+	assert.False(t, trianglesContain(strokePolyline(nil, sharp, 2, LineJoinMiter, LineCapButt, false), tango.Point{X: 12, Y: 0}),
+		"Miters longer than the limit should be beveled")
+
+	square := []tango.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	closed := strokePolyline(nil, square, 2, LineJoinMiter, LineCapButt, true)
+	assert.True(t, trianglesContain(closed, tango.Point{X: -0.9, Y: -0.9}), "A closed polyline should be joined at the first point")
+	assert.False(t, trianglesContain(closed, tango.Point{X: 5, Y: 5}))
+}
+
+func TestTriangulatePolygon(t *testing.T) {
+	concave := []tango.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 5, Y: 10}, {X: 5, Y: 5}, {X: 0, Y: 5}}
+	triangles := triangulatePolygon(concave, nil)
+	assert.Len(t, triangles, 3*4, "A polygon should be split into two triangles less than its number of points")
+	assert.InDelta(t, 75, trianglesArea(triangles), 1e-4)
+	assert.False(t, trianglesContain(triangles, tango.Point{X: 2, Y: 8}), "The concave part shouldn't be filled")
+
+	reversePoints(concave)
+	assert.InDelta(t, 75, trianglesArea(triangulatePolygon(concave, nil)), 1e-4, "The outline may go either way around")
+
+	outline := []tango.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	holes := [][]tango.Point{
+		{{X: 1, Y: 1}, {X: 4, Y: 1}, {X: 4, Y: 4}, {X: 1, Y: 4}},
+		{{X: 6, Y: 6}, {X: 6, Y: 9}, {X: 9, Y: 9}, {X: 9, Y: 6}},
+	}
+	triangles = triangulatePolygon(outline, holes)
+	assert.InDelta(t, 100-9-9, trianglesArea(triangles), 1e-3, "The holes should be cut out of the polygon")
+	assert.False(t, trianglesContain(triangles, tango.Point{X: 2.5, Y: 2.5}))
+	assert.False(t, trianglesContain(triangles, tango.Point{X: 7.5, Y: 7.5}))
+	assert.True(t, trianglesContain(triangles, tango.Point{X: 7.5, Y: 2.5}))
+
+	assert.Empty(t, triangulatePolygon(outline[:2], nil), "An outline without area shouldn't be filled")
+}
+
+func TestVectorShapesTessellate(t *testing.T) {
+	fill, border := RoundedRectangle{Radius: 5}.tessellate(40, 20)
+	assert.InEpsilon(t, 40*20-(4-math32.Pi)*25, trianglesArea(fill), 0.01, "The corners should be rounded")
+	assert.Empty(t, border)
+	assert.False(t, trianglesContain(fill, tango.Point{X: 0.5, Y: 0.5}))
+
+	fill, border = RoundedRectangle{Radius: 50, BorderWidth: 2, BorderColor: color.Black}.tessellate(40, 20)
+	assert.InEpsilon(t, 20*20+math32.Pi*100, trianglesArea(fill), 0.01, "The radius should be reduced to fit")
+	assert.True(t, trianglesContain(border, tango.Point{X: 20, Y: 1}), "The border should be on the inside")
+	assert.False(t, trianglesContain(border, tango.Point{X: 20, Y: 2.1}))
+
+	fill, _ = Arc{StartAngle: 0, EndAngle: 90}.tessellate(20, 20)
+	assert.InEpsilon(t, math32.Pi*100/4, trianglesArea(fill), 0.02, "An arc without a width should be filled")
+	assert.True(t, trianglesContain(fill, tango.Point{X: 15, Y: 15}), "The angles should go clockwise from the right")
+	assert.False(t, trianglesContain(fill, tango.Point{X: 15, Y: 5}))
+
+	fill, _ = Arc{StartAngle: 0, EndAngle: 360, LineWidth: 2}.tessellate(20, 20)
+	assert.InEpsilon(t, math32.Pi*(100-64), trianglesArea(fill), 0.03, "A full arc should be a ring within the area")
+
+	fill, _ = Line{From: tango.Point{X: 0, Y: 0.5}, To: tango.Point{X: 1, Y: 0.5}, LineWidth: 2}.tessellate(10, 4)
+	assert.InDelta(t, 20, trianglesArea(fill), 1e-4, "The points should be scaled to the size of the SpaceComponent")
+
+	fill, border = Polygon{
+		Points:      []tango.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		Holes:       [][]tango.Point{{{X: 0.25, Y: 0.25}, {X: 0.75, Y: 0.25}, {X: 0.75, Y: 0.75}, {X: 0.25, Y: 0.75}}},
+		BorderWidth: 2,
+		BorderColor: color.Black,
+	}.tessellate(20, 20)
+	assert.InDelta(t, 400-100, trianglesArea(fill), 1e-3)
+	assert.True(t, trianglesContain(border, tango.Point{X: 5.5, Y: 10}), "The holes should have a border as well")
+}
+
+func TestVectorBufferContent(t *testing.T) {
+	ren := &RenderComponent{Drawable: Line{To: tango.Point{X: 1}, LineWidth: 2}, Color: color.White}
+	space := &SpaceComponent{Width: 10}
+	buffer, changed := vectorBufferContent(ren.Drawable.(vectorShape), ren, space, nil)
+	assert.True(t, changed)
+	assert.Len(t, buffer, 6*3)
+	assert.Equal(t, colorToFloat32(color.White), buffer[2])
+
+	_, changed = vectorBufferContent(ren.Drawable.(vectorShape), ren, space, buffer)
+	assert.False(t, changed, "The same shape shouldn't change the buffer")
+
+	ren.Drawable = Polyline{Points: []tango.Point{{}, {X: 1}, {X: 1, Y: 1}}, LineWidth: 2, Join: LineJoinBevel}
+	space.Height = 10
+	buffer, changed = vectorBufferContent(ren.Drawable.(vectorShape), ren, space, buffer)
+	assert.True(t, changed)
+	assert.Len(t, buffer, (6+6+3)*3, "The buffer should grow to fit the triangles")
+}
+
+func TestVectorShapesSoftwareRendering(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	w, rs := newSoftwareTestWorld()
+
+	polygon := addSoftwareTestEntity(rs, Polygon{
+		Points: []tango.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		Holes:  [][]tango.Point{{{X: 0.25, Y: 0.25}, {X: 0.75, Y: 0.25}, {X: 0.75, Y: 0.75}, {X: 0.25, Y: 0.75}}},
+	}, 10, 10, 40, 40)
+	polygon.Color = color.RGBA{R: 0xff, A: 0xff}
+	line := addSoftwareTestEntity(rs, Line{From: tango.Point{Y: 0.5}, To: tango.Point{X: 1, Y: 0.5}, LineWidth: 4, Cap: LineCapRound}, 100, 40, 50, 20)
+	line.Color = color.RGBA{G: 0xff, A: 0xff}
+
+	w.Update(1)
+	assertSoftwarePixel(t, color.RGBA{R: 0xff, A: 0xff}, 12, 12, "The polygon should be filled")
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 30, 30, "The hole shouldn't be filled")
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 125, 50, "The line should be drawn")
+	assertSoftwarePixel(t, color.RGBA{G: 0xff, A: 0xff}, 98, 50, "The line should have round caps")
+	assertSoftwarePixel(t, color.RGBA{A: 0xff}, 125, 53)
+}
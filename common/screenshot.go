@@ -0,0 +1,168 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+)
+
+// FrameRecorderPriority is the priority of the FrameRecorder, which records the frames after the RenderSystem
+// has drawn them.
+const FrameRecorderPriority = RenderSystemPriority - 10
+
+// ErrNoFrame is returned by Screenshot when running headless without SoftwareRendering, as nothing is drawn.
+var ErrNoFrame = errors.New("no frame is drawn while running headless without SoftwareRendering")
+
+// Screenshot returns what has been drawn so far this frame. It reads the framebuffer which is bound, which is the
+// canvas, or the offscreen target while rendering to one, such as a RenderTexture or the scene of the
+// post-processing passes. When headless, it returns a copy of the SoftwareCanvas.
+//
+// The canvas is shown opaque, so the screenshot is too. Call it after the RenderSystem has drawn the frame, such as
+// from a system with a lower priority; once the frame is shown, what's left in the canvas is undefined.
+func Screenshot() (*image.NRGBA, error) {
+	if tango.Headless() {
+		if !SoftwareRendering || softwareCanvas == nil {
+			return nil, ErrNoFrame
+		}
+		return opaqueScreenshot(softwareCanvas.Rect.Dx(), softwareCanvas.Rect.Dy(), softwareCanvas.Pix, false), nil
+	}
+
+	viewport := tango.Gl.GetViewport()
+	width, height := int(viewport[2]), int(viewport[3])
+	pixels := make([]uint8, width*height*4)
+	tango.Gl.ReadPixels(int(viewport[0]), int(viewport[1]), width, height, tango.Gl.RGBA, tango.Gl.UNSIGNED_BYTE, pixels)
+	if err := tango.Gl.GetError(); err != 0 {
+		return nil, fmt.Errorf("reading the pixels failed with OpenGL error %d", err)
+	}
+	// OpenGL returns the rows from the bottom up
+	return opaqueScreenshot(width, height, pixels, true), nil
+}
+
+// opaqueScreenshot copies the pixels into an image, without their alpha
+func opaqueScreenshot(width, height int, pixels []uint8, flip bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	stride := width * 4
+	for y := 0; y < height; y++ {
+		src := y
+		if flip {
+			src = height - 1 - y
+		}
+		row := img.Pix[y*img.Stride : y*img.Stride+stride]
+		copy(row, pixels[src*stride:src*stride+stride])
+		for i := 3; i < len(row); i += 4 {
+			row[i] = 0xff
+		}
+	}
+	return img
+}
+
+// FrameRecorder is a system which records a number of frames with Screenshot, and writes them as a sequence of PNG
+// images, or as an animated GIF. Recording starts when it's added to the World, and it stops by itself once all
+// frames are recorded, or on the first error.
+type FrameRecorder struct {
+	// Frames is the number of frames to record.
+	Frames int
+	// Path is where the frames are written. With the .gif extension, they're written there as an animated GIF when
+	// the last one is recorded. Otherwise, each frame is written as a PNG image, with the path formatted with the
+	// number of the frame, starting at 0, such as "frames/%04d.png".
+	Path string
+
+	recorded int
+	elapsed  float32
+	gif      *gif.GIF
+	err      error
+}
+
+// New is called when the FrameRecorder is added to the World.
+func (f *FrameRecorder) New(w *ecs.World) {
+	f.recorded = 0
+	f.elapsed = 0
+	f.err = nil
+	f.gif = nil
+	if strings.EqualFold(filepath.Ext(f.Path), ".gif") {
+		f.gif = &gif.GIF{}
+	}
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*FrameRecorder) Priority() int { return FrameRecorderPriority }
+
+// Remove does nothing because the FrameRecorder has no entities. It implements the ecs.System interface.
+func (*FrameRecorder) Remove(ecs.BasicEntity) {}
+
+// Update records the frame drawn by the RenderSystem.
+func (f *FrameRecorder) Update(dt float32) {
+	if f.Done() {
+		return
+	}
+
+	img, err := Screenshot()
+	if err != nil {
+		f.err = err
+		return
+	}
+
+	if f.gif == nil {
+		f.err = writePNG(fmt.Sprintf(f.Path, f.recorded), img)
+		f.recorded++
+		return
+	}
+
+	frame := image.NewPaletted(img.Rect, palette.Plan9)
+	draw.FloydSteinberg.Draw(frame, img.Rect, img, image.Point{})
+	// The delays are in hundredths of a second, so they're rounded from the total time to not drift apart
+	before := int(f.elapsed*100 + 0.5)
+	f.elapsed += dt
+	f.gif.Image = append(f.gif.Image, frame)
+	f.gif.Delay = append(f.gif.Delay, int(f.elapsed*100+0.5)-before)
+	f.recorded++
+
+	if f.recorded == f.Frames {
+		f.err = writeGIF(f.Path, f.gif)
+		f.gif = nil
+	}
+}
+
+// Done returns whether all frames are recorded, or recording stopped because of an error.
+func (f *FrameRecorder) Done() bool {
+	return f.recorded >= f.Frames || f.err != nil
+}
+
+// Err returns the error which stopped the recording, if any.
+func (f *FrameRecorder) Err() error {
+	return f.err
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeGIF(path string, g *gif.GIF) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = gif.EncodeAll(file, g); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
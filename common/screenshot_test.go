@@ -0,0 +1,88 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScreenshot(t *testing.T) {
+	w, rs := newSoftwareTestWorld()
+	w.Update(1)
+	_, err := Screenshot()
+	assert.Equal(t, ErrNoFrame, err, "Nothing should be drawn without SoftwareRendering")
+
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+	render := addSoftwareTestEntity(rs, Rectangle{}, 10, 10, 20, 20)
+	render.Color = color.NRGBA{R: 0xff, A: 0x80}
+	w.Update(1)
+
+	img, err := Screenshot()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, image.Rect(0, 0, 200, 100), img.Rect)
+	assert.Equal(t, uint8(0xff), img.NRGBAAt(15, 15).A, "The screenshot should be opaque")
+	assert.InDelta(t, 0x40, img.NRGBAAt(15, 15).R, 1)
+	assert.Equal(t, color.NRGBA{A: 0xff}, img.NRGBAAt(5, 5))
+
+	img.Pix[0] = 0xff
+	assert.Equal(t, uint8(0), SoftwareCanvas().Pix[0], "The screenshot should be a copy")
+}
+
+func TestFrameRecorder(t *testing.T) {
+	SoftwareRendering = true
+	defer func() { SoftwareRendering = false }()
+
+	dir, err := ioutil.TempDir("", "tango")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	w, rs := newSoftwareTestWorld()
+	addSoftwareTestEntity(rs, Rectangle{}, 10, 10, 20, 20)
+	sequence := &FrameRecorder{Frames: 2, Path: filepath.Join(dir, "frame%02d.png")}
+	animation := &FrameRecorder{Frames: 3, Path: filepath.Join(dir, "animation.gif")}
+	w.AddSystem(sequence)
+	w.AddSystem(animation)
+	for i := 0; i < 4; i++ {
+		w.Update(0.016)
+	}
+	assert.True(t, sequence.Done())
+	assert.NoError(t, sequence.Err())
+	assert.NoError(t, animation.Err())
+
+	for _, name := range []string{"frame00.png", "frame01.png"} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if !assert.NoError(t, err, "A PNG should be written for each frame") {
+			continue
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if assert.NoError(t, err) {
+			assert.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+		}
+	}
+	_, err = os.Stat(filepath.Join(dir, "frame02.png"))
+	assert.True(t, os.IsNotExist(err), "No more frames than asked for should be recorded")
+
+	file, err := os.Open(filepath.Join(dir, "animation.gif"))
+	if !assert.NoError(t, err, "The GIF should be written after the last frame") {
+		return
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if assert.NoError(t, err) {
+		assert.Len(t, g.Image, 3)
+		assert.Equal(t, []int{2, 1, 2}, g.Delay, "The delays should add up to the time recorded")
+	}
+}
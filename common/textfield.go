@@ -0,0 +1,594 @@
+package common
+
+import (
+	"image/color"
+	"log"
+	"sync"
+	"unicode"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+)
+
+const (
+	// defaultCaretBlinkRate is the default amount of seconds the caret of a TextField is visible or hidden
+	defaultCaretBlinkRate = 0.5
+	// caretWidth is the width of the caret of a TextField, in pixels
+	caretWidth = 2
+)
+
+var (
+	// defaultSelectionColor is the color used to highlight selected text when TextField.SelectionColor is not set
+	defaultSelectionColor = color.NRGBA{R: 51, G: 153, B: 255, A: 128}
+)
+
+// TextField is a single-line editable text, which is rendered using `Text`. It keeps track of the caret, the
+// selection and the part of the text that is visible, scrolling horizontally whenever the text is wider than the
+// `SpaceComponent` of the entity. Add it to the TextFieldSystem to be able to edit it.
+type TextField struct {
+	// Font is the Font used to render the text.
+	Font *Font
+	// Text is the current content of the TextField.
+	Text string
+	// MaxLength is the maximum amount of characters the TextField can contain. Zero means there is no limit.
+	MaxLength int
+	// CaretColor is the color of the caret. Defaults to the foreground color of the Font.
+	CaretColor color.Color
+	// SelectionColor is the color used to highlight the selected text. Defaults to a transparent blue.
+	SelectionColor color.Color
+	// BlinkRate is the amount of seconds the caret is visible or hidden while blinking. Defaults to 0.5 seconds,
+	// a negative value disables blinking.
+	BlinkRate float32
+
+	// caret and anchor are the positions (in runes) of the caret and the other end of the selection. They are the
+	// same when nothing is selected.
+	caret, anchor int
+	// offset is the first visible rune, used to scroll the text horizontally
+	offset int
+
+	focused bool
+
+	// measure returns the width of the rune when rendered, defaults to using the FontAtlas of the Font
+	measure func(r rune) float32
+}
+
+// Focused returns whether or not the TextField is currently receiving the text input.
+func (f *TextField) Focused() bool {
+	return f.focused
+}
+
+// Caret returns the position of the caret, in runes.
+func (f *TextField) Caret() int {
+	f.clamp()
+	return f.caret
+}
+
+// SetCaret moves the caret to the given position, in runes. If selecting is true, the selection is extended up to
+// the new position, otherwise the selection is cleared.
+func (f *TextField) SetCaret(pos int, selecting bool) {
+	f.caret = pos
+	if !selecting {
+		f.anchor = pos
+	}
+	f.clamp()
+}
+
+// Selection returns the start and end position (in runes) of the selected text. They are equal if nothing is
+// selected.
+func (f *TextField) Selection() (start, end int) {
+	f.clamp()
+	if f.anchor < f.caret {
+		return f.anchor, f.caret
+	}
+	return f.caret, f.anchor
+}
+
+// SelectedText returns the selected text.
+func (f *TextField) SelectedText() string {
+	start, end := f.Selection()
+	return string([]rune(f.Text)[start:end])
+}
+
+// SelectAll selects all of the text.
+func (f *TextField) SelectAll() {
+	f.anchor = 0
+	f.caret = len([]rune(f.Text))
+}
+
+// Insert replaces the selection with the given text, and moves the caret to the end of the inserted text. Control
+// characters (such as newlines) are ignored, and the text is truncated when it would exceed MaxLength.
+func (f *TextField) Insert(text string) {
+	insert := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsControl(r) {
+			continue
+		}
+		insert = append(insert, r)
+	}
+
+	start, end := f.Selection()
+	current := []rune(f.Text)
+
+	if f.MaxLength > 0 {
+		available := f.MaxLength - len(current) + (end - start)
+		if available < 0 {
+			available = 0
+		}
+		if len(insert) > available {
+			insert = insert[:available]
+		}
+	}
+
+	result := make([]rune, 0, len(current)-(end-start)+len(insert))
+	result = append(result, current[:start]...)
+	result = append(result, insert...)
+	result = append(result, current[end:]...)
+
+	f.Text = string(result)
+	f.SetCaret(start+len(insert), false)
+}
+
+// edit applies the given TextEditMessage to the TextField.
+func (f *TextField) edit(msg tango.TextEditMessage) {
+	start, end := f.Selection()
+	text := []rune(f.Text)
+
+	switch msg.Action {
+	case tango.TextEditBackspace:
+		if start == end && start > 0 {
+			f.anchor = start - 1
+		}
+		f.Insert("")
+	case tango.TextEditDelete:
+		if start == end && end < len(text) {
+			f.anchor = end + 1
+		}
+		f.Insert("")
+	case tango.TextEditLeft:
+		if start != end && !msg.Select {
+			f.SetCaret(start, false)
+			return
+		}
+		f.SetCaret(f.caret-1, msg.Select)
+	case tango.TextEditRight:
+		if start != end && !msg.Select {
+			f.SetCaret(end, false)
+			return
+		}
+		f.SetCaret(f.caret+1, msg.Select)
+	case tango.TextEditWordLeft:
+		f.SetCaret(previousWord(text, f.caret), msg.Select)
+	case tango.TextEditWordRight:
+		f.SetCaret(nextWord(text, f.caret), msg.Select)
+	case tango.TextEditHome:
+		f.SetCaret(0, msg.Select)
+	case tango.TextEditEnd:
+		f.SetCaret(len(text), msg.Select)
+	case tango.TextEditSelectAll:
+		f.SelectAll()
+	case tango.TextEditCopy:
+		if start != end {
+			tango.SetClipboard(f.SelectedText())
+		}
+	case tango.TextEditCut:
+		if start != end {
+			tango.SetClipboard(f.SelectedText())
+			f.Insert("")
+		}
+	case tango.TextEditPaste:
+		f.Insert(tango.GetClipboard())
+	}
+}
+
+// clamp makes sure the caret, anchor and offset are within the bounds of the text, as Text may have been changed
+// directly.
+func (f *TextField) clamp() {
+	n := len([]rune(f.Text))
+	f.caret = clampInt(f.caret, 0, n)
+	f.anchor = clampInt(f.anchor, 0, n)
+	f.offset = clampInt(f.offset, 0, n)
+}
+
+// display returns the runes that should be displayed, and the position of the caret within them.
+func (f *TextField) display() ([]rune, int) {
+	f.clamp()
+	return []rune(f.Text), f.caret
+}
+
+// advances returns the rendered width of each of the given runes.
+func (f *TextField) advances(text []rune) []float32 {
+	if f.measure == nil {
+		f.measure = func(r rune) float32 {
+			return Text{Font: f.Font, Text: string(r)}.Width()
+		}
+	}
+
+	advances := make([]float32, len(text))
+	for i, r := range text {
+		advances[i] = f.measure(r)
+	}
+	return advances
+}
+
+// scroll updates the first visible rune so that the caret is always visible within the given width, and returns
+// the last visible rune (exclusive). A width of zero or less disables scrolling.
+func (f *TextField) scroll(advances []float32, caret int, width float32) int {
+	if width <= 0 {
+		f.offset = 0
+		return len(advances)
+	}
+
+	if f.offset > caret {
+		f.offset = caret
+	}
+	for f.offset < caret && sumFloat32(advances[f.offset:caret]) > width {
+		f.offset++
+	}
+	// Scroll back whenever there's room to show more of the text, e.g. after deleting
+	for f.offset > 0 && sumFloat32(advances[f.offset-1:]) <= width {
+		f.offset--
+	}
+
+	end := f.offset
+	visible := float32(0)
+	for end < len(advances) && visible+advances[end] <= width {
+		visible += advances[end]
+		end++
+	}
+	if end < caret {
+		end = caret
+	}
+
+	return end
+}
+
+// caretAt returns the position (in runes) which is closest to the given x-coordinate, relative to the start of the
+// visible text.
+func (f *TextField) caretAt(advances []float32, x float32) int {
+	pos := f.offset
+	current := float32(0)
+	for pos < len(advances) && current+advances[pos]/2 < x {
+		current += advances[pos]
+		pos++
+	}
+	return pos
+}
+
+// previousWord returns the position of the start of the word before pos.
+func previousWord(text []rune, pos int) int {
+	for pos > 0 && unicode.IsSpace(text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(text[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// nextWord returns the position of the end of the word after pos.
+func nextWord(text []rune, pos int) int {
+	for pos < len(text) && unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	for pos < len(text) && !unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func sumFloat32(values []float32) float32 {
+	var sum float32
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+// TextFieldSubmitMessage is dispatched whenever enter is pressed while a TextField has focus.
+type TextFieldSubmitMessage struct {
+	Entity *ecs.BasicEntity
+	Field  *TextField
+}
+
+// Type implements the tango.Message interface.
+func (TextFieldSubmitMessage) Type() string { return "TextFieldSubmitMessage" }
+
+// textFieldPart is an entity drawn by the TextFieldSystem on behalf of a TextField, such as the caret.
+type textFieldPart struct {
+	ecs.BasicEntity
+	RenderComponent
+	SpaceComponent
+}
+
+type textFieldEntity struct {
+	*ecs.BasicEntity
+	*TextField
+	*MouseComponent
+	*RenderComponent
+	*SpaceComponent
+
+	caretPart, selectionPart *textFieldPart
+}
+
+// TextFieldSystem handles the text input for TextFields. A TextField gets the focus either by being clicked (when
+// it has a MouseComponent and a MouseSystem was added to the World) or through `Focus`. The TextFieldSystem renders
+// the text, caret and selection of the TextFields using the RenderSystem, which must be added to the World before
+// the TextFieldSystem.
+type TextFieldSystem struct {
+	entities []*textFieldEntity
+	render   *RenderSystem
+	focused  *textFieldEntity
+
+	events   []tango.Message
+	eventsMu sync.Mutex
+	blink    float32
+}
+
+// New initializes the TextFieldSystem. It is run before any updates.
+func (t *TextFieldSystem) New(w *ecs.World) {
+	for _, system := range w.Systems() {
+		switch sys := system.(type) {
+		case *RenderSystem:
+			t.render = sys
+		}
+	}
+
+	if t.render == nil {
+		log.Println("ERROR: RenderSystem not found - have you added the `RenderSystem` before the `TextFieldSystem`?")
+	}
+
+	queue := func(msg tango.Message) {
+		t.eventsMu.Lock()
+		t.events = append(t.events, msg)
+		t.eventsMu.Unlock()
+	}
+	tango.Mailbox.ListenMessage(tango.TextCommitMessage{}, queue)
+	tango.Mailbox.ListenMessage(tango.TextEditMessage{}, queue)
+}
+
+// Add adds a new TextField to the TextFieldSystem. The MouseComponent is optional, and is only required when the
+// TextField should get the focus by clicking it. The Drawable of the RenderComponent is replaced by the `Text`
+// of the TextField. The Width of the SpaceComponent is the visible width of the text, a Width of zero disables
+// scrolling.
+func (t *TextFieldSystem) Add(basic *ecs.BasicEntity, field *TextField, mouse *MouseComponent, render *RenderComponent, space *SpaceComponent) {
+	e := &textFieldEntity{
+		BasicEntity:     basic,
+		TextField:       field,
+		MouseComponent:  mouse,
+		RenderComponent: render,
+		SpaceComponent:  space,
+		caretPart:       &textFieldPart{BasicEntity: ecs.NewBasic()},
+		selectionPart:   &textFieldPart{BasicEntity: ecs.NewBasic()},
+	}
+
+	render.Drawable = Text{Font: field.Font}
+
+	// The caret and selection are drawn on the HUD whenever the text is drawn on the HUD
+	var shader Shader
+	if render.StartShader == HUDShader || render.shader == HUDShader || render.shader == TextHUDShader {
+		shader = HUDShader
+	}
+
+	e.caretPart.Drawable = Rectangle{}
+	e.caretPart.StartShader = shader
+	e.caretPart.StartZIndex = render.StartZIndex + 1
+	e.caretPart.Hidden = true
+
+	e.selectionPart.Drawable = Rectangle{}
+	e.selectionPart.StartShader = shader
+	e.selectionPart.StartZIndex = render.StartZIndex - 1
+	e.selectionPart.Hidden = true
+
+	if t.render != nil {
+		t.render.Add(&e.caretPart.BasicEntity, &e.caretPart.RenderComponent, &e.caretPart.SpaceComponent)
+		t.render.Add(&e.selectionPart.BasicEntity, &e.selectionPart.RenderComponent, &e.selectionPart.SpaceComponent)
+	}
+
+	t.entities = append(t.entities, e)
+}
+
+// AddByInterface adds the Entity to the system as long as it satisfies, TextFieldable. Any Entity containing a
+// BasicEntity, TextField, RenderComponent and SpaceComponent automatically does this. The MouseComponent is used
+// when the Entity also contains one.
+func (t *TextFieldSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(TextFieldable)
+
+	var mouse *MouseComponent
+	if m, ok := i.(MouseFace); ok {
+		mouse = m.GetMouseComponent()
+	}
+
+	t.Add(o.GetBasicEntity(), o.GetTextField(), mouse, o.GetRenderComponent(), o.GetSpaceComponent())
+}
+
+// Remove removes an entity from the TextFieldSystem.
+func (t *TextFieldSystem) Remove(basic ecs.BasicEntity) {
+	var delete = -1
+	for index, entity := range t.entities {
+		if entity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete < 0 {
+		return
+	}
+
+	e := t.entities[delete]
+	if t.focused != nil && t.focused.ID() == e.ID() {
+		t.blur()
+	}
+	if t.render != nil {
+		t.render.Remove(e.caretPart.BasicEntity)
+		t.render.Remove(e.selectionPart.BasicEntity)
+	}
+	t.entities = append(t.entities[:delete], t.entities[delete+1:]...)
+}
+
+// Focus gives the focus to the TextField of the given entity, so it receives the text input.
+func (t *TextFieldSystem) Focus(basic *ecs.BasicEntity) {
+	for _, e := range t.entities {
+		if e.ID() == basic.ID() {
+			t.focus(e)
+			return
+		}
+	}
+}
+
+// Blur removes the focus from the TextField that currently has it, if any.
+func (t *TextFieldSystem) Blur() {
+	t.blur()
+}
+
+func (t *TextFieldSystem) focus(e *textFieldEntity) {
+	if t.focused != nil {
+		t.focused.focused = false
+	}
+
+	t.focused = e
+	e.focused = true
+	t.blink = 0
+	tango.Input.StartTextInput()
+}
+
+func (t *TextFieldSystem) blur() {
+	if t.focused == nil {
+		return
+	}
+
+	t.focused.focused = false
+	t.focused = nil
+	tango.Input.StopTextInput()
+}
+
+// Update handles the text input for the focused TextField, and updates what's rendered for all TextFields.
+func (t *TextFieldSystem) Update(dt float32) {
+	if tango.Input.Mouse.Action == tango.Press && tango.Input.Mouse.Button == tango.MouseButtonLeft {
+		var clicked *textFieldEntity
+		for _, e := range t.entities {
+			if e.MouseComponent != nil && e.MouseComponent.Clicked {
+				clicked = e
+			}
+		}
+
+		if clicked == nil {
+			t.blur()
+		} else {
+			if clicked != t.focused {
+				t.focus(clicked)
+			}
+			t.moveCaretToMouse(clicked, tango.Input.Mouse.Modifer&tango.Shift != 0)
+		}
+	}
+
+	if t.focused != nil && t.focused.MouseComponent != nil && t.focused.MouseComponent.Dragged {
+		t.moveCaretToMouse(t.focused, true)
+	}
+
+	t.eventsMu.Lock()
+	events := t.events
+	t.events = nil
+	t.eventsMu.Unlock()
+
+	if t.focused != nil {
+		for _, msg := range events {
+			t.handle(t.focused, msg)
+		}
+	}
+
+	t.blink += dt
+	for _, e := range t.entities {
+		t.layout(e)
+	}
+}
+
+func (t *TextFieldSystem) handle(e *textFieldEntity, msg tango.Message) {
+	// Restart blinking, so the caret is visible while typing
+	t.blink = 0
+
+	switch m := msg.(type) {
+	case tango.TextCommitMessage:
+		e.Insert(m.Text)
+	case tango.TextEditMessage:
+		if m.Action == tango.TextEditEnter {
+			tango.Mailbox.Dispatch(TextFieldSubmitMessage{Entity: e.BasicEntity, Field: e.TextField})
+			return
+		}
+		e.edit(m)
+	}
+}
+
+func (t *TextFieldSystem) moveCaretToMouse(e *textFieldEntity, selecting bool) {
+	text, _ := e.display()
+	scale := e.RenderComponent.Scale.X
+	if scale == 0 {
+		scale = 1
+	}
+
+	x := (e.MouseComponent.MouseX - e.SpaceComponent.Position.X) / scale
+	e.SetCaret(e.caretAt(e.advances(text), x), selecting)
+}
+
+// layout updates the visible text, caret and selection of the TextField.
+func (t *TextFieldSystem) layout(e *textFieldEntity) {
+	scale := e.RenderComponent.Scale
+	if scale.X == 0 || scale.Y == 0 {
+		scale = tango.Point{X: 1, Y: 1}
+	}
+
+	text, caret := e.display()
+	advances := e.advances(text)
+	end := e.scroll(advances, caret, e.SpaceComponent.Width/scale.X)
+
+	e.RenderComponent.Drawable = Text{Font: e.Font, Text: string(text[e.offset:end])}
+
+	height := e.SpaceComponent.Height
+	if height == 0 && e.Font != nil {
+		height = float32(e.Font.Size) * scale.Y
+	}
+	// x returns the x-coordinate of the given position in the text
+	x := func(pos int) float32 {
+		pos = clampInt(pos, e.offset, end)
+		return e.SpaceComponent.Position.X + sumFloat32(advances[e.offset:pos])*scale.X
+	}
+
+	e.caretPart.Hidden = !e.focused || e.RenderComponent.Hidden
+	if e.BlinkRate >= 0 {
+		rate := e.BlinkRate
+		if rate == 0 {
+			rate = defaultCaretBlinkRate
+		}
+		e.caretPart.Hidden = e.caretPart.Hidden || int(t.blink/rate)%2 == 1
+	}
+	e.caretPart.Color = e.CaretColor
+	if e.caretPart.Color == nil && e.Font != nil {
+		e.caretPart.Color = e.Font.FG
+	}
+	e.caretPart.SpaceComponent = SpaceComponent{
+		Position: tango.Point{X: x(caret), Y: e.SpaceComponent.Position.Y},
+		Width:    caretWidth,
+		Height:   height,
+	}
+
+	start, stop := e.Selection()
+	e.selectionPart.Hidden = !e.focused || e.RenderComponent.Hidden || start == stop
+	e.selectionPart.Color = e.SelectionColor
+	if e.selectionPart.Color == nil {
+		e.selectionPart.Color = defaultSelectionColor
+	}
+	e.selectionPart.SpaceComponent = SpaceComponent{
+		Position: tango.Point{X: x(start), Y: e.SpaceComponent.Position.Y},
+		Width:    x(stop) - x(start),
+		Height:   height,
+	}
+}
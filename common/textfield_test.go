@@ -0,0 +1,134 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTextField returns a TextField where every rune is 10 pixels wide, so no Font is needed.
+func newTestTextField(text string) *TextField {
+	f := &TextField{Text: text}
+	f.measure = func(rune) float32 { return 10 }
+	f.SetCaret(len([]rune(text)), false)
+	return f
+}
+
+func TestTextFieldInsert(t *testing.T) {
+	f := newTestTextField("hello")
+
+	f.Insert(" world\n")
+	assert.Equal(t, "hello world", f.Text, "Control characters should not be inserted")
+	assert.Equal(t, 11, f.Caret())
+
+	f.SetCaret(0, false)
+	f.SetCaret(5, true)
+	assert.Equal(t, "hello", f.SelectedText())
+
+	f.Insert("héllo")
+	assert.Equal(t, "héllo world", f.Text, "Inserting should replace the selection")
+	assert.Equal(t, 5, f.Caret())
+
+	f.MaxLength = 13
+	f.Insert("abc")
+	assert.Equal(t, "hélloab world", f.Text, "Inserting should stop at MaxLength")
+}
+
+func TestTextFieldEdit(t *testing.T) {
+	f := newTestTextField("one two three")
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditBackspace})
+	assert.Equal(t, "one two thre", f.Text)
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditWordLeft})
+	assert.Equal(t, 8, f.Caret())
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditWordLeft, Select: true})
+	start, end := f.Selection()
+	assert.Equal(t, 4, start)
+	assert.Equal(t, 8, end)
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditDelete})
+	assert.Equal(t, "one thre", f.Text, "Deleting should remove the selection")
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditHome})
+	f.edit(tango.TextEditMessage{Action: tango.TextEditDelete})
+	assert.Equal(t, "ne thre", f.Text)
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditEnd, Select: true})
+	f.edit(tango.TextEditMessage{Action: tango.TextEditLeft})
+	assert.Equal(t, 0, f.Caret(), "Moving left should collapse the selection to its start")
+
+	f.Text = "short"
+	assert.Equal(t, 0, f.Caret())
+	f.SetCaret(20, false)
+	assert.Equal(t, 5, f.Caret(), "Caret should stay within the text")
+}
+
+type textFieldTestScene struct{}
+
+func (*textFieldTestScene) Preload()            {}
+func (*textFieldTestScene) Setup(tango.Updater) {}
+func (*textFieldTestScene) Type() string        { return "textFieldTestScene" }
+
+func TestTextFieldClipboard(t *testing.T) {
+	tango.Run(tango.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &textFieldTestScene{})
+
+	f := newTestTextField("copy paste")
+	f.edit(tango.TextEditMessage{Action: tango.TextEditWordLeft, Select: true})
+	f.edit(tango.TextEditMessage{Action: tango.TextEditCut})
+	assert.Equal(t, "paste", tango.GetClipboard())
+	assert.Equal(t, "copy ", f.Text)
+
+	f.edit(tango.TextEditMessage{Action: tango.TextEditHome})
+	f.edit(tango.TextEditMessage{Action: tango.TextEditPaste})
+	assert.Equal(t, "pastecopy ", f.Text)
+}
+
+func TestTextFieldScroll(t *testing.T) {
+	f := newTestTextField("abcdefghij")
+	text, caret := f.display()
+	advances := f.advances(text)
+
+	end := f.scroll(advances, caret, 50)
+	assert.Equal(t, 5, f.offset, "Text should scroll to keep the caret visible")
+	assert.Equal(t, 10, end)
+
+	f.SetCaret(2, false)
+	text, caret = f.display()
+	end = f.scroll(advances, caret, 50)
+	assert.Equal(t, 2, f.offset, "Text should scroll back when the caret moves left")
+	assert.Equal(t, 7, end)
+
+	assert.Equal(t, 4, f.caretAt(advances, 16), "Clicking should move the caret to the closest position")
+	assert.Equal(t, 2, f.caretAt(advances, -5))
+
+	f.Text = "abc"
+	text, caret = f.display()
+	f.scroll(f.advances(text), caret, 50)
+	assert.Equal(t, 0, f.offset, "Text should scroll back when there's room")
+}
+
+func TestTextFieldSystemRemove(t *testing.T) {
+	tango.Mailbox = &tango.MessageManager{}
+	tango.Input = tango.NewInputManager()
+
+	sys := &TextFieldSystem{}
+	sys.New(&ecs.World{})
+
+	basic := ecs.NewBasic()
+	field := newTestTextField("")
+	sys.Add(&basic, field, nil, &RenderComponent{}, &SpaceComponent{})
+	sys.Focus(&basic)
+	assert.True(t, field.Focused())
+	assert.True(t, tango.Input.TextInputActive())
+
+	sys.Remove(basic)
+	assert.False(t, field.Focused(), "Removing the focused TextField should remove the focus")
+	assert.False(t, tango.Input.TextInputActive())
+}
@@ -0,0 +1,364 @@
+package common
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+)
+
+// Tweener is anything the TweenSystem can play: a Tween, a TweenSequence or a TweenGroup.
+type Tweener interface {
+	// advance moves the animation forward by dt seconds. It returns whether it finished, and if so, how much of dt
+	// was left over after finishing.
+	advance(dt float32) (left float32, done bool)
+	// reset prepares the animation to be played again from the start.
+	reset()
+}
+
+// Tween animates a float32 value from From to To over Duration.
+type Tween struct {
+	// Target is the value to animate, such as &space.Position.X or &render.Scale.Y.
+	Target *float32
+	// Get and Set can be used instead of Target for values which aren't a float32 field, such as the alpha of a color.
+	Get func() float32
+	Set func(value float32)
+
+	From, To float32
+	// FromCurrent starts the animation at the value the target has when it starts, instead of at From.
+	FromCurrent bool
+	Duration    time.Duration
+	// Delay is how long to wait before the animation starts.
+	Delay time.Duration
+	// Ease maps the progress over time to the progress of the value. EaseLinear is used if it's nil.
+	Ease EaseFunc
+	// Repeat is how many more times the animation is played after the first time. If negative, it repeats forever.
+	Repeat int
+	// Yoyo plays every repetition in the opposite direction of the one before.
+	Yoyo bool
+
+	started  bool
+	from     float32
+	waited   float32
+	elapsed  float32
+	played   int
+	reversed bool
+}
+
+func (t *Tween) value() float32 {
+	switch {
+	case t.Target != nil:
+		return *t.Target
+	case t.Get != nil:
+		return t.Get()
+	default:
+		return t.From
+	}
+}
+
+func (t *Tween) set(progress float32) {
+	if t.reversed {
+		progress = 1 - progress
+	}
+
+	ease := t.Ease
+	if ease == nil {
+		ease = EaseLinear
+	}
+	value := t.from + (t.To-t.from)*ease(progress)
+
+	if t.Target != nil {
+		*t.Target = value
+	}
+	if t.Set != nil {
+		t.Set(value)
+	}
+}
+
+func (t *Tween) advance(dt float32) (float32, bool) {
+	if delay := float32(t.Delay.Seconds()); t.waited < delay {
+		t.waited += dt
+		if t.waited < delay {
+			return 0, false
+		}
+		dt = t.waited - delay
+	}
+
+	if !t.started {
+		t.started = true
+		t.from = t.From
+		if t.FromCurrent {
+			t.from = t.value()
+		}
+	}
+
+	duration := float32(t.Duration.Seconds())
+	t.elapsed += dt
+	for t.elapsed >= duration {
+		t.set(1)
+		if t.Repeat >= 0 && t.played >= t.Repeat {
+			return t.elapsed - duration, true
+		}
+
+		t.played++
+		t.elapsed -= duration
+		if t.Yoyo {
+			t.reversed = !t.reversed
+		}
+		if duration == 0 {
+			// Repeating an instant animation forever, so it can only be played once per frame
+			return 0, false
+		}
+	}
+
+	t.set(t.elapsed / duration)
+	return 0, false
+}
+
+func (t *Tween) reset() {
+	t.started = false
+	t.waited = 0
+	t.elapsed = 0
+	t.played = 0
+	t.reversed = false
+}
+
+// TweenSequence plays its Tweens one after another. A Tween without a target can be used to wait in between.
+type TweenSequence struct {
+	Tweens []Tweener
+	// Repeat is how many more times the sequence is played after the first time. If negative, it repeats forever.
+	Repeat int
+
+	index  int
+	played int
+}
+
+func (s *TweenSequence) advance(dt float32) (float32, bool) {
+	for {
+		pass := dt
+		for s.index < len(s.Tweens) {
+			left, done := s.Tweens[s.index].advance(dt)
+			if !done {
+				return 0, false
+			}
+			dt = left
+			s.index++
+		}
+
+		if s.Repeat >= 0 && s.played >= s.Repeat {
+			return dt, true
+		}
+
+		s.played++
+		s.index = 0
+		for _, t := range s.Tweens {
+			t.reset()
+		}
+		if dt == 0 || dt == pass {
+			// Repeating a sequence without any duration forever, so it can only be played once per frame
+			return 0, false
+		}
+	}
+}
+
+func (s *TweenSequence) reset() {
+	s.index = 0
+	s.played = 0
+	for _, t := range s.Tweens {
+		t.reset()
+	}
+}
+
+// TweenGroup plays its Tweens at the same time, and finishes when all of them finished.
+type TweenGroup struct {
+	Tweens []Tweener
+	// Repeat is how many more times the group is played after the first time. If negative, it repeats forever.
+	Repeat int
+
+	done   []bool
+	played int
+}
+
+func (g *TweenGroup) advance(dt float32) (float32, bool) {
+	if len(g.done) != len(g.Tweens) {
+		g.done = make([]bool, len(g.Tweens))
+	}
+
+	for {
+		finished := true
+		// The group finishes together with the longest of its tweens, which has the least time left over
+		left := dt
+		for i, t := range g.Tweens {
+			if g.done[i] {
+				continue
+			}
+			l, done := t.advance(dt)
+			if !done {
+				finished = false
+				continue
+			}
+			g.done[i] = true
+			if l < left {
+				left = l
+			}
+		}
+		if !finished {
+			return 0, false
+		}
+
+		if g.Repeat >= 0 && g.played >= g.Repeat {
+			return left, true
+		}
+
+		g.played++
+		g.resetTweens()
+		if left == 0 || left == dt {
+			// Repeating a group without any duration forever, so it can only be played once per frame
+			return 0, false
+		}
+		dt = left
+	}
+}
+
+func (g *TweenGroup) resetTweens() {
+	for i, t := range g.Tweens {
+		t.reset()
+		if i < len(g.done) {
+			g.done[i] = false
+		}
+	}
+}
+
+func (g *TweenGroup) reset() {
+	g.played = 0
+	g.resetTweens()
+}
+
+// TweenPoint returns a TweenGroup which animates both coordinates of the point from their current value to the given
+// point, such as &space.Position.
+func TweenPoint(p *tango.Point, to tango.Point, duration time.Duration, ease EaseFunc) *TweenGroup {
+	return &TweenGroup{Tweens: []Tweener{
+		&Tween{Target: &p.X, To: to.X, FromCurrent: true, Duration: duration, Ease: ease},
+		&Tween{Target: &p.Y, To: to.Y, FromCurrent: true, Duration: duration, Ease: ease},
+	}}
+}
+
+// TweenAlpha returns a Tween which animates the alpha of the color of the RenderComponent from its current value to
+// the given alpha, ranging from 0 to 1.
+func TweenAlpha(render *RenderComponent, to float32, duration time.Duration, ease EaseFunc) *Tween {
+	toNRGBA := func() color.NRGBA {
+		if render.Color == nil {
+			return color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		}
+		return color.NRGBAModel.Convert(render.Color).(color.NRGBA)
+	}
+
+	return &Tween{
+		Get: func() float32 {
+			return float32(toNRGBA().A) / 0xff
+		},
+		Set: func(value float32) {
+			c := toNRGBA()
+			switch {
+			case value <= 0:
+				c.A = 0
+			case value >= 1:
+				c.A = 0xff
+			default:
+				c.A = uint8(value*0xff + 0.5)
+			}
+			render.Color = c
+		},
+		To:          to,
+		FromCurrent: true,
+		Duration:    duration,
+		Ease:        ease,
+	}
+}
+
+// TweenCompleteMessage is dispatched on the tango.Mailbox whenever a Tween, TweenSequence or TweenGroup added to the
+// TweenSystem finished playing.
+type TweenCompleteMessage struct {
+	Entity *ecs.BasicEntity
+	Tween  Tweener
+}
+
+// Type implements the tango.Message interface.
+func (TweenCompleteMessage) Type() string { return "TweenCompleteMessage" }
+
+type tweenEntity struct {
+	*ecs.BasicEntity
+	tween Tweener
+}
+
+// TweenSystem plays Tweens, TweenSequences and TweenGroups. Every animation belongs to an entity, and is stopped when
+// the entity is removed.
+type TweenSystem struct {
+	entities []tweenEntity
+}
+
+// Add starts playing the given animation for the entity. The entity may be nil for animations which don't belong to
+// any entity.
+func (t *TweenSystem) Add(basic *ecs.BasicEntity, tween Tweener) {
+	tween.reset()
+	t.entities = append(t.entities, tweenEntity{basic, tween})
+}
+
+// Remove stops all animations of the given entity.
+func (t *TweenSystem) Remove(basic ecs.BasicEntity) {
+	playing := t.entities[:0]
+	for _, e := range t.entities {
+		if e.BasicEntity == nil || e.BasicEntity.ID() != basic.ID() {
+			playing = append(playing, e)
+		}
+	}
+	for i := len(playing); i < len(t.entities); i++ {
+		t.entities[i] = tweenEntity{}
+	}
+	t.entities = playing
+}
+
+// Stop stops playing the given animation, without dispatching a TweenCompleteMessage. The target is left at its
+// current value.
+func (t *TweenSystem) Stop(tween Tweener) {
+	for index, e := range t.entities {
+		if e.tween == tween {
+			t.entities = append(t.entities[:index], t.entities[index+1:]...)
+			return
+		}
+	}
+}
+
+// Playing returns whether the given animation is being played.
+func (t *TweenSystem) Playing(tween Tweener) bool {
+	for _, e := range t.entities {
+		if e.tween == tween {
+			return true
+		}
+	}
+	return false
+}
+
+// Update advances all animations, and dispatches a TweenCompleteMessage for every animation that finished.
+func (t *TweenSystem) Update(dt float32) {
+	var completed []TweenCompleteMessage
+
+	playing := t.entities[:0]
+	for _, e := range t.entities {
+		if _, done := e.tween.advance(dt); done {
+			completed = append(completed, TweenCompleteMessage{Entity: e.BasicEntity, Tween: e.tween})
+			continue
+		}
+		playing = append(playing, e)
+	}
+	for i := len(playing); i < len(t.entities); i++ {
+		t.entities[i] = tweenEntity{}
+	}
+	t.entities = playing
+
+	// Dispatched afterwards, as listeners may add new animations
+	for _, msg := range completed {
+		tango.Mailbox.Dispatch(msg)
+	}
+}
@@ -0,0 +1,182 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEaseFuncs(t *testing.T) {
+	eases := map[string]EaseFunc{
+		"Linear":       EaseLinear,
+		"InQuad":       EaseInQuad,
+		"OutQuad":      EaseOutQuad,
+		"InOutQuad":    EaseInOutQuad,
+		"InCubic":      EaseInCubic,
+		"OutCubic":     EaseOutCubic,
+		"InOutCubic":   EaseInOutCubic,
+		"InElastic":    EaseInElastic,
+		"OutElastic":   EaseOutElastic,
+		"InOutElastic": EaseInOutElastic,
+		"InBounce":     EaseInBounce,
+		"OutBounce":    EaseOutBounce,
+		"InOutBounce":  EaseInOutBounce,
+		"InBack":       EaseInBack,
+		"OutBack":      EaseOutBack,
+		"InOutBack":    EaseInOutBack,
+	}
+	for name, ease := range eases {
+		assert.InDelta(t, 0, ease(0), 1e-5, "%s should start at 0", name)
+		assert.InDelta(t, 1, ease(1), 1e-5, "%s should end at 1", name)
+	}
+
+	for _, name := range []string{"Linear", "InOutQuad", "InOutCubic", "InOutElastic", "InOutBounce", "InOutBack"} {
+		assert.InDelta(t, 0.5, eases[name](0.5), 1e-5, "%s should be halfway at the halfway point", name)
+	}
+
+	assert.True(t, EaseInBack(0.2) < 0, "EaseInBack should pull back below the start")
+	assert.True(t, EaseOutBack(0.8) > 1, "EaseOutBack should overshoot the end")
+	assert.True(t, EaseOutElastic(0.2) > 1, "EaseOutElastic should overshoot the end")
+	assert.InDelta(t, 0.75, EaseOutBounce(1.5/easeBounceDivisor), 1e-5, "EaseOutBounce should bounce off the end")
+}
+
+func TestTween(t *testing.T) {
+	var value float32
+	tween := &Tween{Target: &value, From: 10, To: 20, Duration: time.Second}
+
+	_, done := tween.advance(0.5)
+	assert.False(t, done)
+	assert.InDelta(t, 15, value, 1e-5)
+
+	left, done := tween.advance(0.75)
+	assert.True(t, done)
+	assert.InDelta(t, 0.25, left, 1e-5, "The time left after finishing should be returned")
+	assert.Equal(t, float32(20), value)
+
+	value = 100
+	tween = &Tween{Target: &value, To: 0, FromCurrent: true, Duration: time.Second, Delay: time.Second, Ease: EaseInQuad}
+	tween.advance(0.5)
+	assert.Equal(t, float32(100), value, "The tween should wait for the delay")
+	tween.advance(1)
+	assert.InDelta(t, 75, value, 1e-5, "The tween should start at the current value and use the easing")
+}
+
+func TestTweenRepeatYoyo(t *testing.T) {
+	var value float32
+	tween := &Tween{Target: &value, From: 0, To: 10, Duration: time.Second, Repeat: 2, Yoyo: true}
+
+	tween.advance(1.25)
+	assert.InDelta(t, 7.5, value, 1e-5, "The second play should go backwards")
+	tween.advance(1)
+	assert.InDelta(t, 2.5, value, 1e-5, "The third play should go forwards again")
+	_, done := tween.advance(0.75)
+	assert.True(t, done)
+	assert.Equal(t, float32(10), value)
+
+	tween = &Tween{Target: &value, From: 0, To: 10, Duration: time.Second, Repeat: -1}
+	for i := 0; i < 10; i++ {
+		_, done = tween.advance(0.75)
+		assert.False(t, done, "A tween repeating forever should never finish")
+	}
+	assert.InDelta(t, 5, value, 1e-4)
+}
+
+func TestTweenSequenceGroup(t *testing.T) {
+	var x, y float32
+	seq := &TweenSequence{Tweens: []Tweener{
+		&Tween{Target: &x, From: 0, To: 10, Duration: time.Second},
+		&Tween{Duration: time.Second},
+		&TweenGroup{Tweens: []Tweener{
+			&Tween{Target: &x, From: 10, To: 0, Duration: time.Second},
+			&Tween{Target: &y, From: 0, To: 10, Duration: 2 * time.Second},
+		}},
+	}}
+
+	seq.advance(1.5)
+	assert.Equal(t, float32(10), x, "The second tween should wait")
+	seq.advance(1)
+	assert.InDelta(t, 5, x, 1e-5, "The group should start after waiting")
+	assert.InDelta(t, 2.5, y, 1e-5)
+
+	_, done := seq.advance(1)
+	assert.False(t, done, "The group should wait for its longest tween")
+	assert.Equal(t, float32(0), x)
+
+	left, done := seq.advance(1)
+	assert.True(t, done)
+	assert.InDelta(t, 0.5, left, 1e-5)
+	assert.Equal(t, float32(10), y)
+
+	seq.Repeat = 1
+	seq.reset()
+	_, done = seq.advance(4.5)
+	assert.False(t, done)
+	assert.InDelta(t, 5, x, 1e-5, "The sequence should start over with the time left over")
+	_, done = seq.advance(3.5)
+	assert.True(t, done)
+}
+
+func TestTweenSequenceGroupWithoutDuration(t *testing.T) {
+	// These would otherwise be played over and over within a single frame
+	var x float32
+	for _, tween := range []Tweener{
+		&TweenSequence{Repeat: -1},
+		&TweenGroup{Repeat: -1},
+		&TweenSequence{Repeat: -1, Tweens: []Tweener{&Tween{Target: &x, To: 1}}},
+		&TweenGroup{Repeat: -1, Tweens: []Tweener{&Tween{Target: &x, To: 1}}},
+		&TweenSequence{Repeat: -1, Tweens: []Tweener{&TweenGroup{}}},
+	} {
+		left, done := tween.advance(0.1)
+		assert.False(t, done, "Repeating forever should never finish")
+		assert.Equal(t, float32(0), left)
+	}
+	assert.Equal(t, float32(1), x, "Tweens without duration should still be played")
+}
+
+func TestTweenAlpha(t *testing.T) {
+	render := &RenderComponent{Color: color.NRGBA{R: 0xff, G: 0x80, A: 0xff}}
+	tween := TweenAlpha(render, 0, time.Second, nil)
+
+	tween.advance(0.5)
+	assert.Equal(t, color.NRGBA{R: 0xff, G: 0x80, A: 0x80}, render.Color)
+	tween.advance(0.5)
+	assert.Equal(t, color.NRGBA{R: 0xff, G: 0x80, A: 0}, render.Color)
+}
+
+func TestTweenSystem(t *testing.T) {
+	tango.Mailbox = &tango.MessageManager{}
+	var completed []TweenCompleteMessage
+	tango.Mailbox.Listen("TweenCompleteMessage", func(msg tango.Message) {
+		completed = append(completed, msg.(TweenCompleteMessage))
+	})
+
+	sys := &TweenSystem{}
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{}
+
+	move := TweenPoint(&space.Position, tango.Point{X: 10, Y: 20}, time.Second, nil)
+	rotate := &Tween{Target: &space.Rotation, To: 90, Duration: 2 * time.Second}
+	sys.Add(&basic, move)
+	sys.Add(&basic, rotate)
+
+	sys.Update(0.5)
+	assert.Equal(t, tango.Point{X: 5, Y: 10}, space.Position)
+	assert.True(t, sys.Playing(move))
+
+	sys.Update(0.5)
+	assert.Equal(t, tango.Point{X: 10, Y: 20}, space.Position)
+	assert.False(t, sys.Playing(move))
+	if assert.Len(t, completed, 1) {
+		assert.Equal(t, &basic, completed[0].Entity)
+		assert.Equal(t, Tweener(move), completed[0].Tween)
+	}
+
+	sys.Remove(basic)
+	sys.Update(1)
+	assert.Equal(t, float32(45), space.Rotation, "Removing the entity should stop its tweens")
+	assert.Len(t, completed, 1, "Stopped tweens should not report completion")
+}
@@ -21,6 +21,7 @@ func (*DefaultScene) Setup(u tango.Updater) {
 
 	tango.Input.RegisterAxis("sideways", tango.AxisKeyPair{tango.KeyA, tango.KeyD})
 	tango.Input.RegisterButton("action", tango.KeySpace, tango.KeyEnter)
+	tango.Input.RegisterMouseButton("action", tango.MouseButtonLeft)
 }
 
 func (*DefaultScene) Type() string { return "Game" }
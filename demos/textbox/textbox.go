@@ -4,7 +4,6 @@ package main
 
 import (
 	"image/color"
-	"sync"
 
 	"github.com/inkeliz-technologies/ecs"
 	"github.com/inkeliz-technologies/tango"
@@ -19,13 +18,19 @@ type MyLabel struct {
 	common.SpaceComponent
 }
 
+type MyTextField struct {
+	ecs.BasicEntity
+	common.TextField
+	common.MouseComponent
+	common.RenderComponent
+	common.SpaceComponent
+}
+
 func (*DefaultScene) Preload() {
 	err := tango.Files.Load("Roboto-Regular.ttf")
 	if err != nil {
 		panic(err)
 	}
-	tango.Input.RegisterButton("backspace", tango.KeyBackspace)
-	tango.Input.RegisterButton("enter", tango.KeyEnter)
 }
 
 // Setup is called before the main loop is started
@@ -34,7 +39,8 @@ func (*DefaultScene) Setup(u tango.Updater) {
 
 	common.SetBackground(color.White)
 	w.AddSystem(&common.RenderSystem{})
-	w.AddSystem(&TypingSystem{})
+	w.AddSystem(&common.MouseSystem{})
+	w.AddSystem(&common.TextFieldSystem{})
 
 	fnt := &common.Font{
 		URL:  "Roboto-Regular.ttf",
@@ -46,91 +52,48 @@ func (*DefaultScene) Setup(u tango.Updater) {
 		panic(err)
 	}
 
-	label1 := MyLabel{BasicEntity: ecs.NewBasic()}
-	label1.RenderComponent.Drawable = common.Text{
+	label := MyLabel{BasicEntity: ecs.NewBasic()}
+	label.SpaceComponent.Position.Set(0, 150)
+	label.RenderComponent.Drawable = common.Text{
 		Font: fnt,
 		Text: "Start Typing to add text!",
 	}
 
-	for _, system := range w.Systems() {
-		switch sys := system.(type) {
-		case *common.RenderSystem:
-			sys.Add(&label1.BasicEntity, &label1.RenderComponent, &label1.SpaceComponent)
-		}
-	}
-}
-
-func (*DefaultScene) Type() string { return "Game" }
-
-type TypingSystem struct {
-	label             MyLabel
-	runeLock          sync.Mutex
-	runesToAdd        []rune
-	timeSinceDeletion float32
-}
-
-func (t *TypingSystem) New(w *ecs.World) {
-	fnt := &common.Font{
-		URL:  "Roboto-Regular.ttf",
-		FG:   color.Black,
-		Size: 64,
-	}
-	err := fnt.CreatePreloaded()
-	if err != nil {
-		panic(err)
-	}
-
-	t.label = MyLabel{BasicEntity: ecs.NewBasic()}
-	t.label.SpaceComponent.Position.Set(0, 75)
-	t.label.RenderComponent.Drawable = common.Text{
-		Font: fnt,
-		Text: "",
+	field := MyTextField{BasicEntity: ecs.NewBasic()}
+	field.TextField = common.TextField{Font: fnt}
+	field.SpaceComponent = common.SpaceComponent{
+		Position: tango.Point{X: 20, Y: 50},
+		Width:    760,
+		Height:   80,
 	}
 
 	for _, system := range w.Systems() {
 		switch sys := system.(type) {
 		case *common.RenderSystem:
-			sys.Add(&t.label.BasicEntity, &t.label.RenderComponent, &t.label.SpaceComponent)
+			sys.Add(&label.BasicEntity, &label.RenderComponent, &label.SpaceComponent)
+			sys.Add(&field.BasicEntity, &field.RenderComponent, &field.SpaceComponent)
+		case *common.MouseSystem:
+			sys.Add(&field.BasicEntity, &field.MouseComponent, &field.SpaceComponent, &field.RenderComponent)
+		case *common.TextFieldSystem:
+			sys.Add(&field.BasicEntity, &field.TextField, &field.MouseComponent, &field.RenderComponent, &field.SpaceComponent)
+			sys.Focus(&field.BasicEntity)
 		}
 	}
 
-	tango.Mailbox.Listen("TextMessage", func(msg tango.Message) {
-		m, ok := msg.(tango.TextMessage)
+	// Pressing enter moves the text of the field to the label
+	tango.Mailbox.Listen("TextFieldSubmitMessage", func(msg tango.Message) {
+		m, ok := msg.(common.TextFieldSubmitMessage)
 		if !ok {
 			return
 		}
-		t.runeLock.Lock()
-		t.runesToAdd = append(t.runesToAdd, m.Char)
-		t.runeLock.Unlock()
+		txt := label.Drawable.(common.Text)
+		txt.Text += "\n" + m.Field.Text
+		label.Drawable = txt
+		m.Field.Text = ""
 	})
 }
 
-func (*TypingSystem) Remove(ecs.BasicEntity) {}
-
-func (t *TypingSystem) Update(dt float32) {
-	t.timeSinceDeletion += dt
-	str := ""
-	txt := t.label.Drawable.(common.Text)
-	if tango.Input.Button("backspace").Down() && len(txt.Text) > 0 {
-		if t.timeSinceDeletion > 0.2 {
-			t.timeSinceDeletion = 0
-			txt.Text = txt.Text[:len(txt.Text)-1]
-			t.label.Drawable = txt
-		}
-		return
-	}
-	t.runeLock.Lock()
-	if len(t.runesToAdd) != 0 {
-		str = string(t.runesToAdd)
-	}
-	t.runesToAdd = make([]rune, 0)
-	t.runeLock.Unlock()
-	txt.Text += str
-	if tango.Input.Button("enter").JustPressed() {
-		txt.Text += "\n"
-	}
-	t.label.Drawable = txt
-}
+func (*DefaultScene) Type() string { return "Game" }
 
 func main() {
 	opts := tango.RunOptions{
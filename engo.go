@@ -225,6 +225,8 @@ func Run(o RunOptions, defaultScene Scene) {
 		if !opts.NoRun {
 			runHeadless(defaultScene)
 		} else {
+			// Step starts the clock again
+			Time = nil
 			SetScene(defaultScene, true)
 		}
 	} else {
@@ -302,6 +304,28 @@ func closeEvent() {
 	}
 }
 
+// Step runs a single frame of the current Scene, with the given amount of seconds passing, in place of the main loop.
+// It's meant for stepping through frames in headless tests, after calling Run with NoRun. Input set through the
+// InputManager before the call is seen by the systems during the frame. Time starts at zero with the first call after
+// Run, and then only moves forward by the time given to Step, rather than with the real time.
+func Step(dt float32) {
+	timer, ok := theTimer.(*stepTimer)
+	if !ok || Time == nil {
+		timer = &stepTimer{}
+		theTimer = timer
+		Time = NewClock()
+	}
+	timer.now += int64(float64(dt) * float64(secondsInNano))
+	Time.Tick()
+
+	currentUpdater.Update(dt)
+
+	// Forget the input of this frame, as the main loop does before the next one
+	Input.update()
+	Input.Mouse.ScrollX, Input.Mouse.ScrollY = 0, 0
+	Input.Mouse.Action = Neutral
+}
+
 func runHeadless(defaultScene Scene) {
 	runLoop(defaultScene, true)
 }
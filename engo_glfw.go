@@ -165,11 +165,15 @@ func CreateWindow(opts *RunOptions) {
 		} else {
 			Input.Mouse.Action = Release
 		}
+
+		Input.mouseButtons.Set(MouseButton(b), a == glfw.Press)
 	})
 
 	Window.SetScrollCallback(func(Window *glfw.Window, xoff, yoff float64) {
 		Input.Mouse.ScrollX = float32(xoff)
 		Input.Mouse.ScrollY = float32(yoff)
+
+		Input.mouseButtons.setScroll(Input.Mouse.ScrollX, Input.Mouse.ScrollY)
 	})
 
 	Window.SetKeyCallback(func(Window *glfw.Window, k glfw.Key, s int, a glfw.Action, m glfw.ModifierKey) {
@@ -179,6 +183,10 @@ func CreateWindow(opts *RunOptions) {
 		} else if a == glfw.Release {
 			Input.keys.Set(key, false)
 		}
+
+		if a == glfw.Press || a == glfw.Repeat {
+			Input.editText(key, Modifier(m))
+		}
 	})
 
 	Window.SetSizeCallback(func(w *glfw.Window, widthInt int, heightInt int) {
@@ -205,6 +213,7 @@ func CreateWindow(opts *RunOptions) {
 
 	Window.SetCharCallback(func(Window *glfw.Window, char rune) {
 		Mailbox.Dispatch(TextMessage{char})
+		Input.commitText(string(char))
 	})
 
 	Window.SetCloseCallback(func(Window *glfw.Window) {
@@ -399,6 +408,23 @@ func SetCursorPosition(x, y float32) {
 	Window.SetCursorPos(float64(x), float64(y))
 }
 
+// GetClipboard returns the text currently stored on the clipboard
+func GetClipboard() string {
+	if opts.HeadlessMode {
+		return headlessClipboard
+	}
+	return Window.GetClipboardString()
+}
+
+// SetClipboard stores the given text on the clipboard
+func SetClipboard(text string) {
+	if opts.HeadlessMode {
+		headlessClipboard = text
+		return
+	}
+	Window.SetClipboardString(text)
+}
+
 // openFile is the desktop-specific way of opening a file
 func openFile(url string) (io.ReadCloser, error) {
 	return os.Open(url)
@@ -349,3 +349,75 @@ func TestGetApplicationVersion(t *testing.T) {
 		t.Errorf("Application version did not match. Wanted: %v.%v.%v \n Got: %v.%v.%v\n", 1, 2, 3, ver[0], ver[1], ver[2])
 	}
 }
+
+type testStepScene struct {
+	system *testStepSystem
+}
+
+func (*testStepScene) Preload() {}
+
+func (t *testStepScene) Setup(u Updater) {
+	w, _ := u.(*ecs.World)
+	w.AddSystem(t.system)
+}
+
+func (*testStepScene) Type() string { return "testStepScene" }
+
+type testStepSystem struct {
+	dt     float32
+	states []int
+	action Action
+}
+
+func (*testStepSystem) Remove(ecs.BasicEntity) {}
+
+func (t *testStepSystem) Update(dt float32) {
+	t.dt = dt
+	state := Input.keys.Get(KeySpace)
+	t.states = append(t.states, state.State())
+	t.action = Input.Mouse.Action
+}
+
+func TestStep(t *testing.T) {
+	system := &testStepSystem{}
+	Run(RunOptions{
+		HeadlessMode: true,
+		NoRun:        true,
+	}, &testStepScene{system})
+
+	Step(0.25)
+	if system.dt != 0.25 {
+		t.Errorf("Step did not update with the given time. Wanted: %v, got: %v", 0.25, system.dt)
+	}
+	if Time.Delta() != 0.25 || Time.Time() != 0.25 {
+		t.Errorf("Step did not move the clock forward by the given time. Wanted: %v, got: %v and %v", 0.25, Time.Delta(), Time.Time())
+	}
+
+	Input.SetKey(KeySpace, true)
+	Input.Mouse.Action = Press
+	Step(0.25)
+	if system.action != Press {
+		t.Errorf("Mouse action was not seen during the frame. Wanted: %v, got: %v", Press, system.action)
+	}
+	if Input.Mouse.Action != Neutral {
+		t.Errorf("Mouse action was not reset after the frame. Wanted: %v, got: %v", Neutral, Input.Mouse.Action)
+	}
+	Step(0.25)
+	Input.SetKey(KeySpace, false)
+	Step(0.25)
+	Step(0.25)
+
+	if Time.Time() != 1.25 {
+		t.Errorf("Step did not move the clock forward by the given time. Wanted: %v, got: %v", 1.25, Time.Time())
+	}
+
+	wanted := []int{KeyStateUp, KeyStateJustDown, KeyStateDown, KeyStateJustUp, KeyStateUp}
+	if len(system.states) != len(wanted) {
+		t.Fatalf("Step did not run the expected number of frames. Wanted: %v, got: %v", len(wanted), len(system.states))
+	}
+	for i := range wanted {
+		if system.states[i] != wanted[i] {
+			t.Errorf("Key state of frame %v did not match. Wanted: %v, got: %v", i, wanted[i], system.states[i])
+		}
+	}
+}
@@ -4565,6 +4565,10 @@ func (c *Context) GetViewport() [4]int32 {
 	return params
 }
 
+func (c *Context) ReadPixels(x, y, width, height, format, kind int, pixels []uint8) {
+	gl.ReadPixels(int32(x), int32(y), int32(width), int32(height), uint32(format), uint32(kind), gl.Ptr(pixels))
+}
+
 func (c *Context) Scissor(x, y, width, height int) {
 	gl.Scissor(int32(x), int32(y), int32(width), int32(height))
 }
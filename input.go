@@ -16,6 +16,8 @@ func NewInputManager() *InputManager {
 		axes:    make(map[string]Axis),
 		buttons: make(map[string]Button),
 		keys:    NewKeyManager(),
+
+		mouseButtons: NewMouseButtonManager(),
 	}
 }
 
@@ -33,15 +35,20 @@ type InputManager struct {
 	axes    map[string]Axis
 	buttons map[string]Button
 	keys    *KeyManager
+
+	mouseButtons *MouseButtonManager
+	textInput    bool
 }
 
 func (im *InputManager) update() {
 	im.keys.update()
+	im.mouseButtons.update()
 }
 
-// Mute mutes any key pressed returning as not pressed until unmuted
+// Mute mutes any key or mouse button pressed returning as not pressed until unmuted
 func (im *InputManager) SetMute(muted bool) {
 	im.keys.SetMute(muted)
+	im.mouseButtons.SetMute(muted)
 }
 
 // RegisterAxis registers a new axis which can be used to retrieve inputs which are spectrums.
@@ -52,11 +59,24 @@ func (im *InputManager) RegisterAxis(name string, pairs ...AxisPair) {
 	}
 }
 
-// RegisterButton registers a new button input.
+// RegisterButton registers a new button input. Any mouse triggers previously registered
+// for the button through RegisterMouseButton are kept.
 func (im *InputManager) RegisterButton(name string, keys ...Key) {
 	im.buttons[name] = Button{
-		Triggers: keys,
-		Name:     name,
+		Triggers:      keys,
+		MouseTriggers: im.buttons[name].MouseTriggers,
+		Name:          name,
+	}
+}
+
+// RegisterMouseButton registers the mouse buttons (or scroll ticks) which trigger the button
+// with the given name. Any key triggers previously registered for the button through
+// RegisterButton are kept, so the same button can be triggered by both keys and mouse buttons.
+func (im *InputManager) RegisterMouseButton(name string, buttons ...MouseButton) {
+	im.buttons[name] = Button{
+		Triggers:      im.buttons[name].Triggers,
+		MouseTriggers: buttons,
+		Name:          name,
 	}
 }
 
@@ -70,6 +90,23 @@ func (im *InputManager) Button(name string) Button {
 	return im.buttons[name]
 }
 
+// MouseButton retrieves the state of a mouse button (or scroll tick).
+func (im *InputManager) MouseButton(b MouseButton) KeyState {
+	return im.mouseButtons.Get(b)
+}
+
+// SetKey sets whether the key is held down, as the backend does when it's pressed or released. It can be used to
+// simulate input, such as in headless tests together with Step.
+func (im *InputManager) SetKey(k Key, down bool) {
+	im.keys.Set(k, down)
+}
+
+// SetMouseButton sets whether the mouse button is held down, as the backend does when it's pressed or released. The
+// Mouse isn't changed.
+func (im *InputManager) SetMouseButton(b MouseButton, down bool) {
+	im.mouseButtons.Set(b, down)
+}
+
 // Mouse represents the mouse
 type Mouse struct {
 	X, Y             float32
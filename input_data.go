@@ -56,6 +56,19 @@ const (
 	MouseButtonLast MouseButton = 7
 )
 
+// Mouse scroll ticks. These can be used as Button triggers just like the mouse buttons, but they're only
+// JustPressed for the frame in which the scrolling happened and JustReleased in the frame after that.
+const (
+	// MouseScrollUp represents a single tick of the mouse wheel scrolling up
+	MouseScrollUp MouseButton = MouseButtonLast + 1 + iota
+	// MouseScrollDown represents a single tick of the mouse wheel scrolling down
+	MouseScrollDown
+	// MouseScrollLeft represents a single tick of the mouse wheel scrolling left
+	MouseScrollLeft
+	// MouseScrollRight represents a single tick of the mouse wheel scrolling right
+	MouseScrollRight
+)
+
 // MouseState represents the current state of the Mouse (or latest Touch-events).
 type MouseState struct {
 	// X and Y are the coordinates of the Mouse, relative to the `Canvas`.
@@ -17,71 +17,114 @@ const (
 
 // NewKeyManager creates a new KeyManager.
 func NewKeyManager() *KeyManager {
-	return &KeyManager{
-		dirtmap: make(map[Key]Key),
-		mapper:  make(map[Key]KeyState),
-	}
+	return &KeyManager{states: newButtonStates(nil)}
 }
 
 // KeyManager tracks which keys are pressed and released at the current point of time.
 type KeyManager struct {
-	muted bool
-	dirtmap map[Key]Key
-	mapper  map[Key]KeyState
-	mutex   sync.RWMutex
+	states buttonStates
 }
 
 func (km *KeyManager) SetMute(muted bool) {
-	km.muted = muted
+	km.states.setMute(muted)
 }
 
 // Set is used for updating whether or not a key is held down, or not held down.
 func (km *KeyManager) Set(k Key, state bool) {
-	km.mutex.Lock()
-
-	ks := km.mapper[k]
-	ks.set(state)
-	km.mapper[k] = ks
-	km.dirtmap[k] = k
-
-	km.mutex.Unlock()
+	km.states.set(int(k), state)
 }
 
 // Get retrieves a keys state.
 func (km *KeyManager) Get(k Key) KeyState {
-	if km.muted {
-		return KeyState{lastState: false, currentState: false}
-	}
-
-	return km.get(k)
+	return km.states.get(int(k))
 }
 
 // GetIgnoreMuted retrieves a keys state even with it's muted
 func (km *KeyManager) GetIgnoreMuted(k Key) KeyState {
-	return km.get(k)
+	return km.states.getIgnoreMuted(int(k))
 }
 
-func (km *KeyManager) get(k Key) KeyState {
-	km.mutex.RLock()
-	ks := km.mapper[k]
-	km.mutex.RUnlock()
+func (km *KeyManager) update() {
+	km.states.update()
+}
+
+// buttonStates tracks the KeyState of buttons identified by an int, such as keys or mouse buttons.
+type buttonStates struct {
+	muted   bool
+	dirtmap map[int]int
+	mapper  map[int]KeyState
+	mutex   sync.RWMutex
+	// autoRelease returns whether a button has no release event, so it's released one frame after being pressed. It
+	// may be nil.
+	autoRelease func(b int) bool
+}
+
+func newButtonStates(autoRelease func(b int) bool) buttonStates {
+	return buttonStates{
+		dirtmap:     make(map[int]int),
+		mapper:      make(map[int]KeyState),
+		autoRelease: autoRelease,
+	}
+}
+
+func (bs *buttonStates) releasesItself(b int) bool {
+	return bs.autoRelease != nil && bs.autoRelease(b)
+}
+
+func (bs *buttonStates) setMute(muted bool) {
+	bs.muted = muted
+}
+
+func (bs *buttonStates) set(b int, state bool) {
+	bs.mutex.Lock()
+
+	ks := bs.mapper[b]
+	// Pressing a button which releases itself more than once within a single frame still counts as a single press
+	if !bs.releasesItself(b) || !ks.currentState || !state {
+		ks.set(state)
+		bs.mapper[b] = ks
+		bs.dirtmap[b] = b
+	}
+
+	bs.mutex.Unlock()
+}
+
+func (bs *buttonStates) get(b int) KeyState {
+	if bs.muted {
+		return KeyState{lastState: false, currentState: false}
+	}
+
+	return bs.getIgnoreMuted(b)
+}
+
+func (bs *buttonStates) getIgnoreMuted(b int) KeyState {
+	bs.mutex.RLock()
+	ks := bs.mapper[b]
+	bs.mutex.RUnlock()
 
 	return ks
 }
 
-func (km *KeyManager) update() {
-	km.mutex.Lock()
+func (bs *buttonStates) update() {
+	bs.mutex.Lock()
 
-	// Update the state on all the dirty keys
-	for _, key := range km.dirtmap {
-		delete(km.dirtmap, key)
+	// Update the state on all the dirty buttons
+	for _, b := range bs.dirtmap {
+		state := bs.mapper[b]
+
+		// Buttons which release themselves are released one frame after being pressed, and have to stay dirty until
+		// the release has been seen.
+		if bs.releasesItself(b) && state.currentState {
+			state.set(false)
+		} else {
+			delete(bs.dirtmap, b)
+			state.set(state.currentState)
+		}
 
-		state := km.mapper[key]
-		state.set(state.currentState)
-		km.mapper[key] = state
+		bs.mapper[b] = state
 	}
 
-	km.mutex.Unlock()
+	bs.mutex.Unlock()
 }
 
 // KeyState is used for detecting the state of a key press.
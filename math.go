@@ -33,7 +33,8 @@ type AABB struct {
 	Min, Max Point
 }
 
-// AABBer is an interface for everything that provides information about its axis aligned bounding box.
+// AABBer is an interface for everything that provides information about its axis aligned bounding box. AABBer values
+// stored in a SpatialIndex, such as a Quadtree, have to be comparable, preferably pointers.
 type AABBer interface {
 	// AABB returns the axis aligned bounding box.
 	AABB() AABB
@@ -166,6 +167,76 @@ func (p Point) Within(c Container) bool {
 	return c.Contains(p)
 }
 
+// Length returns the euclidean length of p
+func (p *Point) Length() float32 {
+	return math32.Sqrt(p.LengthSquared())
+}
+
+// LengthSquared returns the squared euclidean length of p
+func (p *Point) LengthSquared() float32 {
+	return p.X*p.X + p.Y*p.Y
+}
+
+// Lerp sets p to the linear interpolation between p and p2, where t = 0 is p and t = 1 is p2
+func (p *Point) Lerp(p2 Point, t float32) *Point {
+	p.X += (p2.X - p.X) * t
+	p.Y += (p2.Y - p.Y) * t
+	return p
+}
+
+// Rotate rotates p around the origin by deg degrees, in the same direction as Matrix.Rotate
+func (p *Point) Rotate(deg float32) *Point {
+	return p.RotateRad(deg * DegToRad)
+}
+
+// RotateRad rotates p around the origin by rad radians, in the same direction as Matrix.RotateRad
+func (p *Point) RotateRad(rad float32) *Point {
+	sin, cos := math32.Sincos(rad)
+	p.X, p.Y = p.X*cos-p.Y*sin, p.X*sin+p.Y*cos
+	return p
+}
+
+// Perpendicular rotates p by 90 degrees, in the same direction as Matrix.Rotate
+func (p *Point) Perpendicular() *Point {
+	p.X, p.Y = -p.Y, p.X
+	return p
+}
+
+// Reflect reflects p off a surface with the given normal, which has to be a unit vector
+func (p *Point) Reflect(normal Point) *Point {
+	d := 2 * DotProduct(*p, normal)
+	p.X -= d * normal.X
+	p.Y -= d * normal.Y
+	return p
+}
+
+// AngleBetween returns the angle in radians to rotate p by to point in the same direction as p2, in the same direction
+// as Matrix.RotateRad. Values returned are [-pi, pi].
+func (p *Point) AngleBetween(p2 Point) float32 {
+	return math32.Atan2(CrossProduct(*p, p2), DotProduct(*p, p2))
+}
+
+// AngleBetweenDeg returns the angle in degrees to rotate p by to point in the same direction as p2, in the same
+// direction as Matrix.Rotate. Values returned are [-180, 180].
+func (p *Point) AngleBetweenDeg(p2 Point) float32 {
+	return p.AngleBetween(p2) * RadToDeg
+}
+
+// Clamp limits each component of p to the range given by the components of min and max
+func (p *Point) Clamp(min, max Point) *Point {
+	p.X = math32.Clamp(p.X, min.X, max.X)
+	p.Y = math32.Clamp(p.Y, min.Y, max.Y)
+	return p
+}
+
+// ClampLength shortens p to the given length if it's longer, keeping its direction
+func (p *Point) ClampLength(max float32) *Point {
+	if l := p.LengthSquared(); l > max*max {
+		p.MultiplyScalar(max / math32.Sqrt(l))
+	}
+	return p
+}
+
 // Set sets the matrix to the given float slice and returns the matrix. The float
 // slice must have at least 9 elements. If the float slie contains more than 9 elements,
 // only the first 9 will be copied.
@@ -285,6 +356,65 @@ func (m *Matrix) RotateRad(rad float32) *Matrix {
 	return m
 }
 
+// Determinant returns the determinant of m.
+func (m *Matrix) Determinant() float32 {
+	return m.Val[m00]*(m.Val[m11]*m.Val[m22]-m.Val[m12]*m.Val[m21]) -
+		m.Val[m01]*(m.Val[m10]*m.Val[m22]-m.Val[m12]*m.Val[m20]) +
+		m.Val[m02]*(m.Val[m10]*m.Val[m21]-m.Val[m11]*m.Val[m20])
+}
+
+// Invert sets m to its inverse, so it undoes the transformation it did before. If m can't be inverted, as its
+// determinant is 0, m is left unchanged and false is returned.
+func (m *Matrix) Invert() bool {
+	det := m.Determinant()
+	if det == 0 {
+		return false
+	}
+
+	m.tmp[m00] = (m.Val[m11]*m.Val[m22] - m.Val[m12]*m.Val[m21]) / det
+	m.tmp[m01] = (m.Val[m02]*m.Val[m21] - m.Val[m01]*m.Val[m22]) / det
+	m.tmp[m02] = (m.Val[m01]*m.Val[m12] - m.Val[m02]*m.Val[m11]) / det
+
+	m.tmp[m10] = (m.Val[m12]*m.Val[m20] - m.Val[m10]*m.Val[m22]) / det
+	m.tmp[m11] = (m.Val[m00]*m.Val[m22] - m.Val[m02]*m.Val[m20]) / det
+	m.tmp[m12] = (m.Val[m02]*m.Val[m10] - m.Val[m00]*m.Val[m12]) / det
+
+	m.tmp[m20] = (m.Val[m10]*m.Val[m21] - m.Val[m11]*m.Val[m20]) / det
+	m.tmp[m21] = (m.Val[m01]*m.Val[m20] - m.Val[m00]*m.Val[m21]) / det
+	m.tmp[m22] = (m.Val[m00]*m.Val[m11] - m.Val[m01]*m.Val[m10]) / det
+
+	m.Val = m.tmp
+	return true
+}
+
+// TransformPoint returns the point p transformed by m.
+func (m *Matrix) TransformPoint(p Point) Point {
+	return *p.MultiplyMatrixVector(m)
+}
+
+// InverseTransformPoint returns the point which m transforms into p, undoing TransformPoint. If m can't be inverted,
+// p is returned unchanged.
+func (m *Matrix) InverseTransformPoint(p Point) Point {
+	inv := *m
+	if !inv.Invert() {
+		return p
+	}
+	return inv.TransformPoint(p)
+}
+
+// Decompose splits m into the translation, rotation in degrees and scale it applies, in that order. The scale is
+// negative along the y-axis if m mirrors. This assumes m doesn't skew, as is the case for matrices built from
+// translations, rotations and scales only.
+func (m *Matrix) Decompose() (translation Point, rotation float32, scale Point) {
+	translation = Point{X: m.Val[m02], Y: m.Val[m12]}
+	scale.X = math32.Sqrt(m.Val[m00]*m.Val[m00] + m.Val[m10]*m.Val[m10])
+	if scale.X != 0 {
+		scale.Y = (m.Val[m00]*m.Val[m11] - m.Val[m01]*m.Val[m10]) / scale.X
+	}
+	rotation = math32.Atan2(m.Val[m10], m.Val[m00]) * RadToDeg
+	return translation, rotation, scale
+}
+
 // PointSide returns which side of the line l the point p sits on
 // true means the point is below/left of the line
 // false means the point is above/right of the line or touching the line
@@ -377,6 +507,32 @@ func IdentityMatrix() *Matrix {
 	return new(Matrix).Identity()
 }
 
+// TranslationMatrix returns a new matrix which translates by the point (x, y).
+func TranslationMatrix(x, y float32) *Matrix {
+	return IdentityMatrix().Translate(x, y)
+}
+
+// RotationMatrix returns a new matrix which rotates counter-clockwise by deg degrees.
+func RotationMatrix(deg float32) *Matrix {
+	return IdentityMatrix().Rotate(deg)
+}
+
+// ScaleMatrix returns a new matrix which scales by x and y.
+func ScaleMatrix(x, y float32) *Matrix {
+	return IdentityMatrix().Scale(x, y)
+}
+
+// TransformMatrix returns a new matrix which scales by scale and rotates by rotation degrees around pivot, and then
+// moves pivot to position. With a pivot of (0, 0), this is how the RenderSystem places a SpaceComponent, apart
+// from the global scale.
+func TransformMatrix(position Point, rotation float32, scale, pivot Point) *Matrix {
+	return IdentityMatrix().
+		TranslatePoint(position).
+		Rotate(rotation).
+		Scale(scale.X, scale.Y).
+		Translate(-pivot.X, -pivot.Y)
+}
+
 // DotProduct returns the dot product between this and that
 func DotProduct(this, that Point) float32 {
 	return this.X*that.X + this.Y*that.Y
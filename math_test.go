@@ -1587,3 +1587,199 @@ func TestMultipleMatrixVector(t *testing.T) {
 		}
 	}
 }
+
+func TestPointLength(t *testing.T) {
+	p := Point{3, 4}
+	if l := p.Length(); !FloatEqual(l, 5) {
+		t.Errorf("Length should be 5, got %v", l)
+	}
+	if l := p.LengthSquared(); !FloatEqual(l, 25) {
+		t.Errorf("LengthSquared should be 25, got %v", l)
+	}
+}
+
+func TestPointLerp(t *testing.T) {
+	data := []struct {
+		a, b Point
+		t    float32
+		exp  Point
+	}{
+		{Point{0, 0}, Point{10, 20}, 0, Point{0, 0}},
+		{Point{0, 0}, Point{10, 20}, 1, Point{10, 20}},
+		{Point{-10, 10}, Point{10, 20}, 0.25, Point{-5, 12.5}},
+	}
+	for _, d := range data {
+		p := d.a
+		if p.Lerp(d.b, d.t); !p.Equal(d.exp) {
+			t.Errorf("Lerp of %v and %v at %v should be %v, got %v", d.a, d.b, d.t, d.exp, p)
+		}
+	}
+}
+
+func TestPointRotate(t *testing.T) {
+	data := []struct {
+		p   Point
+		deg float32
+		exp Point
+	}{
+		{Point{1, 0}, 90, Point{0, 1}},
+		{Point{1, 0}, -90, Point{0, -1}},
+		{Point{2, 3}, 180, Point{-2, -3}},
+		{Point{1, 1}, 45, Point{0, math32.Sqrt(2)}},
+	}
+	for _, d := range data {
+		p := d.p
+		if p.Rotate(d.deg); !p.Equal(d.exp) {
+			t.Errorf("Rotating %v by %v should be %v, got %v", d.p, d.deg, d.exp, p)
+		}
+
+		m := RotationMatrix(d.deg)
+		if p := m.TransformPoint(d.p); !p.Equal(d.exp) {
+			t.Errorf("Rotate should match Matrix.Rotate: wanted %v, got %v", d.exp, p)
+		}
+	}
+
+	p := Point{3, 4}
+	if p.Perpendicular(); !p.Equal(Point{-4, 3}) {
+		t.Errorf("Perpendicular should be (-4, 3), got %v", p)
+	}
+}
+
+func TestPointReflect(t *testing.T) {
+	p := Point{3, 4}
+	if p.Reflect(Point{0, -1}); !p.Equal(Point{3, -4}) {
+		t.Errorf("Reflecting off a floor should be (3, -4), got %v", p)
+	}
+
+	p = Point{1, 0}
+	n, _ := (&Point{-1, 1}).Normalize()
+	if p.Reflect(n); !p.Equal(Point{0, 1}) {
+		t.Errorf("Reflecting off a diagonal should be (0, 1), got %v", p)
+	}
+}
+
+func TestPointAngleBetween(t *testing.T) {
+	data := []struct {
+		a, b Point
+		exp  float32
+	}{
+		{Point{1, 0}, Point{0, 1}, 90},
+		{Point{0, 1}, Point{1, 0}, -90},
+		{Point{1, 0}, Point{5, 0}, 0},
+		{Point{1, 1}, Point{-1, 0}, 135},
+	}
+	for _, d := range data {
+		if deg := d.a.AngleBetweenDeg(d.b); !FloatEqual(deg, d.exp) {
+			t.Errorf("Angle between %v and %v should be %v, got %v", d.a, d.b, d.exp, deg)
+		}
+
+		// Rotating by the angle between should make both point in the same direction
+		p := d.a
+		p.RotateRad(d.a.AngleBetween(d.b))
+		if cross := CrossProduct(p, d.b); !FloatEqual(cross, 0) || DotProduct(p, d.b) < 0 {
+			t.Errorf("Rotating %v by the angle between should point towards %v, got %v", d.a, d.b, p)
+		}
+	}
+}
+
+func TestPointClamp(t *testing.T) {
+	p := Point{-5, 15}
+	if p.Clamp(Point{0, 0}, Point{10, 10}); !p.Equal(Point{0, 10}) {
+		t.Errorf("Clamp should be (0, 10), got %v", p)
+	}
+
+	p = Point{30, 40}
+	if p.ClampLength(5); !p.Equal(Point{3, 4}) {
+		t.Errorf("ClampLength should be (3, 4), got %v", p)
+	}
+	if p.ClampLength(10); !p.Equal(Point{3, 4}) {
+		t.Errorf("ClampLength should not lengthen, got %v", p)
+	}
+}
+
+func TestMatrixDeterminant(t *testing.T) {
+	data := []struct {
+		matrix []float32
+		exp    float32
+	}{
+		{[]float32{1, 0, 0, 0, 1, 0, 0, 0, 1}, 1},
+		{[]float32{1, 2, 3, 4, 5, 6, 7, 8, 9}, 0},
+		{[]float32{2, 0, 0, 0, 3, 0, 5, 6, 1}, 6},
+		{[]float32{1, 2, 3, 0, 1, 4, 5, 6, 0}, 1},
+	}
+	for _, d := range data {
+		m := IdentityMatrix().Set(d.matrix)
+		if det := m.Determinant(); !FloatEqual(det, d.exp) {
+			t.Errorf("Determinant of %v should be %v, got %v", d.matrix, d.exp, det)
+		}
+	}
+}
+
+func TestMatrixInvert(t *testing.T) {
+	matrices := []*Matrix{
+		IdentityMatrix(),
+		IdentityMatrix().Set([]float32{1, 2, 3, 0, 1, 4, 5, 6, 0}),
+		TransformMatrix(Point{10, -20}, 30, Point{2, -0.5}, Point{5, 5}),
+	}
+	for _, m := range matrices {
+		inv := *m
+		if !inv.Invert() {
+			t.Errorf("%v should be invertible", m.Val)
+			continue
+		}
+
+		res := *m
+		res.Multiply(&inv)
+		for i, v := range IdentityMatrix().Val {
+			if !FloatEqualThreshold(res.Val[i], v, 1e-2) {
+				t.Errorf("Multiplying %v with its inverse should give the identity matrix, got %v", m.Val, res.Val)
+				break
+			}
+		}
+	}
+
+	m := IdentityMatrix().Set([]float32{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	if m.Invert() {
+		t.Error("A matrix with a determinant of 0 should not be invertible")
+	}
+	if m.Val != IdentityMatrix().Set([]float32{1, 2, 3, 4, 5, 6, 7, 8, 9}).Val {
+		t.Error("A matrix which can't be inverted should be left unchanged")
+	}
+}
+
+func TestMatrixTransformPoint(t *testing.T) {
+	m := TransformMatrix(Point{100, 50}, 90, Point{2, 2}, Point{5, 0})
+
+	if p := m.TransformPoint(Point{5, 0}); !p.Equal(Point{100, 50}) {
+		t.Errorf("The pivot should end up at the position, got %v", p)
+	}
+	if p := m.TransformPoint(Point{6, 0}); !p.Equal(Point{100, 52}) {
+		t.Errorf("Transforming (6, 0) should give (100, 52), got %v", p)
+	}
+	if p := m.InverseTransformPoint(Point{100, 52}); !p.Equal(Point{6, 0}) {
+		t.Errorf("InverseTransformPoint should undo TransformPoint, got %v", p)
+	}
+
+	if p := new(Matrix).InverseTransformPoint(Point{1, 2}); !p.Equal(Point{1, 2}) {
+		t.Errorf("InverseTransformPoint should leave the point unchanged if the matrix can't be inverted, got %v", p)
+	}
+}
+
+func TestMatrixDecompose(t *testing.T) {
+	data := []struct {
+		position Point
+		rotation float32
+		scale    Point
+	}{
+		{Point{0, 0}, 0, Point{1, 1}},
+		{Point{10, -20}, 30, Point{2, 3}},
+		{Point{-5, 5}, -120, Point{0.5, -2}},
+	}
+	for _, d := range data {
+		translation, rotation, scale := TransformMatrix(d.position, d.rotation, d.scale, Point{}).Decompose()
+		if !translation.Equal(d.position) || !FloatEqual(rotation, d.rotation) || !scale.Equal(d.scale) {
+			t.Errorf("Decompose should give %v, %v, %v, got %v, %v, %v",
+				d.position, d.rotation, d.scale, translation, rotation, scale)
+		}
+	}
+}
@@ -166,3 +166,24 @@ type TextMessage struct {
 
 // Type returns the type of the message, "TextMessage"
 func (TextMessage) Type() string { return "TextMessage" }
+
+// TextCommitMessage is a message that is dispatched while text input is active, whenever text is committed by the
+// user. This is either a typed character, or the final text of an input method editor (IME). The text being composed
+// with an IME isn't reported, as GLFW doesn't support it, so it's only shown by the IME itself.
+type TextCommitMessage struct {
+	Text string
+}
+
+// Type returns the type of the message, "TextCommitMessage"
+func (TextCommitMessage) Type() string { return "TextCommitMessage" }
+
+// TextEditMessage is a message that is dispatched while text input is active, whenever the user requests an edit of
+// the text, such as deleting a character or moving the caret. Select indicates whether the caret movement should
+// extend the selection.
+type TextEditMessage struct {
+	Action TextEditAction
+	Select bool
+}
+
+// Type returns the type of the message, "TextEditMessage"
+func (TextEditMessage) Type() string { return "TextEditMessage" }
@@ -0,0 +1,58 @@
+package tango
+
+// NewMouseButtonManager creates a new MouseButtonManager.
+func NewMouseButtonManager() *MouseButtonManager {
+	return &MouseButtonManager{states: newButtonStates(func(b int) bool { return MouseButton(b).isScroll() })}
+}
+
+// MouseButtonManager tracks which mouse buttons are pressed and released at the current point of time. Scroll ticks
+// (MouseScrollUp, MouseScrollDown, MouseScrollLeft and MouseScrollRight) are tracked as buttons which are released
+// automatically one frame after they were pressed.
+type MouseButtonManager struct {
+	states buttonStates
+}
+
+// SetMute mutes any mouse button, returning it as not pressed until unmuted.
+func (mm *MouseButtonManager) SetMute(muted bool) {
+	mm.states.setMute(muted)
+}
+
+// Set is used for updating whether or not a mouse button is held down, or not held down.
+func (mm *MouseButtonManager) Set(b MouseButton, state bool) {
+	mm.states.set(int(b), state)
+}
+
+// Get retrieves a mouse buttons state.
+func (mm *MouseButtonManager) Get(b MouseButton) KeyState {
+	return mm.states.get(int(b))
+}
+
+// GetIgnoreMuted retrieves a mouse buttons state even when it's muted
+func (mm *MouseButtonManager) GetIgnoreMuted(b MouseButton) KeyState {
+	return mm.states.getIgnoreMuted(int(b))
+}
+
+func (mm *MouseButtonManager) update() {
+	mm.states.update()
+}
+
+// setScroll presses the scroll ticks corresponding to the given scroll offsets.
+func (mm *MouseButtonManager) setScroll(x, y float32) {
+	switch {
+	case y > 0:
+		mm.Set(MouseScrollUp, true)
+	case y < 0:
+		mm.Set(MouseScrollDown, true)
+	}
+
+	switch {
+	case x > 0:
+		mm.Set(MouseScrollRight, true)
+	case x < 0:
+		mm.Set(MouseScrollLeft, true)
+	}
+}
+
+func (b MouseButton) isScroll() bool {
+	return b >= MouseScrollUp && b <= MouseScrollRight
+}
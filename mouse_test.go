@@ -0,0 +1,36 @@
+package tango
+
+import "testing"
+
+func TestMouseButtonManagerScroll(t *testing.T) {
+	mm := NewMouseButtonManager()
+
+	mm.setScroll(0, 1)
+	mm.setScroll(0, 2)
+	if !mm.Get(MouseScrollUp).JustPressed() {
+		t.Error("Scrolling up should press MouseScrollUp")
+	}
+	if mm.Get(MouseScrollDown).JustPressed() {
+		t.Error("Scrolling up should not press MouseScrollDown")
+	}
+
+	mm.update()
+	if !mm.Get(MouseScrollUp).JustReleased() {
+		t.Error("MouseScrollUp should be released the frame after scrolling")
+	}
+
+	mm.update()
+	if !mm.Get(MouseScrollUp).Up() {
+		t.Error("MouseScrollUp should be up two frames after scrolling")
+	}
+
+	mm.setScroll(-1, 0)
+	if !mm.Get(MouseScrollLeft).JustPressed() {
+		t.Error("Scrolling left should press MouseScrollLeft")
+	}
+	mm.update()
+	mm.setScroll(-1, 0)
+	if !mm.Get(MouseScrollLeft).JustPressed() {
+		t.Error("Scrolling in consecutive frames should press MouseScrollLeft every frame")
+	}
+}
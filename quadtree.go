@@ -84,13 +84,16 @@ type quadtreeNode struct {
 	Tree     *Quadtree
 }
 
-// Quadtree implementation which can store AABBer values
+// Quadtree implementation which can store AABBer values. The items are tracked by identity, as the keys of a map, so
+// they have to be comparable and unique, preferably pointers: inserting a struct holding a slice or a map panics, and
+// values which are equal are the same item.
 type Quadtree struct {
 	MaxObjects int // Maximum objects a node can hold before splitting into 4 subnodes
 	MaxLevels  int // Total max levels inside root Quadtree
 	root       *quadtreeNode
 	usePool    bool
 	Total      int
+	items      map[AABBer]*quadtreeNodeData
 }
 
 func calcMaxLevel(width, height float32) int {
@@ -105,8 +108,10 @@ func calcMaxLevel(width, height float32) int {
 // NewQuadtree creates a new quadtree for the given bounds.
 // When setting usePool to true, the internal values will be taken from a sync.Pool which reduces the allocation overhead.
 // maxObjects tells the tree how many objects should be stored within a level before the quadtree cell is split.
+//
+// Items are tracked by identity, so they have to be comparable, e.g. pointers.
 func NewQuadtree(bounds AABB, usePool bool, maxObjects int) *Quadtree {
-	qt := &Quadtree{MaxObjects: maxObjects, usePool: usePool}
+	qt := &Quadtree{MaxObjects: maxObjects, usePool: usePool, items: make(map[AABBer]*quadtreeNodeData)}
 	qt.root = qt.newNode(bounds, 0)
 	qt.MaxLevels = calcMaxLevel(aabbWidth(bounds), aabbHeight(bounds))
 	return qt
@@ -116,6 +121,7 @@ func NewQuadtree(bounds AABB, usePool bool, maxObjects int) *Quadtree {
 func (qt *Quadtree) Destroy() {
 	qt.freeQuadtreeNode(qt.root)
 	qt.root = nil
+	qt.items = nil
 }
 
 func (qt *Quadtree) newNode(bounds AABB, level int) (node *quadtreeNode) {
@@ -243,11 +249,18 @@ func (qt *quadtreeNode) getIndex(pRect AABB) int {
 	return -1 // index of the subnode (0-3), or -1 if pRect cannot completely fit within a subnode and is part of the parent node
 }
 
-// Insert inserts the given item to the quadtree
+// Insert inserts the given item to the quadtree. Inserting an item which is already in the quadtree moves it like Update.
 func (qt *Quadtree) Insert(item AABBer) {
+	if _, ok := qt.items[item]; ok {
+		qt.Update(item)
+		return
+	}
+
 	qt.Total++
 	pRect := item.AABB()
-	qt.root.Insert(qt.newQuadtreeNodeData(item, pRect))
+	data := qt.newQuadtreeNodeData(item, pRect)
+	qt.items[item] = data
+	qt.root.Insert(data)
 }
 
 func (qt *quadtreeNode) Insert(item *quadtreeNodeData) {
@@ -286,68 +299,77 @@ func (qt *quadtreeNode) Insert(item *quadtreeNodeData) {
 }
 
 func (qt *quadtreeNode) Remove(item AABBer, pRect AABB) {
+	if data := qt.detach(item, pRect); data != nil {
+		qt.Tree.freeQuadtreeNodeData(data)
+	}
+}
+
+// detach removes the given item from the node or its subnodes and returns its data, without freeing it
+func (qt *quadtreeNode) detach(item AABBer, pRect AABB) *quadtreeNodeData {
 	if qt.hasNodes {
 		index := qt.getIndex(pRect)
 		if index != -1 {
-			qt.Nodes[index].Remove(item, pRect)
+			data := qt.Nodes[index].detach(item, pRect)
 			qt.unsplit()
-			return
+			return data
 		}
 	}
 	for i := 0; i < len(qt.Objects); i++ {
 		if qt.Objects[i].Value == item {
-			qt.Tree.freeQuadtreeNodeData(qt.Objects[i])
+			data := qt.Objects[i]
 			qt.Objects = append(qt.Objects[:i], qt.Objects[i+1:]...) // Remove the object from the slice
-			return
+			return data
 		}
 	}
+	return nil
 }
 
-// Remove removes the given item from the quadtree
+// Remove removes the given item from the quadtree. The item is looked up using the bounding box it had when it was
+// inserted or last updated, so it can be removed even if it moved in the meantime.
 func (qt *Quadtree) Remove(item AABBer) {
-	bounds := item.AABB()
-	qt.root.Remove(item, bounds)
+	data, ok := qt.items[item]
+	if !ok {
+		return
+	}
+	delete(qt.items, item)
+	qt.Total--
+	qt.root.Remove(item, data.AABB)
 }
 
-// Retrieve returns all objects that could collide with the given bounding box
-func (qt *quadtreeNode) Retrieve(pRect AABB) []AABBer {
-	index := qt.getIndex(pRect)
-
-	// Array with all detected objects
-	result := make([]AABBer, len(qt.Objects))
-	for i, o := range qt.Objects {
-		result[i] = o.Value
+// Update moves the given item to the location of its current bounding box. It has to be called whenever an item in the
+// quadtree moves or changes size. Only the subtree in which the old and the new location differ is changed, which makes
+// small movements cheaper than a Remove followed by an Insert. Items not in the quadtree are inserted.
+func (qt *Quadtree) Update(item AABBer) {
+	data, ok := qt.items[item]
+	if !ok {
+		qt.Insert(item)
+		return
 	}
 
-	//if we have subnodes ...
-	if qt.hasNodes {
-		//if pRect fits into a subnode ..
-		if index != -1 {
-			result = append(result, qt.Nodes[index].Retrieve(pRect)...)
-		} else {
-			//if pRect does not fit into a subnode, check it against all subnodes
-			for i := 0; i < 4; i++ {
-				result = append(result, qt.Nodes[i].Retrieve(pRect)...)
-			}
+	pRect := item.AABB()
+	node := qt.root
+	for node.hasNodes {
+		index := node.getIndex(data.AABB)
+		if index == -1 || index != node.getIndex(pRect) {
+			break
 		}
+		node = node.Nodes[index]
 	}
 
-	return result
+	// The item stays within the same node, so only the stored bounding box changes
+	if !node.hasNodes || (node.getIndex(data.AABB) == -1 && node.getIndex(pRect) == -1) {
+		data.AABB = pRect
+		return
+	}
 
+	node.detach(item, data.AABB)
+	data.AABB = pRect
+	node.Insert(data)
 }
 
 // Retrieve returns all objects that could collide with the given bounding box and passing the given filter function.
 func (qt *Quadtree) Retrieve(find AABB, filter func(aabb AABBer) bool) []AABBer {
-	var foundIntersections []AABBer
-
-	potentials := qt.root.Retrieve(find)
-	for _, p := range potentials {
-		if aabbOverlaps(find, p.AABB()) && (filter == nil || filter(p)) {
-			foundIntersections = append(foundIntersections, p)
-		}
-	}
-
-	return foundIntersections
+	return retrieveAABB(qt.visit, find, filter)
 }
 
 //Clear removes all items from the quadtree
@@ -355,5 +377,6 @@ func (qt *Quadtree) Clear() {
 	bounds := qt.root.Bounds
 	qt.freeQuadtreeNode(qt.root)
 	qt.root = qt.newNode(bounds, 0)
+	qt.items = make(map[AABBer]*quadtreeNodeData)
 	qt.Total = 0
 }
@@ -0,0 +1,118 @@
+package tango
+
+import (
+	"container/heap"
+
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// infiniteRegion returns the region of the root node. Objects outside the bounds of the quadtree are still stored in
+// it, so the regions along the edges of the tree extend to infinity.
+func infiniteRegion() AABB {
+	return AABB{
+		Min: Point{X: math32.Inf(-1), Y: math32.Inf(-1)},
+		Max: Point{X: math32.Inf(1), Y: math32.Inf(1)},
+	}
+}
+
+// subregion returns the region of the subnode with the given index, given the region of the node itself. All objects
+// stored within a subnode or its subnodes lie within its region.
+func (qt *quadtreeNode) subregion(region AABB, index int) AABB {
+	horzMidpoint := qt.Bounds.Min.X + (aabbWidth(qt.Bounds) / 2)
+	vertMidpoint := qt.Bounds.Min.Y + (aabbHeight(qt.Bounds) / 2)
+
+	switch index {
+	case 0:
+		return AABB{Min: Point{X: horzMidpoint, Y: region.Min.Y}, Max: Point{X: region.Max.X, Y: vertMidpoint}}
+	case 1:
+		return AABB{Min: region.Min, Max: Point{X: horzMidpoint, Y: vertMidpoint}}
+	case 2:
+		return AABB{Min: Point{X: region.Min.X, Y: vertMidpoint}, Max: Point{X: horzMidpoint, Y: region.Max.Y}}
+	default:
+		return AABB{Min: Point{X: horzMidpoint, Y: vertMidpoint}, Max: region.Max}
+	}
+}
+
+// visit calls fn for all objects stored within the node and its subnodes, skipping every node of which the region
+// doesn't overlap find.
+func (qt *quadtreeNode) visit(region, find AABB, fn func(item AABBer)) {
+	if !aabbOverlaps(region, find) {
+		return
+	}
+
+	for _, o := range qt.Objects {
+		fn(o.Value)
+	}
+
+	if qt.hasNodes {
+		for i := 0; i < 4; i++ {
+			qt.Nodes[i].visit(qt.subregion(region, i), find, fn)
+		}
+	}
+}
+
+func (qt *Quadtree) visit(find AABB, fn func(item AABBer)) {
+	qt.root.visit(infiniteRegion(), find, fn)
+}
+
+// RetrievePoint returns all objects containing the given point and passing the given filter function.
+func (qt *Quadtree) RetrievePoint(p Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrievePoint(qt.visit, p, filter)
+}
+
+// RetrieveCircle returns all objects overlapping the circle with the given center and radius and passing the given
+// filter function.
+func (qt *Quadtree) RetrieveCircle(center Point, radius float32, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveCircle(qt.visit, center, radius, filter)
+}
+
+// RetrieveOriented returns all objects overlapping the oriented rectangle with the given corners and passing the given
+// filter function. The corners are in the order returned by common.SpaceComponent.Corners.
+func (qt *Quadtree) RetrieveOriented(corners [4]Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveOriented(qt.visit, corners, filter)
+}
+
+// RetrieveLine returns all objects intersecting the line segment l and passing the given filter function, ordered by
+// the distance from l.P1 at which the line segment enters them. The first object is the one a ray cast from l.P1 to
+// l.P2 hits; to cast a ray, use a line segment as long as the maximum distance the ray should travel.
+func (qt *Quadtree) RetrieveLine(l Line, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveLine(qt.visit, l, filter)
+}
+
+// RetrieveNearest returns up to k objects passing the given filter function, ordered by their distance to the point
+// p. Objects containing p have a distance of 0.
+func (qt *Quadtree) RetrieveNearest(p Point, k int, filter func(aabb AABBer) bool) []AABBer {
+	if k <= 0 {
+		return nil
+	}
+
+	var found []AABBer
+	queue := nearestQueue{{node: qt.root, region: infiniteRegion()}}
+	for len(queue) > 0 && len(found) < k {
+		c := heap.Pop(&queue).(nearestCandidate)
+
+		// No node or object left in the queue can be closer than this object
+		if c.item != nil {
+			found = append(found, c.item)
+			continue
+		}
+
+		node := c.node.(*quadtreeNode)
+		for _, o := range node.Objects {
+			queue.pushItem(p, o.Value, filter)
+		}
+
+		if node.hasNodes {
+			for i := 0; i < 4; i++ {
+				region := node.subregion(c.region, i)
+				heap.Push(&queue, nearestCandidate{
+					distance: aabbDistanceSquared(region, p),
+					node:     node.Nodes[i],
+					region:   region,
+				})
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,229 @@
+package tango
+
+import (
+	"testing"
+
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+type quadtreeTestItem struct {
+	aabb AABB
+}
+
+func (i *quadtreeTestItem) AABB() AABB {
+	return i.aabb
+}
+
+// quadtreeTestRotated is a square rotated by 45 degrees around its center
+type quadtreeTestRotated struct {
+	center Point
+	size   float32
+}
+
+func (r *quadtreeTestRotated) Corners() [4]Point {
+	d := r.size / 2 * math32.Sqrt(2)
+	return [4]Point{
+		{X: r.center.X, Y: r.center.Y - d},
+		{X: r.center.X + d, Y: r.center.Y},
+		{X: r.center.X - d, Y: r.center.Y},
+		{X: r.center.X, Y: r.center.Y + d},
+	}
+}
+
+func (r *quadtreeTestRotated) AABB() AABB {
+	d := r.size / 2 * math32.Sqrt(2)
+	return AABB{
+		Min: Point{X: r.center.X - d, Y: r.center.Y - d},
+		Max: Point{X: r.center.X + d, Y: r.center.Y + d},
+	}
+}
+
+func newQuadtreeTestGrid() (*Quadtree, []*quadtreeTestItem) {
+	qt := NewQuadtree(aabbRect(0, 0, 100, 100), false, 2)
+	var items []*quadtreeTestItem
+	for y := float32(0); y < 100; y += 10 {
+		for x := float32(0); x < 100; x += 10 {
+			item := &quadtreeTestItem{aabbRect(x+1, y+1, 8, 8)}
+			items = append(items, item)
+			qt.Insert(item)
+		}
+	}
+	return qt, items
+}
+
+func quadtreeContains(found []AABBer, item AABBer) bool {
+	for _, f := range found {
+		if f == item {
+			return true
+		}
+	}
+	return false
+}
+
+func TestQuadtreeRetrievePoint(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	found := qt.RetrievePoint(Point{X: 55, Y: 35}, nil)
+	if len(found) != 1 || found[0] != items[35] {
+		t.Errorf("RetrievePoint should find the item containing the point, got %v", found)
+	}
+
+	if found := qt.RetrievePoint(Point{X: 50, Y: 50}, nil); len(found) != 0 {
+		t.Errorf("RetrievePoint should not find items in between the grid cells, got %v", found)
+	}
+
+	rotated := &quadtreeTestRotated{center: Point{X: 200, Y: 200}, size: 10}
+	qt.Insert(rotated)
+	if found := qt.RetrievePoint(Point{X: 200, Y: 200}, nil); len(found) != 1 || found[0] != rotated {
+		t.Errorf("RetrievePoint should find items outside the bounds of the quadtree, got %v", found)
+	}
+	if found := qt.RetrievePoint(Point{X: 196, Y: 196}, nil); len(found) != 0 {
+		t.Errorf("RetrievePoint should test rotated items against their corners instead of their AABB, got %v", found)
+	}
+}
+
+func TestQuadtreeRetrieveCircle(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	found := qt.RetrieveCircle(Point{X: 50, Y: 50}, 2, nil)
+	if len(found) != 4 {
+		t.Errorf("RetrieveCircle should find the 4 items around the center, got %d", len(found))
+	}
+	for _, i := range []int{44, 45, 54, 55} {
+		if !quadtreeContains(found, items[i]) {
+			t.Errorf("RetrieveCircle should find item %d", i)
+		}
+	}
+
+	if found := qt.RetrieveCircle(Point{X: 50, Y: 50}, 1, nil); len(found) != 0 {
+		t.Errorf("RetrieveCircle should not find the corners of the items outside the circle, got %d", len(found))
+	}
+
+	found = qt.RetrieveCircle(Point{X: 50, Y: 50}, 2, func(aabb AABBer) bool {
+		return aabb == items[44]
+	})
+	if len(found) != 1 || found[0] != items[44] {
+		t.Errorf("RetrieveCircle should respect the filter, got %v", found)
+	}
+}
+
+func TestQuadtreeRetrieveLine(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	found := qt.RetrieveLine(Line{P1: Point{X: 95, Y: 5}, P2: Point{X: 30, Y: 5}}, nil)
+	if len(found) != 7 {
+		t.Fatalf("RetrieveLine should find 7 items, got %d", len(found))
+	}
+	for i, f := range found {
+		if f != items[9-i] {
+			t.Errorf("RetrieveLine should order the items by distance, item %d is wrong", i)
+		}
+	}
+
+	if found := qt.RetrieveLine(Line{P1: Point{X: 0, Y: 10}, P2: Point{X: 100, Y: 10}}, nil); len(found) != 0 {
+		t.Errorf("RetrieveLine should not find items the line passes in between, got %d", len(found))
+	}
+}
+
+func TestQuadtreeRetrieveOriented(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	// A thin rectangle along the diagonal from (0, 0) to (100, 100)
+	d := float32(0.5)
+	corners := [4]Point{
+		{X: d, Y: -d},
+		{X: 100 + d, Y: 100 - d},
+		{X: -d, Y: d},
+		{X: 100 - d, Y: 100 + d},
+	}
+	found := qt.RetrieveOriented(corners, nil)
+	if len(found) != 10 {
+		t.Errorf("RetrieveOriented should find the 10 items on the diagonal, got %d", len(found))
+	}
+	for i := 0; i < 10; i++ {
+		if !quadtreeContains(found, items[i*11]) {
+			t.Errorf("RetrieveOriented should find item %d", i*11)
+		}
+	}
+}
+
+func TestQuadtreeRetrieveNearest(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	found := qt.RetrieveNearest(Point{X: 4, Y: 4}, 3, nil)
+	if len(found) != 3 {
+		t.Fatalf("RetrieveNearest should find 3 items, got %d", len(found))
+	}
+	if found[0] != items[0] {
+		t.Error("RetrieveNearest should return the item containing the point first")
+	}
+	if !quadtreeContains(found, items[1]) || !quadtreeContains(found, items[10]) {
+		t.Error("RetrieveNearest should return the neighbouring items")
+	}
+
+	far := &quadtreeTestItem{aabbRect(-1000, -1000, 1, 1)}
+	qt.Insert(far)
+	found = qt.RetrieveNearest(Point{X: -500, Y: -500}, 1, nil)
+	if len(found) != 1 || found[0] != far {
+		t.Errorf("RetrieveNearest should find items outside the bounds of the quadtree, got %v", found)
+	}
+
+	if found := qt.RetrieveNearest(Point{}, 200, nil); len(found) != len(items)+1 {
+		t.Errorf("RetrieveNearest should return all items if k exceeds the total, got %d", len(found))
+	}
+}
+
+func TestQuadtreeUpdate(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	item := items[0]
+	item.aabb = aabbRect(91, 91, 8, 8)
+	qt.Update(item)
+	item.aabb = aabbRect(92, 92, 6, 6)
+	qt.Update(item)
+
+	found := qt.Retrieve(aabbRect(0, 0, 10, 10), nil)
+	if quadtreeContains(found, item) {
+		t.Error("Updated item should no longer be found at its old location")
+	}
+	found = qt.RetrievePoint(Point{X: 95, Y: 95}, nil)
+	if len(found) != 2 || !quadtreeContains(found, item) {
+		t.Errorf("Updated item should be found at its new location, got %v", found)
+	}
+
+	// The item moved, but is still found using the location it was last updated at
+	item.aabb = aabbRect(500, 500, 1, 1)
+	qt.Remove(item)
+	if qt.Total != len(items)-1 {
+		t.Errorf("Total should be %d after removing an item, got %d", len(items)-1, qt.Total)
+	}
+	if found := qt.RetrievePoint(Point{X: 95, Y: 95}, nil); quadtreeContains(found, item) {
+		t.Error("Removed item should no longer be found")
+	}
+
+	for _, i := range items[1:] {
+		qt.Remove(i)
+	}
+	if qt.Total != 0 || qt.root.hasNodes || len(qt.root.Objects) != 0 {
+		t.Error("Quadtree should be empty after removing all items")
+	}
+}
+
+func TestQuadtreeInsertTwice(t *testing.T) {
+	qt, items := newQuadtreeTestGrid()
+
+	item := items[0]
+	item.aabb = aabbRect(91, 91, 8, 8)
+	qt.Insert(item)
+	if qt.Total != len(items) {
+		t.Errorf("Inserting an item twice shouldn't change Total, got %d", qt.Total)
+	}
+	if found := qt.RetrievePoint(Point{X: 5, Y: 5}, nil); quadtreeContains(found, item) {
+		t.Error("An item inserted again should no longer be found at its old location")
+	}
+
+	qt.Remove(item)
+	if found := qt.Retrieve(infiniteRegion(), nil); quadtreeContains(found, item) || len(found) != len(items)-1 {
+		t.Errorf("An item inserted twice should be removed at once, %d items left", len(found))
+	}
+}
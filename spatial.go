@@ -0,0 +1,324 @@
+package tango
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// SpatialIndex is a broad-phase structure which stores AABBer values and finds the ones within an area, so they don't
+// all have to be tested against each other. Quadtree suits bounded worlds, SpatialHash unbounded worlds of similarly
+// sized objects, and AABBTree unbounded worlds with objects of any size.
+//
+// Items are tracked by identity, as the keys of a map, so they have to be comparable and unique, preferably pointers:
+// inserting a struct holding a slice or a map panics, and values which are equal are the same item. Whenever an item
+// moves or changes size, Update has to be called for it.
+type SpatialIndex interface {
+	// Insert adds the given item to the index. Inserting an item which is already in the index moves it like Update.
+	Insert(item AABBer)
+	// Remove removes the given item from the index.
+	Remove(item AABBer)
+	// Update moves the given item to the location of its current bounding box, inserting it if needed.
+	Update(item AABBer)
+	// Clear removes all items from the index.
+	Clear()
+	// Retrieve returns all items overlapping the given bounding box and passing the given filter function.
+	Retrieve(find AABB, filter func(aabb AABBer) bool) []AABBer
+	// RetrievePoint returns all items containing the given point and passing the given filter function.
+	RetrievePoint(p Point, filter func(aabb AABBer) bool) []AABBer
+	// RetrieveCircle returns all items overlapping the given circle and passing the given filter function.
+	RetrieveCircle(center Point, radius float32, filter func(aabb AABBer) bool) []AABBer
+	// RetrieveLine returns all items intersecting the line segment l and passing the given filter function, ordered by
+	// the distance from l.P1 at which the line segment enters them.
+	RetrieveLine(l Line, filter func(aabb AABBer) bool) []AABBer
+	// RetrieveOriented returns all items overlapping the oriented rectangle with the given corners and passing the
+	// given filter function.
+	RetrieveOriented(corners [4]Point, filter func(aabb AABBer) bool) []AABBer
+	// RetrieveNearest returns up to k items passing the given filter function, ordered by their distance to p.
+	RetrieveNearest(p Point, k int, filter func(aabb AABBer) bool) []AABBer
+}
+
+var (
+	_ SpatialIndex = &Quadtree{}
+	_ SpatialIndex = &SpatialHash{}
+	_ SpatialIndex = &AABBTree{}
+)
+
+// OBBer is an interface for everything that provides information about its oriented bounding box, such as a rotated
+// common.SpaceComponent. The corners are in the order returned by common.SpaceComponent.Corners: the origin, the
+// corner along the width, the corner along the height and the opposite corner.
+//
+// The shape queries of the Quadtree test an OBBer against its oriented bounding box instead of its AABB.
+type OBBer interface {
+	AABBer
+	// Corners returns the four corners of the oriented bounding box.
+	Corners() [4]Point
+}
+
+// orientedRect is a rectangle described by its origin, the unit vectors along its sides and its size along them.
+type orientedRect struct {
+	origin        Point
+	axisX, axisY  Point
+	width, height float32
+}
+
+func orientedRectFromAABB(a AABB) orientedRect {
+	return orientedRect{
+		origin: a.Min,
+		axisX:  Point{X: 1},
+		axisY:  Point{Y: 1},
+		width:  aabbWidth(a),
+		height: aabbHeight(a),
+	}
+}
+
+func orientedRectFromCorners(corners [4]Point) orientedRect {
+	x := corners[1]
+	x.Subtract(corners[0])
+	y := corners[2]
+	y.Subtract(corners[0])
+
+	r := orientedRect{origin: corners[0]}
+	r.axisX, r.width = x.Normalize()
+	r.axisY, r.height = y.Normalize()
+
+	// A rectangle without width or height still needs two axes to be tested against
+	switch {
+	case r.width == 0 && r.height == 0:
+		r.axisX, r.axisY = Point{X: 1}, Point{Y: 1}
+	case r.width == 0:
+		r.axisX = Point{X: r.axisY.Y, Y: -r.axisY.X}
+	case r.height == 0:
+		r.axisY = Point{X: -r.axisX.Y, Y: r.axisX.X}
+	}
+	return r
+}
+
+// itemRect returns the rectangle an item is tested against in the shape queries
+func itemRect(item AABBer) orientedRect {
+	if o, ok := item.(OBBer); ok {
+		return orientedRectFromCorners(o.Corners())
+	}
+	return orientedRectFromAABB(item.AABB())
+}
+
+// local returns p in the coordinate system of the rectangle
+func (r orientedRect) local(p Point) Point {
+	p.Subtract(r.origin)
+	return Point{X: DotProduct(p, r.axisX), Y: DotProduct(p, r.axisY)}
+}
+
+func (r orientedRect) corners() [4]Point {
+	w := r.axisX
+	w.MultiplyScalar(r.width)
+	h := r.axisY
+	h.MultiplyScalar(r.height)
+
+	points := [4]Point{r.origin, r.origin, r.origin, r.origin}
+	points[1].Add(w)
+	points[2].Add(h)
+	points[3].Add(w).Add(h)
+	return points
+}
+
+func (r orientedRect) bounds() AABB {
+	points := r.corners()
+	b := AABB{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		b.Min.X = math32.Min(b.Min.X, p.X)
+		b.Min.Y = math32.Min(b.Min.Y, p.Y)
+		b.Max.X = math32.Max(b.Max.X, p.X)
+		b.Max.Y = math32.Max(b.Max.Y, p.Y)
+	}
+	return b
+}
+
+// contains reports whether p lies within the rectangle, including its border
+func (r orientedRect) contains(p Point) bool {
+	l := r.local(p)
+	return l.X >= 0 && l.X <= r.width && l.Y >= 0 && l.Y <= r.height
+}
+
+// distanceSquared returns the squared distance from p to the closest point of the rectangle, which is 0 if p is
+// contained in it
+func (r orientedRect) distanceSquared(p Point) float32 {
+	l := r.local(p)
+	dx := l.X - math32.Clamp(l.X, 0, r.width)
+	dy := l.Y - math32.Clamp(l.Y, 0, r.height)
+	return dx*dx + dy*dy
+}
+
+// lineFraction returns the fraction of the line segment l at which it enters the rectangle, and false if it doesn't
+// intersect the rectangle at all. A line segment starting within the rectangle enters it at 0.
+func (r orientedRect) lineFraction(l Line) (float32, bool) {
+	p1 := r.local(l.P1)
+	p2 := r.local(l.P2)
+
+	tMin, tMax := float32(0), float32(1)
+	slabs := [2]struct{ start, delta, size float32 }{
+		{p1.X, p2.X - p1.X, r.width},
+		{p1.Y, p2.Y - p1.Y, r.height},
+	}
+	for _, s := range slabs {
+		if s.delta == 0 {
+			if s.start < 0 || s.start > s.size {
+				return 0, false
+			}
+			continue
+		}
+		t1 := -s.start / s.delta
+		t2 := (s.size - s.start) / s.delta
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin = math32.Max(tMin, t1)
+		tMax = math32.Min(tMax, t2)
+		if tMin > tMax {
+			return 0, false
+		}
+	}
+	return tMin, true
+}
+
+// overlaps reports whether the two rectangles overlap, using the separating axis theorem
+func (r orientedRect) overlaps(o orientedRect) bool {
+	a, b := r.corners(), o.corners()
+	for _, axis := range [4]Point{r.axisX, r.axisY, o.axisX, o.axisY} {
+		aMin, aMax := projectPoints(a, axis)
+		bMin, bMax := projectPoints(b, axis)
+		if aMax < bMin || bMax < aMin {
+			return false
+		}
+	}
+	return true
+}
+
+func projectPoints(points [4]Point, axis Point) (min, max float32) {
+	min = DotProduct(points[0], axis)
+	max = min
+	for _, p := range points[1:] {
+		d := DotProduct(p, axis)
+		min = math32.Min(min, d)
+		max = math32.Max(max, d)
+	}
+	return min, max
+}
+
+// spatialVisit calls fn for every item of a SpatialIndex which may overlap find. Every item is passed at most once.
+type spatialVisit func(find AABB, fn func(item AABBer))
+
+// retrieveShape returns all items within the bounding box find which hit the shape and pass the given filter function
+func retrieveShape(visit spatialVisit, find AABB, hit func(orientedRect) bool, filter func(aabb AABBer) bool) []AABBer {
+	var found []AABBer
+
+	visit(find, func(item AABBer) {
+		if hit(itemRect(item)) && (filter == nil || filter(item)) {
+			found = append(found, item)
+		}
+	})
+
+	return found
+}
+
+// retrieveAABB returns all items of which the AABB overlaps find and which pass the given filter function
+func retrieveAABB(visit spatialVisit, find AABB, filter func(aabb AABBer) bool) []AABBer {
+	var found []AABBer
+
+	visit(find, func(item AABBer) {
+		if aabbOverlaps(find, item.AABB()) && (filter == nil || filter(item)) {
+			found = append(found, item)
+		}
+	})
+
+	return found
+}
+
+func retrievePoint(visit spatialVisit, p Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveShape(visit, AABB{Min: p, Max: p}, func(r orientedRect) bool {
+		return r.contains(p)
+	}, filter)
+}
+
+func retrieveCircle(visit spatialVisit, center Point, radius float32, filter func(aabb AABBer) bool) []AABBer {
+	find := AABB{
+		Min: Point{X: center.X - radius, Y: center.Y - radius},
+		Max: Point{X: center.X + radius, Y: center.Y + radius},
+	}
+	return retrieveShape(visit, find, func(r orientedRect) bool {
+		return r.distanceSquared(center) <= radius*radius
+	}, filter)
+}
+
+func retrieveOriented(visit spatialVisit, corners [4]Point, filter func(aabb AABBer) bool) []AABBer {
+	rect := orientedRectFromCorners(corners)
+	return retrieveShape(visit, rect.bounds(), rect.overlaps, filter)
+}
+
+func retrieveLine(visit spatialVisit, l Line, filter func(aabb AABBer) bool) []AABBer {
+	type lineHit struct {
+		item     AABBer
+		fraction float32
+	}
+	var hits []lineHit
+
+	find := AABB{
+		Min: Point{X: math32.Min(l.P1.X, l.P2.X), Y: math32.Min(l.P1.Y, l.P2.Y)},
+		Max: Point{X: math32.Max(l.P1.X, l.P2.X), Y: math32.Max(l.P1.Y, l.P2.Y)},
+	}
+	visit(find, func(item AABBer) {
+		fraction, ok := itemRect(item).lineFraction(l)
+		if ok && (filter == nil || filter(item)) {
+			hits = append(hits, lineHit{item, fraction})
+		}
+	})
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].fraction < hits[j].fraction
+	})
+
+	found := make([]AABBer, len(hits))
+	for i, h := range hits {
+		found[i] = h.item
+	}
+	return found
+}
+
+// aabbDistanceSquared returns the squared distance from p to the closest point of the bounding box. The bounding box
+// may extend to infinity.
+func aabbDistanceSquared(a AABB, p Point) float32 {
+	dx := math32.Max(math32.Max(a.Min.X-p.X, p.X-a.Max.X), 0)
+	dy := math32.Max(math32.Max(a.Min.Y-p.Y, p.Y-a.Max.Y), 0)
+	return dx*dx + dy*dy
+}
+
+// nearestCandidate is either an item or a part of a SpatialIndex queued during a nearest neighbour search. The
+// distance of a part is the lowest distance any item within it can have.
+type nearestCandidate struct {
+	distance float32
+	item     AABBer
+	node     interface{}
+	region   AABB
+}
+
+// nearestQueue is a priority queue of candidates, closest first
+type nearestQueue []nearestCandidate
+
+func (q nearestQueue) Len() int            { return len(q) }
+func (q nearestQueue) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q nearestQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nearestQueue) Push(x interface{}) { *q = append(*q, x.(nearestCandidate)) }
+func (q *nearestQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	x := old[n-1]
+	*q = old[:n-1]
+	return x
+}
+
+// pushItem queues the given item if it passes the filter function
+func (q *nearestQueue) pushItem(p Point, item AABBer, filter func(aabb AABBer) bool) {
+	if filter == nil || filter(item) {
+		heap.Push(q, nearestCandidate{distance: itemRect(item).distanceSquared(p), item: item})
+	}
+}
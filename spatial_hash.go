@@ -0,0 +1,285 @@
+package tango
+
+import (
+	"container/heap"
+
+	"github.com/inkeliz-technologies/tango/math32"
+)
+
+// maxSpatialHashCell is the largest cell coordinate of a SpatialHash, which keeps the coordinates of infinite or very
+// large bounding boxes from overflowing.
+const maxSpatialHashCell = 1 << 30
+
+type spatialHashCell struct {
+	X, Y int
+}
+
+type spatialHashEntry struct {
+	Value    AABBer
+	AABB     AABB
+	min, max spatialHashCell
+	query    uint32
+}
+
+// SpatialHash is a SpatialIndex which divides the world into a uniform grid of square cells, of which only the
+// occupied ones are stored. It's unbounded and cheap to update, which makes it well suited for large worlds full of
+// small moving objects. Objects spanning many cells are stored in all of them, so the cells should be larger than
+// most objects.
+type SpatialHash struct {
+	CellSize float32 // Width and height of every cell
+	cells    map[spatialHashCell][]*spatialHashEntry
+	items    map[AABBer]*spatialHashEntry
+	query    uint32
+}
+
+// NewSpatialHash creates a new spatial hash with cells of the given size.
+func NewSpatialHash(cellSize float32) *SpatialHash {
+	return &SpatialHash{
+		CellSize: cellSize,
+		cells:    make(map[spatialHashCell][]*spatialHashEntry),
+		items:    make(map[AABBer]*spatialHashEntry),
+	}
+}
+
+// cellCoordinate returns the coordinate of the cell containing the given coordinate
+func (sh *SpatialHash) cellCoordinate(v float32) int {
+	return int(math32.Clamp(math32.Floor(v/sh.CellSize), -maxSpatialHashCell, maxSpatialHashCell))
+}
+
+// cellRange returns the first and last cell overlapping the given bounding box
+func (sh *SpatialHash) cellRange(a AABB) (min, max spatialHashCell) {
+	min = spatialHashCell{sh.cellCoordinate(a.Min.X), sh.cellCoordinate(a.Min.Y)}
+	max = spatialHashCell{sh.cellCoordinate(a.Max.X), sh.cellCoordinate(a.Max.Y)}
+	return min, max
+}
+
+func cellInRange(c, min, max spatialHashCell) bool {
+	return c.X >= min.X && c.X <= max.X && c.Y >= min.Y && c.Y <= max.Y
+}
+
+func (sh *SpatialHash) addToCells(e *spatialHashEntry, min, max spatialHashCell, skipMin, skipMax spatialHashCell, skip bool) {
+	for y := min.Y; y <= max.Y; y++ {
+		for x := min.X; x <= max.X; x++ {
+			c := spatialHashCell{x, y}
+			if skip && cellInRange(c, skipMin, skipMax) {
+				continue
+			}
+			sh.cells[c] = append(sh.cells[c], e)
+		}
+	}
+}
+
+func (sh *SpatialHash) removeFromCells(e *spatialHashEntry, min, max spatialHashCell, skipMin, skipMax spatialHashCell, skip bool) {
+	for y := min.Y; y <= max.Y; y++ {
+		for x := min.X; x <= max.X; x++ {
+			c := spatialHashCell{x, y}
+			if skip && cellInRange(c, skipMin, skipMax) {
+				continue
+			}
+			entries := sh.cells[c]
+			for i, o := range entries {
+				if o == e {
+					entries[i] = entries[len(entries)-1]
+					entries[len(entries)-1] = nil
+					entries = entries[:len(entries)-1]
+					break
+				}
+			}
+			if len(entries) == 0 {
+				delete(sh.cells, c)
+			} else {
+				sh.cells[c] = entries
+			}
+		}
+	}
+}
+
+// Insert inserts the given item to the spatial hash. Inserting an item which is already in the spatial hash moves it
+// like Update.
+func (sh *SpatialHash) Insert(item AABBer) {
+	if _, ok := sh.items[item]; ok {
+		sh.Update(item)
+		return
+	}
+
+	pRect := item.AABB()
+	e := &spatialHashEntry{Value: item, AABB: pRect}
+	e.min, e.max = sh.cellRange(pRect)
+	sh.items[item] = e
+	sh.addToCells(e, e.min, e.max, spatialHashCell{}, spatialHashCell{}, false)
+}
+
+// Remove removes the given item from the spatial hash
+func (sh *SpatialHash) Remove(item AABBer) {
+	e, ok := sh.items[item]
+	if !ok {
+		return
+	}
+	delete(sh.items, item)
+	sh.removeFromCells(e, e.min, e.max, spatialHashCell{}, spatialHashCell{}, false)
+}
+
+// Update moves the given item to the location of its current bounding box. Only the cells the item left or entered
+// are changed. Items not in the spatial hash are inserted.
+func (sh *SpatialHash) Update(item AABBer) {
+	e, ok := sh.items[item]
+	if !ok {
+		sh.Insert(item)
+		return
+	}
+
+	e.AABB = item.AABB()
+	min, max := sh.cellRange(e.AABB)
+	if min == e.min && max == e.max {
+		return
+	}
+
+	sh.removeFromCells(e, e.min, e.max, min, max, true)
+	sh.addToCells(e, min, max, e.min, e.max, true)
+	e.min, e.max = min, max
+}
+
+// Clear removes all items from the spatial hash
+func (sh *SpatialHash) Clear() {
+	sh.cells = make(map[spatialHashCell][]*spatialHashEntry)
+	sh.items = make(map[AABBer]*spatialHashEntry)
+}
+
+// Len returns the number of items in the spatial hash
+func (sh *SpatialHash) Len() int {
+	return len(sh.items)
+}
+
+// nextQuery returns the number of a new query, used to pass items spanning multiple cells only once
+func (sh *SpatialHash) nextQuery() uint32 {
+	sh.query++
+	if sh.query == 0 {
+		// Wrapped around, so older marks may match again
+		for _, e := range sh.items {
+			e.query = 0
+		}
+		sh.query++
+	}
+	return sh.query
+}
+
+func (sh *SpatialHash) visitCell(c spatialHashCell, query uint32, fn func(item AABBer)) {
+	for _, e := range sh.cells[c] {
+		if e.query != query {
+			e.query = query
+			fn(e.Value)
+		}
+	}
+}
+
+func (sh *SpatialHash) visit(find AABB, fn func(item AABBer)) {
+	query := sh.nextQuery()
+	min, max := sh.cellRange(find)
+
+	// Large areas are cheaper to check by going through the occupied cells instead
+	if float64(max.X-min.X+1)*float64(max.Y-min.Y+1) > float64(len(sh.cells)) {
+		for c := range sh.cells {
+			if cellInRange(c, min, max) {
+				sh.visitCell(c, query, fn)
+			}
+		}
+		return
+	}
+
+	for y := min.Y; y <= max.Y; y++ {
+		for x := min.X; x <= max.X; x++ {
+			sh.visitCell(spatialHashCell{x, y}, query, fn)
+		}
+	}
+}
+
+// Retrieve returns all objects that could collide with the given bounding box and passing the given filter function.
+func (sh *SpatialHash) Retrieve(find AABB, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveAABB(sh.visit, find, filter)
+}
+
+// RetrievePoint returns all objects containing the given point and passing the given filter function.
+func (sh *SpatialHash) RetrievePoint(p Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrievePoint(sh.visit, p, filter)
+}
+
+// RetrieveCircle returns all objects overlapping the circle with the given center and radius and passing the given
+// filter function.
+func (sh *SpatialHash) RetrieveCircle(center Point, radius float32, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveCircle(sh.visit, center, radius, filter)
+}
+
+// RetrieveOriented returns all objects overlapping the oriented rectangle with the given corners and passing the given
+// filter function. The corners are in the order returned by common.SpaceComponent.Corners.
+func (sh *SpatialHash) RetrieveOriented(corners [4]Point, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveOriented(sh.visit, corners, filter)
+}
+
+// RetrieveLine returns all objects intersecting the line segment l and passing the given filter function, ordered by
+// the distance from l.P1 at which the line segment enters them.
+func (sh *SpatialHash) RetrieveLine(l Line, filter func(aabb AABBer) bool) []AABBer {
+	return retrieveLine(sh.visit, l, filter)
+}
+
+// RetrieveNearest returns up to k objects passing the given filter function, ordered by their distance to the point
+// p. Objects containing p have a distance of 0.
+//
+// The search goes through the cells in rings around p, so it's fastest when the nearest objects are only a few cells
+// away.
+func (sh *SpatialHash) RetrieveNearest(p Point, k int, filter func(aabb AABBer) bool) []AABBer {
+	if k <= 0 {
+		return nil
+	}
+
+	var (
+		found []AABBer
+		queue nearestQueue
+		seen  int
+	)
+	query := sh.nextQuery()
+	push := func(item AABBer) {
+		seen++
+		queue.pushItem(p, item, filter)
+	}
+	center := spatialHashCell{sh.cellCoordinate(p.X), sh.cellCoordinate(p.Y)}
+
+	for ring := 0; len(found) < k; ring++ {
+		if seen == len(sh.items) {
+			// Every item is queued, so the remaining ones only need to be sorted
+			for len(queue) > 0 && len(found) < k {
+				found = append(found, heap.Pop(&queue).(nearestCandidate).item)
+			}
+			break
+		}
+
+		if 8*ring > len(sh.cells) {
+			// The rings became larger than the amount of occupied cells, so queue the remaining items at once
+			for c := range sh.cells {
+				sh.visitCell(c, query, push)
+			}
+			continue
+		}
+
+		if ring == 0 {
+			sh.visitCell(center, query, push)
+		} else {
+			for i := -ring; i <= ring; i++ {
+				sh.visitCell(spatialHashCell{center.X + i, center.Y - ring}, query, push)
+				sh.visitCell(spatialHashCell{center.X + i, center.Y + ring}, query, push)
+			}
+			for i := -ring + 1; i < ring; i++ {
+				sh.visitCell(spatialHashCell{center.X - ring, center.Y + i}, query, push)
+				sh.visitCell(spatialHashCell{center.X + ring, center.Y + i}, query, push)
+			}
+		}
+
+		// Items which aren't queued yet only occupy cells outside of the rings visited so far, so they are at least
+		// ring cells away from p
+		bound := float32(ring) * sh.CellSize
+		for len(queue) > 0 && len(found) < k && queue[0].distance <= bound*bound {
+			found = append(found, heap.Pop(&queue).(nearestCandidate).item)
+		}
+	}
+
+	return found
+}
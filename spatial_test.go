@@ -0,0 +1,308 @@
+package tango
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var spatialIndexes = []struct {
+	name string
+	new  func() SpatialIndex
+}{
+	{"Quadtree", func() SpatialIndex { return NewQuadtree(aabbRect(-5000, -5000, 10000, 10000), false, 8) }},
+	{"SpatialHash", func() SpatialIndex { return NewSpatialHash(32) }},
+	{"AABBTree", func() SpatialIndex { return NewAABBTree(4) }},
+}
+
+// newSpatialTestItems returns small items spread over the given area
+func newSpatialTestItems(rng *rand.Rand, n int, size float32) []*quadtreeTestItem {
+	items := make([]*quadtreeTestItem, n)
+	for i := range items {
+		items[i] = &quadtreeTestItem{aabbRect(
+			(rng.Float32()-0.5)*size, (rng.Float32()-0.5)*size,
+			1+rng.Float32()*20, 1+rng.Float32()*20,
+		)}
+	}
+	return items
+}
+
+func moveSpatialTestItem(rng *rand.Rand, item *quadtreeTestItem, distance float32) {
+	dx := (rng.Float32() - 0.5) * distance
+	dy := (rng.Float32() - 0.5) * distance
+	item.aabb.Min.X += dx
+	item.aabb.Max.X += dx
+	item.aabb.Min.Y += dy
+	item.aabb.Max.Y += dy
+}
+
+func sameSpatialItems(t *testing.T, query string, expected, found []AABBer) {
+	if len(expected) != len(found) {
+		t.Errorf("%s should find %d items, got %d", query, len(expected), len(found))
+		return
+	}
+	for _, e := range expected {
+		if !quadtreeContains(found, e) {
+			t.Errorf("%s didn't find %v", query, e)
+			return
+		}
+	}
+}
+
+func TestSpatialIndexes(t *testing.T) {
+	for _, index := range spatialIndexes {
+		t.Run(index.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			items := newSpatialTestItems(rng, 500, 1000)
+			idx := index.new()
+			for _, item := range items {
+				idx.Insert(item)
+			}
+
+			for round := 0; round < 10; round++ {
+				// Move the items around
+				for _, item := range items {
+					moveSpatialTestItem(rng, item, 100)
+					idx.Update(item)
+				}
+				// Replace some of the items
+				for i := 0; i < 20; i++ {
+					j := rng.Intn(len(items))
+					idx.Remove(items[j])
+					items[j] = newSpatialTestItems(rng, 1, 1000)[0]
+					idx.Insert(items[j])
+				}
+
+				expect := func(hit func(item AABBer) bool) []AABBer {
+					var found []AABBer
+					for _, item := range items {
+						if hit(item) {
+							found = append(found, item)
+						}
+					}
+					return found
+				}
+
+				find := aabbRect((rng.Float32()-0.5)*1000, (rng.Float32()-0.5)*1000, 200, 100)
+				sameSpatialItems(t, "Retrieve", expect(func(item AABBer) bool {
+					return aabbOverlaps(find, item.AABB())
+				}), idx.Retrieve(find, nil))
+
+				p := Point{X: (rng.Float32() - 0.5) * 1000, Y: (rng.Float32() - 0.5) * 1000}
+				sameSpatialItems(t, "RetrievePoint", expect(func(item AABBer) bool {
+					return itemRect(item).contains(p)
+				}), idx.RetrievePoint(p, nil))
+
+				sameSpatialItems(t, "RetrieveCircle", expect(func(item AABBer) bool {
+					return itemRect(item).distanceSquared(p) <= 50*50
+				}), idx.RetrieveCircle(p, 50, nil))
+
+				l := Line{P1: p, P2: Point{X: -p.Y, Y: p.X}}
+				sameSpatialItems(t, "RetrieveLine", expect(func(item AABBer) bool {
+					_, ok := itemRect(item).lineFraction(l)
+					return ok
+				}), idx.RetrieveLine(l, nil))
+
+				corners := [4]Point{{X: 0, Y: -200}, {X: 200, Y: 0}, {X: -20, Y: -180}, {X: 180, Y: 20}}
+				rect := orientedRectFromCorners(corners)
+				sameSpatialItems(t, "RetrieveOriented", expect(func(item AABBer) bool {
+					return rect.overlaps(itemRect(item))
+				}), idx.RetrieveOriented(corners, nil))
+
+				distances := make([]float32, len(items))
+				for i, item := range items {
+					distances[i] = itemRect(item).distanceSquared(p)
+				}
+				sort.Slice(distances, func(i, j int) bool { return distances[i] < distances[j] })
+				found := idx.RetrieveNearest(p, 10, nil)
+				if len(found) != 10 {
+					t.Fatalf("RetrieveNearest should find 10 items, got %d", len(found))
+				}
+				for i, item := range found {
+					if d := itemRect(item).distanceSquared(p); !FloatEqual(d, distances[i]) {
+						t.Errorf("RetrieveNearest item %d should have distance %v, got %v", i, distances[i], d)
+					}
+				}
+			}
+
+			idx.Clear()
+			if found := idx.Retrieve(infiniteRegion(), nil); len(found) != 0 {
+				t.Errorf("Clear should remove all items, %d left", len(found))
+			}
+		})
+	}
+}
+
+func TestSpatialIndexesInsertTwice(t *testing.T) {
+	for _, index := range spatialIndexes {
+		t.Run(index.name, func(t *testing.T) {
+			idx := index.new()
+			item := &quadtreeTestItem{aabbRect(0, 0, 10, 10)}
+			idx.Insert(item)
+			item.aabb = aabbRect(500, 500, 10, 10)
+			idx.Insert(item)
+
+			if found := idx.Retrieve(infiniteRegion(), nil); len(found) != 1 {
+				t.Errorf("An item inserted twice should be found once, got %d", len(found))
+			}
+			if found := idx.RetrievePoint(Point{X: 5, Y: 5}, nil); len(found) != 0 {
+				t.Error("An item inserted again should no longer be found where it was")
+			}
+			if found := idx.RetrievePoint(Point{X: 505, Y: 505}, nil); len(found) != 1 {
+				t.Error("An item inserted again should be found where it is")
+			}
+
+			idx.Remove(item)
+			if found := idx.Retrieve(infiniteRegion(), nil); len(found) != 0 {
+				t.Errorf("An item inserted twice should be removed at once, %d left", len(found))
+			}
+		})
+	}
+}
+
+func TestSpatialHashNearestSparse(t *testing.T) {
+	sh := NewSpatialHash(1)
+	far := &quadtreeTestItem{aabbRect(1e6, 1e6, 1, 1)}
+	near := &quadtreeTestItem{aabbRect(-1e5, 0, 1, 1)}
+	sh.Insert(far)
+	sh.Insert(near)
+
+	found := sh.RetrieveNearest(Point{}, 5, nil)
+	if len(found) != 2 || found[0] != near || found[1] != far {
+		t.Errorf("RetrieveNearest should find far away items in order, got %v", found)
+	}
+}
+
+// checkAABBTree verifies the links, heights and bounding boxes of the subtree and returns the amount of leaves
+func checkAABBTree(t *testing.T, n *aabbTreeNode) int {
+	if n.isLeaf() {
+		if n.height != 0 {
+			t.Errorf("Leaf should have height 0, got %d", n.height)
+		}
+		return 1
+	}
+	for _, child := range [2]*aabbTreeNode{n.left, n.right} {
+		if child.parent != n {
+			t.Error("Child should link back to its parent")
+		}
+		if !aabbContains(n.AABB, child.AABB) {
+			t.Error("Node should contain the bounding boxes of its children")
+		}
+	}
+	if n.height != 1+n.left.height && n.height != 1+n.right.height {
+		t.Errorf("Node has wrong height %d", n.height)
+	}
+	return checkAABBTree(t, n.left) + checkAABBTree(t, n.right)
+}
+
+func TestAABBTreeStructure(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	tree := NewAABBTree(2)
+
+	// Inserting items in order would degenerate an unbalanced tree into a list
+	var items []*quadtreeTestItem
+	for i := 0; i < 1024; i++ {
+		item := &quadtreeTestItem{aabbRect(float32(i)*10, 0, 5, 5)}
+		items = append(items, item)
+		tree.Insert(item)
+	}
+	if leaves := checkAABBTree(t, tree.root); leaves != len(items) {
+		t.Errorf("Tree should have %d leaves, got %d", len(items), leaves)
+	}
+	if tree.root.height > 20 {
+		t.Errorf("Tree should stay balanced, got height %d", tree.root.height)
+	}
+
+	// Small movements stay within the fattened bounding box
+	leaf := tree.items[items[0]]
+	items[0].aabb.Min.X++
+	items[0].aabb.Max.X++
+	tree.Update(items[0])
+	if tree.items[items[0]] != leaf || leaf.AABB != tree.fatten(aabbRect(0, 0, 5, 5)) {
+		t.Error("Moving within the margin should not change the tree")
+	}
+
+	for _, item := range items[:1000] {
+		moveSpatialTestItem(rng, item, 100)
+		tree.Update(item)
+	}
+	for _, item := range items[:1000] {
+		tree.Remove(item)
+	}
+	if leaves := checkAABBTree(t, tree.root); leaves != 24 || tree.Len() != 24 {
+		t.Errorf("Tree should have 24 leaves after removing items, got %d", leaves)
+	}
+}
+
+const (
+	benchmarkSpatialItems = 10000
+	benchmarkSpatialWorld = 10000
+)
+
+func newBenchmarkSpatialIndex(newIndex func() SpatialIndex) (SpatialIndex, []*quadtreeTestItem) {
+	items := newSpatialTestItems(rand.New(rand.NewSource(1)), benchmarkSpatialItems, benchmarkSpatialWorld)
+	idx := newIndex()
+	for _, item := range items {
+		idx.Insert(item)
+	}
+	return idx, items
+}
+
+func BenchmarkSpatialIndex_Insert(b *testing.B) {
+	for _, index := range spatialIndexes {
+		b.Run(index.name, func(b *testing.B) {
+			items := newSpatialTestItems(rand.New(rand.NewSource(1)), benchmarkSpatialItems, benchmarkSpatialWorld)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				idx := index.new()
+				for _, item := range items {
+					idx.Insert(item)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialIndex_Update(b *testing.B) {
+	for _, index := range spatialIndexes {
+		b.Run(index.name, func(b *testing.B) {
+			idx, items := newBenchmarkSpatialIndex(index.new)
+			rng := rand.New(rand.NewSource(2))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				item := items[i%len(items)]
+				moveSpatialTestItem(rng, item, 5)
+				idx.Update(item)
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialIndex_Retrieve(b *testing.B) {
+	for _, index := range spatialIndexes {
+		b.Run(index.name, func(b *testing.B) {
+			idx, _ := newBenchmarkSpatialIndex(index.new)
+			rng := rand.New(rand.NewSource(2))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				x := (rng.Float32() - 0.5) * benchmarkSpatialWorld
+				y := (rng.Float32() - 0.5) * benchmarkSpatialWorld
+				idx.Retrieve(aabbRect(x, y, 200, 200), nil)
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialIndex_RetrieveNearest(b *testing.B) {
+	for _, index := range spatialIndexes {
+		b.Run(index.name, func(b *testing.B) {
+			idx, _ := newBenchmarkSpatialIndex(index.new)
+			rng := rand.New(rand.NewSource(2))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				p := Point{X: (rng.Float32() - 0.5) * benchmarkSpatialWorld, Y: (rng.Float32() - 0.5) * benchmarkSpatialWorld}
+				idx.RetrieveNearest(p, 8, nil)
+			}
+		})
+	}
+}
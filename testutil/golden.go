@@ -0,0 +1,123 @@
+package testutil
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// UpdateGolden writes the images compared by CompareGolden as the golden images, in place of comparing them. It's set
+// when the TANGO_UPDATE_GOLDEN environment variable isn't empty, such as to create the golden images of new tests,
+// or to accept the changes in existing ones.
+var UpdateGolden = os.Getenv("TANGO_UPDATE_GOLDEN") != ""
+
+var diffMismatch = color.NRGBA{R: 0xff, A: 0xff}
+
+// Diff compares both images pixel by pixel, and returns the number of pixels of which a channel differs by more than
+// the tolerance. The returned image shows the pixels that differ in red, over a faded copy of the expected image.
+// The images must have the same size.
+func Diff(expected, actual image.Image, tolerance uint8) (*image.NRGBA, int) {
+	bounds := expected.Bounds()
+	diff := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	offset := actual.Bounds().Min.Sub(bounds.Min)
+
+	mismatched := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			e := color.NRGBAModel.Convert(expected.At(x, y)).(color.NRGBA)
+			a := color.NRGBAModel.Convert(actual.At(x+offset.X, y+offset.Y)).(color.NRGBA)
+			if differs(e.R, a.R, tolerance) || differs(e.G, a.G, tolerance) ||
+				differs(e.B, a.B, tolerance) || differs(e.A, a.A, tolerance) {
+				mismatched++
+				diff.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, diffMismatch)
+				continue
+			}
+
+			faded := 0xc0 + color.GrayModel.Convert(e).(color.Gray).Y/4
+			diff.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.NRGBA{R: faded, G: faded, B: faded, A: 0xff})
+		}
+	}
+	return diff, mismatched
+}
+
+func differs(a, b, tolerance uint8) bool {
+	if a > b {
+		return a-b > tolerance
+	}
+	return b-a > tolerance
+}
+
+// CompareGolden compares the image against the golden PNG image at the path, allowing each channel of a pixel to
+// differ by the tolerance. If they differ, the test fails, and the image and a Diff are written next to the golden
+// image, with the .actual.png and .diff.png extensions, to look into what changed. With UpdateGolden, the image is
+// written as the golden image instead.
+func CompareGolden(t TB, path string, img image.Image, tolerance uint8) bool {
+	t.Helper()
+	if UpdateGolden {
+		if err := writePNG(path, img); err != nil {
+			t.Fatalf("writing golden image %s failed: %v", path, err)
+		}
+		t.Logf("updated golden image %s", path)
+		return true
+	}
+
+	actualPath := strings.TrimSuffix(path, ".png") + ".actual.png"
+	diffPath := strings.TrimSuffix(path, ".png") + ".diff.png"
+
+	golden, err := readPNG(path)
+	if err != nil {
+		writeFailure(t, actualPath, img)
+		t.Errorf("reading golden image %s failed: %v; set TANGO_UPDATE_GOLDEN to create it", path, err)
+		return false
+	}
+
+	if golden.Bounds().Size() != img.Bounds().Size() {
+		writeFailure(t, actualPath, img)
+		t.Errorf("image is %v, but golden image %s is %v; the image was written to %s",
+			img.Bounds().Size(), path, golden.Bounds().Size(), actualPath)
+		return false
+	}
+
+	diff, mismatched := Diff(golden, img, tolerance)
+	if mismatched > 0 {
+		writeFailure(t, actualPath, img)
+		writeFailure(t, diffPath, diff)
+		t.Errorf("%d pixels differ from golden image %s by more than %d; the image was written to %s, and the difference to %s",
+			mismatched, path, tolerance, actualPath, diffPath)
+		return false
+	}
+
+	// Leftovers of earlier failures would be mistaken for new ones
+	os.Remove(actualPath)
+	os.Remove(diffPath)
+	return true
+}
+
+func writeFailure(t TB, path string, img image.Image) {
+	if err := writePNG(path, img); err != nil {
+		t.Logf("writing %s failed: %v", path, err)
+	}
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,102 @@
+// Package testutil runs scenes headless in tests, and compares the frames they draw against golden images.
+package testutil
+
+import (
+	"image"
+
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/common"
+)
+
+// DefaultDelta is the time passing in every frame stepped by a Harness, unless its Delta is changed.
+const DefaultDelta float32 = 1.0 / 60
+
+// TB is the part of testing.TB used by the Harness and CompareGolden.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Logf(format string, args ...interface{})
+}
+
+// Harness runs a Scene headless, with common.SoftwareRendering, one frame at a time. The time passing and the input
+// are controlled by the test, so each frame is drawn the same every time the test is run.
+type Harness struct {
+	// Delta is the time passing in every frame, in seconds.
+	Delta float32
+	// Tolerance is how much each channel of a pixel may differ from the golden image.
+	Tolerance uint8
+
+	t TB
+}
+
+// NewHarness runs the Scene headless on a canvas of the given size, and returns a Harness to step through its frames.
+// Preload and Setup are called before it returns. Close must be called once the test is done.
+func NewHarness(t TB, width, height int, scene tango.Scene) *Harness {
+	t.Helper()
+	common.SoftwareRendering = true
+	tango.Run(tango.RunOptions{HeadlessMode: true, NoRun: true, Width: width, Height: height}, scene)
+	return &Harness{Delta: DefaultDelta, t: t}
+}
+
+// Close stops rendering with the CPU, so other tests run headless as before.
+func (h *Harness) Close() {
+	common.SoftwareRendering = false
+}
+
+// Step runs the given number of frames, with Delta passing in each of them, as measured by tango.Time as well.
+func (h *Harness) Step(frames int) {
+	for i := 0; i < frames; i++ {
+		tango.Step(h.Delta)
+	}
+}
+
+// KeyDown presses the key. Systems see it as just pressed during the next frame, and held down after that.
+func (h *Harness) KeyDown(k tango.Key) {
+	tango.Input.SetKey(k, true)
+}
+
+// KeyUp releases the key. Systems see it as just released during the next frame.
+func (h *Harness) KeyUp(k tango.Key) {
+	tango.Input.SetKey(k, false)
+}
+
+// MouseMove moves the mouse to the position on the canvas.
+func (h *Harness) MouseMove(x, y float32) {
+	tango.Input.Mouse.X, tango.Input.Mouse.Y = x, y
+	if tango.Input.Mouse.Action != tango.Press && tango.Input.Mouse.Action != tango.Release {
+		tango.Input.Mouse.Action = tango.Move
+	}
+}
+
+// MouseDown presses the mouse button at the position of the mouse.
+func (h *Harness) MouseDown(b tango.MouseButton) {
+	tango.Input.Mouse.Button = b
+	tango.Input.Mouse.Action = tango.Press
+	tango.Input.SetMouseButton(b, true)
+}
+
+// MouseUp releases the mouse button at the position of the mouse.
+func (h *Harness) MouseUp(b tango.MouseButton) {
+	tango.Input.Mouse.Button = b
+	tango.Input.Mouse.Action = tango.Release
+	tango.Input.SetMouseButton(b, false)
+}
+
+// Capture returns the last frame which was drawn. It fails the test if there is none, such as when the Scene has no
+// RenderSystem.
+func (h *Harness) Capture() *image.NRGBA {
+	h.t.Helper()
+	img, err := common.Screenshot()
+	if err != nil {
+		h.t.Fatalf("capturing the frame failed: %v", err)
+	}
+	return img
+}
+
+// AssertGolden compares the last frame which was drawn against the golden image at the path, with the Tolerance of
+// the Harness. See CompareGolden.
+func (h *Harness) AssertGolden(path string) bool {
+	h.t.Helper()
+	return CompareGolden(h.t, path, h.Capture(), h.Tolerance)
+}
@@ -0,0 +1,142 @@
+package testutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inkeliz-technologies/ecs"
+	"github.com/inkeliz-technologies/tango"
+	"github.com/inkeliz-technologies/tango/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type goldenTestScene struct {
+	player *common.SpaceComponent
+}
+
+func (*goldenTestScene) Preload() {}
+
+func (s *goldenTestScene) Setup(u tango.Updater) {
+	w := u.(*ecs.World)
+	common.SetBackground(color.White)
+	rs := &common.RenderSystem{}
+	w.AddSystem(rs)
+	w.AddSystem(&goldenTestMover{scene: s})
+
+	ground := ecs.NewBasic()
+	rs.Add(&ground, &common.RenderComponent{Drawable: common.Rectangle{}, Color: color.RGBA{G: 0x80, A: 0xff}},
+		&common.SpaceComponent{Position: tango.Point{Y: 48}, Width: 64, Height: 16})
+
+	player := ecs.NewBasic()
+	s.player = &common.SpaceComponent{Position: tango.Point{X: 8, Y: 32}, Width: 16, Height: 16}
+	rs.Add(&player, &common.RenderComponent{
+		Drawable:    common.Circle{BorderWidth: 2, BorderColor: color.Black},
+		Color:       color.RGBA{R: 0xff, A: 0xff},
+		StartZIndex: 1,
+	}, s.player)
+}
+
+func (*goldenTestScene) Type() string { return "goldenTestScene" }
+
+// goldenTestMover moves the player right while the right button is held down, at 60 units per second
+type goldenTestMover struct {
+	scene *goldenTestScene
+}
+
+func (*goldenTestMover) Remove(ecs.BasicEntity) {}
+
+func (m *goldenTestMover) Update(dt float32) {
+	if right := tango.Input.Button("right"); right.JustPressed() || right.Down() {
+		m.scene.player.Position.X += 60 * dt
+	}
+}
+
+type fakeTB struct {
+	errors []string
+}
+
+func (*fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+}
+
+func (*fakeTB) Logf(string, ...interface{}) {}
+
+func TestHarness(t *testing.T) {
+	scene := &goldenTestScene{}
+	h := NewHarness(t, 64, 64, scene)
+	defer h.Close()
+	tango.Input.RegisterButton("right", tango.KeyD)
+
+	h.Step(1)
+	h.AssertGolden(filepath.Join("testdata", "start.png"))
+
+	h.KeyDown(tango.KeyD)
+	h.Step(30)
+	h.KeyUp(tango.KeyD)
+	h.Step(1)
+	assert.InDelta(t, 38, scene.player.Position.X, 1e-3, "The player should have moved for 30 frames")
+	h.AssertGolden(filepath.Join("testdata", "moved.png"))
+}
+
+func TestDiff(t *testing.T) {
+	expected := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	actual := image.NewNRGBA(image.Rect(10, 10, 12, 12))
+	expected.SetNRGBA(0, 0, color.NRGBA{R: 100, A: 0xff})
+	actual.SetNRGBA(10, 10, color.NRGBA{R: 104, A: 0xff})
+	actual.SetNRGBA(11, 11, color.NRGBA{G: 0xff, A: 0xff})
+
+	diff, mismatched := Diff(expected, actual, 4)
+	assert.Equal(t, 1, mismatched, "Pixels within the tolerance should match")
+	assert.Equal(t, image.Rect(0, 0, 2, 2), diff.Rect)
+	assert.Equal(t, diffMismatch, diff.NRGBAAt(1, 1), "Pixels which differ should be shown in red")
+	assert.NotEqual(t, diffMismatch, diff.NRGBAAt(0, 0))
+
+	_, mismatched = Diff(expected, actual, 3)
+	assert.Equal(t, 2, mismatched)
+}
+
+func TestCompareGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tango")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	golden := filepath.Join(dir, "golden.png")
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	tb := &fakeTB{}
+	assert.False(t, CompareGolden(tb, golden, img, 0), "A missing golden image should fail")
+	assert.Len(t, tb.errors, 1)
+	assert.FileExists(t, filepath.Join(dir, "golden.actual.png"))
+
+	UpdateGolden = true
+	assert.True(t, CompareGolden(tb, golden, img, 0))
+	UpdateGolden = false
+	assert.FileExists(t, golden, "The golden image should be written when updating")
+
+	img.SetNRGBA(1, 1, color.NRGBA{R: 0xff, A: 0xff})
+	tb = &fakeTB{}
+	assert.False(t, CompareGolden(tb, golden, img, 0))
+	assert.Len(t, tb.errors, 1)
+	assert.FileExists(t, filepath.Join(dir, "golden.diff.png"), "A diff should be written when the image differs")
+
+	img.SetNRGBA(1, 1, color.NRGBA{})
+	assert.True(t, CompareGolden(tb, golden, img, 0))
+	_, err = os.Stat(filepath.Join(dir, "golden.diff.png"))
+	assert.True(t, os.IsNotExist(err), "The diff of an earlier failure should be removed")
+
+	tb = &fakeTB{}
+	assert.False(t, CompareGolden(tb, golden, image.NewNRGBA(image.Rect(0, 0, 2, 2)), 0), "Images of another size should fail")
+	assert.Len(t, tb.errors, 1)
+}
@@ -0,0 +1,115 @@
+package tango
+
+// TextEditAction is an editing action requested by the user while text input is active, such as deleting a
+// character or moving the caret.
+type TextEditAction uint8
+
+const (
+	// TextEditBackspace deletes the selection, or the character before the caret
+	TextEditBackspace TextEditAction = iota
+	// TextEditDelete deletes the selection, or the character after the caret
+	TextEditDelete
+	// TextEditLeft moves the caret one character to the left
+	TextEditLeft
+	// TextEditRight moves the caret one character to the right
+	TextEditRight
+	// TextEditWordLeft moves the caret to the start of the previous word
+	TextEditWordLeft
+	// TextEditWordRight moves the caret to the end of the next word
+	TextEditWordRight
+	// TextEditHome moves the caret to the start of the text
+	TextEditHome
+	// TextEditEnd moves the caret to the end of the text
+	TextEditEnd
+	// TextEditSelectAll selects all of the text
+	TextEditSelectAll
+	// TextEditCopy copies the selection to the clipboard
+	TextEditCopy
+	// TextEditCut copies the selection to the clipboard and deletes it
+	TextEditCut
+	// TextEditPaste replaces the selection with the contents of the clipboard
+	TextEditPaste
+	// TextEditEnter confirms the text
+	TextEditEnter
+)
+
+// headlessClipboard is the clipboard used when running in headless mode, as there is no system clipboard to use.
+var headlessClipboard string
+
+// StartTextInput starts accepting text input. While text input is active, TextCommitMessage and TextEditMessage are
+// dispatched on the Mailbox.
+func (im *InputManager) StartTextInput() {
+	im.textInput = true
+}
+
+// StopTextInput stops accepting text input. TextMessage is still dispatched for every typed character.
+func (im *InputManager) StopTextInput() {
+	im.textInput = false
+}
+
+// TextInputActive returns whether or not text input was started using StartTextInput.
+func (im *InputManager) TextInputActive() bool {
+	return im.textInput
+}
+
+// commitText dispatches the given committed text if text input is active.
+func (im *InputManager) commitText(text string) {
+	if !im.textInput {
+		return
+	}
+
+	Mailbox.Dispatch(TextCommitMessage{Text: text})
+}
+
+// editText dispatches the TextEditMessage that corresponds to the given key press (or repeat) if text input is
+// active.
+func (im *InputManager) editText(k Key, m Modifier) {
+	if !im.textInput {
+		return
+	}
+
+	if msg, ok := textEditMessage(k, m); ok {
+		Mailbox.Dispatch(msg)
+	}
+}
+
+// textEditMessage translates a key press into a TextEditMessage. It returns false if the key press does not
+// correspond to any TextEditAction.
+func textEditMessage(k Key, m Modifier) (TextEditMessage, bool) {
+	msg := TextEditMessage{Select: m&Shift != 0}
+	// Both Control and Super are accepted as shortcut modifier, to support both macOS and other platforms
+	shortcut := m&(Control|Super) != 0
+
+	switch {
+	case k == KeyBackspace:
+		msg.Action = TextEditBackspace
+	case k == KeyDelete:
+		msg.Action = TextEditDelete
+	case k == KeyArrowLeft && shortcut:
+		msg.Action = TextEditWordLeft
+	case k == KeyArrowLeft:
+		msg.Action = TextEditLeft
+	case k == KeyArrowRight && shortcut:
+		msg.Action = TextEditWordRight
+	case k == KeyArrowRight:
+		msg.Action = TextEditRight
+	case k == KeyHome:
+		msg.Action = TextEditHome
+	case k == KeyEnd:
+		msg.Action = TextEditEnd
+	case k == KeyEnter:
+		msg.Action = TextEditEnter
+	case k == KeyA && shortcut:
+		msg.Action = TextEditSelectAll
+	case k == KeyC && shortcut:
+		msg.Action = TextEditCopy
+	case k == KeyX && shortcut:
+		msg.Action = TextEditCut
+	case k == KeyV && shortcut:
+		msg.Action = TextEditPaste
+	default:
+		return msg, false
+	}
+
+	return msg, true
+}
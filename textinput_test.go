@@ -0,0 +1,65 @@
+package tango
+
+import "testing"
+
+func TestTextEditMessage(t *testing.T) {
+	tests := []struct {
+		key    Key
+		mod    Modifier
+		ok     bool
+		action TextEditAction
+		sel    bool
+	}{
+		{key: KeyBackspace, ok: true, action: TextEditBackspace},
+		{key: KeyArrowLeft, ok: true, action: TextEditLeft},
+		{key: KeyArrowLeft, mod: Shift, ok: true, action: TextEditLeft, sel: true},
+		{key: KeyArrowRight, mod: Control | Shift, ok: true, action: TextEditWordRight, sel: true},
+		{key: KeyV, mod: Control, ok: true, action: TextEditPaste},
+		{key: KeyC, mod: Super, ok: true, action: TextEditCopy},
+		{key: KeyV},
+		{key: KeySpace},
+	}
+
+	for _, test := range tests {
+		msg, ok := textEditMessage(test.key, test.mod)
+		if ok != test.ok {
+			t.Errorf("Key %d with modifier %d should be an edit action: %t", test.key, test.mod, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if msg.Action != test.action || msg.Select != test.sel {
+			t.Errorf("Key %d with modifier %d should be action %d (select %t), got %d (select %t)",
+				test.key, test.mod, test.action, test.sel, msg.Action, msg.Select)
+		}
+	}
+}
+
+func TestTextInputActive(t *testing.T) {
+	Input = NewInputManager()
+	Mailbox = &MessageManager{}
+
+	var commits, edits int
+	Mailbox.ListenMessage(TextCommitMessage{}, func(Message) { commits++ })
+	Mailbox.ListenMessage(TextEditMessage{}, func(Message) { edits++ })
+
+	Input.commitText("a")
+	Input.editText(KeyBackspace, 0)
+	if commits != 0 || edits != 0 {
+		t.Error("Text messages should not be dispatched while text input is not active")
+	}
+
+	Input.StartTextInput()
+	Input.commitText("a")
+	Input.editText(KeyBackspace, 0)
+	Input.editText(KeySpace, 0)
+	if commits != 1 || edits != 1 {
+		t.Errorf("Expected 1 commit and 1 edit, got %d and %d", commits, edits)
+	}
+
+	Input.StopTextInput()
+	if Input.TextInputActive() {
+		t.Error("Text input should not be active after StopTextInput")
+	}
+}